@@ -1,22 +1,128 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
 	"go-occupy/pkg/occupy"
+	"google.golang.org/grpc"
 )
 
 var (
-	memoryPercent float64
-	cpuPercent    float64
-	diskPercent   float64
-	interval      time.Duration
+	memoryPercent       float64
+	gpuMemoryPercent    float64
+	gpuDevice           int
+	cpuPercent          float64
+	diskPercent         float64
+	interval            time.Duration
+	diskPath            string
+	diskTargets         []string
+	memAllocator        string
+	memoryFileDir       string
+	memoryWave          string
+	memoryBasis         string
+	releasePolicy       string
+	memoryAccess        string
+	memoryAccessWorkers int
+	memoryVerify        bool
+	tagAllocations      bool
+	memoryVerifyEvery   time.Duration
+	burstInterval       time.Duration
+	burstDuration       time.Duration
+	burstMemory         float64
+	burstCPU            float64
+	burstDisk           float64
+	waveAmplitude       float64
+	wavePeriod          time.Duration
+	warmup              time.Duration
+	diskWriteRate       float64
+	diskDirectIO        bool
+	diskFsync           bool
+	diskRetries         int
+	diskRetryDelay      time.Duration
+	filesPerDir         int
+	allowTmpfs          bool
+	diskMeasureOnly     bool
+	sandbox             bool
+	blockUntilReady     bool
+	noCleanupErr        bool
+	rampDown            time.Duration
+	reportEvery         time.Duration
+	metricTimeout       time.Duration
+	convergeDeadline    time.Duration
+	reportSelf          bool
+	summaryJSON         string
+	labels              []string
+	configPath          string
+	showProgress        bool
+	tolerance           float64
+	cpuCoreLoad         float64
+	gomaxprocs          int
+	respectCgroups      bool
+	cgroupTarget        string
+	startupOrder        string
+	startupDelay        time.Duration
+	memoryFloor         string
+	minFreeMemory       string
+	maxMemory           string
+	rlimitMemory        string
+	leakMode            bool
+	leakRate            string
+	memoryOOM           bool
+	memoryOOMStep       string
+	memoryFragment      bool
+	memoryFragmentMin   string
+	memoryFragmentMax   string
+	confirmOOM          bool
+	diskFloor           string
+	maxDisk             string
+	diskFillMode        string
+	seed                int64
+	memoryFill          string
+	logLevel            string
+	logFormat           string
+	logFile             string
+	logMaxSize          string
+	hugePages           bool
+	memoryCommit        bool
+	numaNode            int
+	cpuCooldown         time.Duration
+	cpuSmoothing        float64
+	cpuWorkload         string
+	cpuMix              []string
+	cpuExclude          string
+	cpuControl          string
+	controlGain         float64
+	mirrorPID           int32
+	mirrorFactor        float64
+	serveAddr           string
+	grpcAddr            string
+	statusDiskPath      string
+	httpAddr            string
+	pprofAddr           string
+	gcPercent           int
+	memoryBaseline      string
+	apiToken            string
+	apiPublicReads      bool
+	statusJSON          bool
+	probeDiskPaths      []string
+	probeJSON           bool
+	probeMemoryMargin   float64
+	probeDiskMargin     float64
+	cleanDir            string
+	cleanPrefix         string
+	filePrefix          string
 )
 
 func main() {
@@ -29,14 +135,149 @@ func main() {
 	}
 
 	// 添加命令行参数
+	rootCmd.Flags().StringVar(&configPath, "config", "", "JSON配置文件，可设置 memory_percent、cpu_percent、disk_percent；收到 SIGHUP 时重新加载")
 	rootCmd.Flags().Float64VarP(&memoryPercent, "memory", "m", 50.0, "目标内存使用百分比 (0-100)")
 	rootCmd.Flags().Float64VarP(&cpuPercent, "cpu", "c", 30.0, "目标CPU使用百分比 (0-100)")
+	rootCmd.Flags().Float64Var(&cpuCoreLoad, "cpu-cores-load", 0, "需要保持忙碌的核心数，如 4.5 (设置后覆盖 --cpu)")
+	rootCmd.Flags().IntVar(&gomaxprocs, "gomaxprocs", 0, "设置 GOMAXPROCS 并以该值作为CPU工作线程计算的核心数 (0 表示使用 NumCPU)")
+	rootCmd.Flags().StringVar(&startupOrder, "startup-order", "", "分阶段启动的顺序，如 mem,cpu,disk 或 mem,cpu:1m,disk（为空表示同时启动）")
+	rootCmd.Flags().DurationVar(&startupDelay, "startup-delay", 30*time.Second, "分阶段启动时相邻阶段之间的默认延迟")
+	rootCmd.Flags().StringVar(&cgroupTarget, "cgroup", "", "启动时将整个进程（包括监控线程，而不只是工作线程）移入的 cgroup（如 /sys/fs/cgroup/test 或 test），CPU和内存占用计入该 cgroup（仅Linux）")
+	rootCmd.Flags().BoolVar(&respectCgroups, "respect-cgroups", false, "按 cgroup CPU 配额（小于 NumCPU 时）计算CPU工作线程数")
+	rootCmd.Flags().Float64Var(&controlGain, "control-gain", occupy.DefaultControlGain, "比例控制增益 (0-1]，每次调整只补齐与目标差距的该比例")
+	rootCmd.Flags().StringVar(&cpuControl, "cpu-control", occupy.CPUControlDuty, "CPU负载的控制方式 (duty, tokens)")
+	rootCmd.Flags().StringVar(&cpuWorkload, "cpu-workload", occupy.CPUWorkloadFloat, "CPU负载的计算类型 (float, int, memory)")
+	rootCmd.Flags().StringVar(&cpuExclude, "cpu-exclude", "", "不施加CPU负载的核心编号，如 0,1 或 0-3，工作线程绑定到其余核心（仅Linux）")
+	rootCmd.Flags().StringArrayVar(&cpuMix, "cpu-mix", nil, "CPU混合负载 TYPE:WEIGHT，如 float:2,int:1,memory:1，可重复指定（设置后覆盖 --cpu-workload）")
+	rootCmd.Flags().Float64Var(&cpuSmoothing, "cpu-smoothing", occupy.DefaultCPUSmoothing, "CPU使用率平滑系数 (0-1]，越大越接近最新采样值")
+	rootCmd.Flags().DurationVar(&cpuCooldown, "cpu-cooldown", 0, "停止CPU负载后重新启动前的冷却时间 (0 表示不限制)")
 	rootCmd.Flags().Float64VarP(&diskPercent, "disk", "d", 40.0, "目标磁盘使用百分比 (0-100)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "日志级别 (debug, info, warn, error)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", string(occupy.LogFormatText), "日志格式 (text, json)，json 格式每行一个JSON对象，包含时间、级别、内容和 --label 标签")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "同时将日志写入该文件（标准错误输出照常输出）")
+	rootCmd.PersistentFlags().StringVar(&logMaxSize, "log-max-size", "", "日志文件超过该大小（如 100MB）时轮转，保留最近的旧文件 (默认不轮转)")
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		level, err := occupy.ParseLogLevel(logLevel)
+		if err != nil {
+			log.Fatal(err)
+		}
+		occupy.SetLogLevel(level)
+
+		if logFile != "" {
+			maxSize, err := parseOptionalSize(logMaxSize)
+			if err != nil {
+				log.Fatalf("日志文件大小上限: %v", err)
+			}
+			file, err := occupy.OpenRotatingFile(logFile, maxSize)
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.SetOutput(io.MultiWriter(os.Stderr, file))
+		}
+
+		format, err := occupy.ParseLogFormat(logFormat)
+		if err != nil {
+			log.Fatal(err)
+		}
+		occupy.SetLogFormat(format)
+	}
 	rootCmd.Flags().DurationVarP(&interval, "interval", "i", 5*time.Second, "监控间隔时间")
+	rootCmd.Flags().StringVar(&diskPath, "disk-path", "", "统计磁盘使用率的路径（默认为临时文件目录所在的文件系统）")
+	rootCmd.Flags().Float64Var(&tolerance, "tolerance", occupy.DefaultTolerance, "目标容忍度（百分点），超出目标该范围才进行反向调整")
+	rootCmd.Flags().DurationVar(&warmup, "warmup", occupy.DefaultWarmupDuration, "开始占用前采样CPU基线的预热时间 (0 表示不预热)")
+	rootCmd.Flags().StringVar(&memoryBasis, "memory-basis", occupy.MemoryBasisTotal, "内存目标的计算基准 (total, available)")
+	rootCmd.Flags().StringVar(&releasePolicy, "memory-release-policy", occupy.MemoryReleaseTail, "释放内存时选择内存块的顺序 (tail, head, random)")
+	rootCmd.Flags().StringVar(&memoryAccess, "memory-access-pattern", occupy.MemoryAccessNone, "已分配内存的访问模式 (none, sequential, random, strided)")
+	rootCmd.Flags().IntVar(&memoryAccessWorkers, "memory-access-workers", occupy.DefaultMemoryAccessWorkers, "内存访问工作线程数")
+	rootCmd.Flags().BoolVar(&memoryVerify, "memory-verify", false, "定期校验已分配内存的内容，记录不一致的位置（用于检测内存故障）")
+	rootCmd.Flags().BoolVar(&tagAllocations, "tag-allocations", false, "在每个内存块开头写入标记和块编号，收到 SIGUSR1 时输出内存块清单（便于泄漏检测工具识别）")
+	rootCmd.Flags().DurationVar(&memoryVerifyEvery, "memory-verify-interval", occupy.DefaultMemoryVerifyInterval, "两轮内存校验之间的间隔")
+	rootCmd.Flags().DurationVar(&burstInterval, "burst-interval", 0, "每隔该时间进入一次突发窗口 (0 表示不启用)")
+	rootCmd.Flags().DurationVar(&burstDuration, "burst-duration", 30*time.Second, "突发窗口持续时间")
+	rootCmd.Flags().Float64Var(&burstMemory, "burst-memory", 0, "突发窗口内的内存目标百分比 (0 表示保持基础目标)")
+	rootCmd.Flags().Float64Var(&burstCPU, "burst-cpu", 0, "突发窗口内的CPU目标百分比 (0 表示保持基础目标)")
+	rootCmd.Flags().Float64Var(&burstDisk, "burst-disk", 0, "突发窗口内的磁盘目标百分比 (0 表示保持基础目标)")
+	rootCmd.Flags().StringVar(&memoryWave, "memory-wave", occupy.MemoryWaveFlat, "内存目标波形 (flat, sawtooth, sine, square)")
+	rootCmd.Flags().Float64Var(&waveAmplitude, "memory-wave-amplitude", 20, "内存目标波形振幅（百分点）")
+	rootCmd.Flags().DurationVar(&wavePeriod, "memory-wave-period", 10*time.Minute, "内存目标波形周期")
+	rootCmd.Flags().StringVar(&memAllocator, "memory-allocator", occupy.MemoryAllocatorHeap, "内存分配方式 (heap, mmap, shm, file)")
+	rootCmd.Flags().StringVar(&memoryFileDir, "memory-file-dir", "", "file 分配方式下映射文件所在的目录 (默认: 磁盘占用的写入目录)")
+	rootCmd.Flags().StringVar(&memAllocator, "memory-backing", occupy.MemoryAllocatorHeap, "--memory-allocator 的别名")
+	rootCmd.Flags().Int64Var(&seed, "seed", 0, "所有随机选择（随机填充、碎片模式、随机释放和访问顺序）使用的随机数种子 (0 表示基于时间生成)")
+	rootCmd.Flags().StringVar(&memoryFill, "memory-fill", occupy.MemoryFillPattern, "内存块内容 (pattern, random)")
+	rootCmd.Flags().StringVar(&filePrefix, "file-prefix", occupy.DefaultFilePrefix, "临时文件名前缀，同一目录运行多个实例时用于区分各自的文件")
+	rootCmd.Flags().StringVar(&diskFillMode, "disk-fill", occupy.DiskFillSequential, "临时文件内容 (sequential, random, zero)")
+	rootCmd.Flags().IntVar(&numaNode, "numa-node", -1, "mmap分配时将内存绑定到指定NUMA节点 (仅Linux，-1 表示不绑定)")
+	rootCmd.Flags().BoolVar(&hugePages, "huge-pages", false, "mmap分配时使用大页 (仅Linux，需配合 --memory-allocator mmap)")
+	rootCmd.Flags().BoolVar(&memoryCommit, "memory-commit", true, "写入分配的内存使其常驻；为 false 时只保留地址空间 (需配合 --memory-allocator mmap)")
+	rootCmd.Flags().IntVar(&filesPerDir, "disk-files-per-dir", 0, "每个子目录最多写入的临时文件数 (0 表示不创建子目录)")
+	rootCmd.Flags().BoolVar(&diskDirectIO, "disk-direct-io", false, "以直接I/O方式写入临时文件，绕过页缓存（仅Linux）")
+	rootCmd.Flags().BoolVar(&diskFsync, "disk-fsync", false, "每个临时文件写入后调用 fsync，确保空间已在存储上实际分配（较慢）")
+	rootCmd.Flags().Float64Var(&diskWriteRate, "disk-write-rate", 0, "临时文件写入速率上限 MB/s，最小为1 (0 表示不限速)")
+	rootCmd.Flags().IntVar(&diskRetries, "disk-retries", occupy.DefaultDiskWriteRetries, "临时文件写入失败后的重试次数，只读文件系统等永久错误不重试 (负数表示不重试)")
+	rootCmd.Flags().DurationVar(&diskRetryDelay, "disk-retry-delay", occupy.DefaultDiskRetryDelay, "临时文件写入首次重试前的等待时间，之后每次加倍")
+	rootCmd.Flags().BoolVar(&leakMode, "leak", false, "模拟内存泄漏：只增长、从不释放内存")
+	rootCmd.Flags().StringVar(&leakRate, "leak-rate", "", "泄漏模式下每次调整最多增长的内存（如 10MB，默认不限制）")
+	rootCmd.Flags().BoolVar(&memoryOOM, "memory-oom", false, "危险：忽略内存目标持续分配内存，直到分配失败或本进程被 OOM killer 杀死（需同时设置 --confirm-oom）")
+	rootCmd.Flags().StringVar(&memoryOOMStep, "memory-oom-step", "16MB", "OOM模式下每次分配的内存")
+	rootCmd.Flags().BoolVar(&memoryFragment, "memory-fragment", false, "碎片模式：内存块大小在 --memory-fragment-min 到 --memory-fragment-max 之间随机选取")
+	rootCmd.Flags().StringVar(&memoryFragmentMin, "memory-fragment-min", "64KB", "碎片模式下内存块的最小大小")
+	rootCmd.Flags().StringVar(&memoryFragmentMax, "memory-fragment-max", "100MB", "碎片模式下内存块的最大大小")
+	rootCmd.Flags().BoolVar(&confirmOOM, "confirm-oom", false, "确认启用 --memory-oom")
+	rootCmd.Flags().StringVar(&minFreeMemory, "min-free-memory", "", "至少保留的可用内存（如 2GB，留给页缓存），分配不会使可用内存低于该值")
+	rootCmd.Flags().StringVar(&maxMemory, "max-memory", "", "最多分配的内存总量（如 8GB），无论百分比目标为多少都不超过")
+	rootCmd.Flags().StringVar(&rlimitMemory, "rlimit-memory", "", "启动时通过 setrlimit 设置本进程的内存硬限制（如 4GB，仅Unix），作为 --max-memory 之外的兜底")
+	rootCmd.Flags().StringVar(&memoryBaseline, "memory-baseline", "", "开始占用前一次性分配的固定内存（如 1GB），不随目标调整释放")
+	rootCmd.Flags().StringVar(&memoryFloor, "memory-floor", "", "可用内存下限（如 100MB），低于该值时紧急释放所有资源并暂停")
+	rootCmd.Flags().StringVar(&maxDisk, "max-disk", "", "临时文件最多占用的字节数（如 50GB），无论百分比目标为多少都不超过")
+	rootCmd.Flags().Float64Var(&gpuMemoryPercent, "gpu-memory", 0, "目标GPU显存使用百分比 (0-100，0 表示不占用；需使用 -tags gpu 编译)")
+	rootCmd.Flags().IntVar(&gpuDevice, "gpu-device", 0, "占用显存的GPU设备编号")
+	rootCmd.Flags().StringVar(&diskFloor, "disk-floor", "", "磁盘剩余空间下限（如 1GB），低于该值时紧急释放所有资源并暂停")
+	rootCmd.Flags().Int32Var(&mirrorPID, "mirror-pid", 0, "镜像指定进程的资源使用，按倍数动态计算内存/CPU目标")
+	rootCmd.Flags().Float64Var(&mirrorFactor, "mirror-factor", occupy.DefaultMirrorFactor, "镜像倍数，目标 = 进程使用率 × 倍数")
+	rootCmd.Flags().DurationVar(&convergeDeadline, "converge-deadline", 0, "预热结束后在该时间内未达到目标则以错误退出 (0 表示不检查)")
+	rootCmd.Flags().BoolVar(&reportSelf, "report-self", false, "输出使用情况时同时输出本进程自身的RSS和CPU占用")
+	rootCmd.Flags().StringArrayVar(&labels, "label", nil, "标签 KEY=VALUE，添加到每行日志、运行汇总和 /metrics 指标中，可重复指定")
+	rootCmd.Flags().StringVar(&summaryJSON, "summary-json", "", "退出时将各资源使用率分布（min/p50/p90/p99/max）以JSON写入该文件（- 表示标准输出）")
+	rootCmd.Flags().DurationVar(&metricTimeout, "metric-timeout", occupy.DefaultMetricTimeout, "单次读取内存/CPU/磁盘指标的超时时间，超时的资源本次跳过 (负数表示不限制)")
+	rootCmd.Flags().DurationVar(&reportEvery, "report-interval", 0, "输出当前使用情况的间隔 (0 表示每次调整时以 debug 级别输出)")
+	rootCmd.Flags().StringVar(&grpcAddr, "grpc-addr", "", "gRPC控制服务监听地址（如 :9090，为空表示不启用）")
+	rootCmd.Flags().IntVar(&gcPercent, "gc-percent", 100, "运行期间的GC目标百分比（同 GOGC，-1 关闭自动GC），未指定时不修改")
+	rootCmd.Flags().StringVar(&pprofAddr, "pprof-addr", "", "运行期间提供 pprof 性能分析接口的监听地址（如 localhost:6060，为空表示不启用）")
+	rootCmd.Flags().StringVar(&httpAddr, "http-addr", "", "健康检查HTTP服务监听地址，提供 /healthz、/readyz 和 Prometheus 指标 /metrics（如 :8081，为空表示不启用）")
+	rootCmd.Flags().BoolVar(&showProgress, "progress", false, "每次调整后显示当前使用率与目标的对比（终端上在同一行刷新）")
+	rootCmd.Flags().DurationVar(&rampDown, "ramp-down", 0, "停止时在该时长内逐步释放内存和CPU负载 (0 表示立即清理，最长50s)")
+	rootCmd.Flags().BoolVar(&noCleanupErr, "no-cleanup-on-error", false, "因错误退出时保留内存和临时文件以便排查（正常停止仍会清理）")
+	rootCmd.Flags().BoolVar(&allowTmpfs, "allow-tmpfs-disk", false, "允许在 tmpfs/ramfs 上进行磁盘占用")
+	rootCmd.Flags().BoolVar(&diskMeasureOnly, "disk-measure-only", false, "只测量并输出磁盘使用率，不创建临时文件")
+	rootCmd.Flags().StringVar(&apiToken, "api-token", "", "gRPC控制接口的 Bearer 令牌（健康检查服务不需要令牌），为空时读取环境变量 "+occupy.APITokenEnv+"，均为空表示不认证")
+	rootCmd.Flags().BoolVar(&apiPublicReads, "api-public-reads", false, "只读接口（gRPC Status、ListTempFiles）不需要令牌")
+	rootCmd.Flags().BoolVar(&blockUntilReady, "block-until-ready", false, "启动监控前同步写入临时文件、分配内存直到达到目标，之后再进入调整循环")
+	rootCmd.Flags().BoolVar(&sandbox, "sandbox", false, "在子进程中执行资源占用，本进程只负责监督，子进程异常退出后清理其残留的临时文件")
+	rootCmd.Flags().StringArrayVar(&diskTargets, "disk-target", nil, "磁盘占用目标 PATH=PERCENT，可重复指定（设置后忽略 --disk）")
 
 	// 添加子命令
+	serveCmd.Flags().StringVar(&serveAddr, "addr", ":8080", "HTTP服务监听地址")
+	serveCmd.Flags().StringVar(&apiToken, "api-token", "", "控制接口的 Bearer 令牌，为空时读取环境变量 "+occupy.APITokenEnv+"，均为空表示不认证")
+	serveCmd.Flags().BoolVar(&apiPublicReads, "api-public-reads", false, "只读接口不需要令牌")
+	statusCmd.Flags().StringVar(&statusDiskPath, "disk-path", occupy.DefaultDiskPath, "查看的磁盘路径")
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "以JSON格式输出")
+	probeCmd.Flags().StringArrayVar(&probeDiskPaths, "disk-path", []string{occupy.DefaultDiskPath}, "探测的磁盘路径，可重复指定")
+	probeCmd.Flags().BoolVar(&probeJSON, "json", false, "以JSON格式输出")
+	probeCmd.Flags().Float64Var(&probeMemoryMargin, "memory-margin", occupy.DefaultProbeMemoryMargin, "保留的可用内存占总内存的百分比")
+	probeCmd.Flags().Float64Var(&probeDiskMargin, "disk-margin", occupy.DefaultProbeDiskMargin, "保留的磁盘剩余空间占总容量的百分比")
+	cleanCmd.Flags().StringVar(&cleanDir, "disk-path", "", "临时文件所在目录 (默认: 系统临时目录)")
+	cleanCmd.Flags().StringVar(&cleanPrefix, "prefix", occupy.DefaultFilePrefix, "临时文件名前缀")
+	// 沙箱子进程使用与主命令相同的参数
+	sandboxChildCmd.Flags().AddFlagSet(rootCmd.Flags())
+
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(helpCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(probeCmd)
+	rootCmd.AddCommand(cleanCmd)
+	rootCmd.AddCommand(sandboxChildCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -45,6 +286,30 @@ func main() {
 }
 
 func runOccupy(cmd *cobra.Command, args []string) {
+	// 标签最先生效，使之后的所有日志都带有标签
+	runLabels, err := occupy.ParseLabels(labels)
+	if err != nil {
+		log.Fatal(err)
+	}
+	occupy.SetLogLabels(runLabels)
+
+	// 配置文件中的目标仅在未通过命令行指定时生效
+	if configPath != "" {
+		fileConfig, err := occupy.LoadConfig(configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if fileConfig.MemoryPercent != nil && !cmd.Flags().Changed("memory") {
+			memoryPercent = *fileConfig.MemoryPercent
+		}
+		if fileConfig.CPUPercent != nil && !cmd.Flags().Changed("cpu") {
+			cpuPercent = *fileConfig.CPUPercent
+		}
+		if fileConfig.DiskPercent != nil && !cmd.Flags().Changed("disk") {
+			diskPercent = *fileConfig.DiskPercent
+		}
+	}
+
 	// 验证参数
 	if memoryPercent < 0 || memoryPercent > 100 {
 		log.Fatal("内存百分比必须在 0-100 之间")
@@ -55,13 +320,322 @@ func runOccupy(cmd *cobra.Command, args []string) {
 	if diskPercent < 0 || diskPercent > 100 {
 		log.Fatal("磁盘百分比必须在 0-100 之间")
 	}
+	if gpuMemoryPercent < 0 || gpuMemoryPercent > 100 {
+		log.Fatal("GPU显存百分比必须在 0-100 之间")
+	}
+	if gpuDevice < 0 {
+		log.Fatal("GPU设备编号不能为负数")
+	}
+	if err := occupy.ValidateInterval(interval); err != nil {
+		log.Fatal(err)
+	}
+	if filePrefix == "" {
+		log.Fatal("临时文件名前缀不能为空")
+	}
+
+	var stages []occupy.StartupStage
+	if startupOrder != "" {
+		var err error
+		if stages, err = occupy.ParseStartupOrder(startupOrder, startupDelay); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if gomaxprocs < 0 {
+		log.Fatal("GOMAXPROCS 不能为负数")
+	}
+	cpuCount := runtime.NumCPU()
+	if gomaxprocs > 0 {
+		// 固定 GOMAXPROCS，使工作线程数不随运行环境的核心数变化
+		runtime.GOMAXPROCS(gomaxprocs)
+		cpuCount = gomaxprocs
+		log.Printf("GOMAXPROCS 已设置为 %d (NumCPU: %d)", gomaxprocs, runtime.NumCPU())
+	}
+	if cpuCoreLoad < 0 || cpuCoreLoad > float64(cpuCount) {
+		log.Fatalf("CPU核心负载必须在 0-%d 之间", cpuCount)
+	}
+	if controlGain <= 0 || controlGain > 1 {
+		log.Fatal("控制增益必须在 0-1 之间且大于0")
+	}
+	if cpuControl != occupy.CPUControlDuty && cpuControl != occupy.CPUControlTokens {
+		log.Fatal("CPU负载控制方式必须是 duty 或 tokens")
+	}
+	switch cpuWorkload {
+	case occupy.CPUWorkloadFloat, occupy.CPUWorkloadInt, occupy.CPUWorkloadMemory:
+	default:
+		log.Fatal("CPU负载计算类型必须是 float、int 或 memory")
+	}
+	var workloadMix []occupy.CPUWorkloadWeight
+	if len(cpuMix) > 0 {
+		var err error
+		if workloadMix, err = occupy.ParseCPUMix(strings.Join(cpuMix, ",")); err != nil {
+			log.Fatal(err)
+		}
+	}
+	var excludedCPUs []int
+	if cpuExclude != "" {
+		var err error
+		if excludedCPUs, err = occupy.ParseCPUList(cpuExclude); err != nil {
+			log.Fatal(err)
+		}
+		if err := occupy.ValidateCPUExclude(excludedCPUs); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cpuSmoothing <= 0 || cpuSmoothing > 1 {
+		log.Fatal("CPU平滑系数必须在 0-1 之间且大于0")
+	}
+	if reportEvery < 0 {
+		log.Fatal("输出间隔不能为负数")
+	}
+	if convergeDeadline < 0 {
+		log.Fatal("达到目标的截止时间不能为负数")
+	}
+	if rampDown < 0 {
+		log.Fatal("逐步释放时长不能为负数")
+	}
+	if mirrorFactor <= 0 {
+		log.Fatal("镜像倍数必须大于0")
+	}
+	if tolerance <= 0 || tolerance > 100 {
+		log.Fatal("容忍度必须在 0-100 之间且大于0")
+	}
+	if filesPerDir < 0 {
+		log.Fatal("每个子目录的文件数不能为负数")
+	}
+	if err := occupy.ValidateDiskWriteRate(diskWriteRate); err != nil {
+		log.Fatal(err)
+	}
+	if diskRetryDelay <= 0 {
+		log.Fatal("磁盘写入重试等待时间必须大于0")
+	}
+	switch diskFillMode {
+	case occupy.DiskFillSequential, occupy.DiskFillRandom, occupy.DiskFillZero:
+	default:
+		log.Fatal("临时文件内容必须是 sequential、random 或 zero")
+	}
+	switch memoryAccess {
+	case occupy.MemoryAccessNone, occupy.MemoryAccessSequential, occupy.MemoryAccessRandom, occupy.MemoryAccessStrided:
+	default:
+		log.Fatal("内存访问模式必须是 none、sequential、random 或 strided")
+	}
+	if memoryAccessWorkers <= 0 {
+		log.Fatal("内存访问工作线程数必须大于0")
+	}
+	if memoryVerifyEvery <= 0 {
+		log.Fatal("内存校验间隔必须大于0")
+	}
+	if memoryBasis != occupy.MemoryBasisTotal && memoryBasis != occupy.MemoryBasisAvailable {
+		log.Fatal("内存目标的计算基准必须是 total 或 available")
+	}
+	switch releasePolicy {
+	case occupy.MemoryReleaseTail, occupy.MemoryReleaseHead, occupy.MemoryReleaseRandom:
+	default:
+		log.Fatal("内存释放顺序必须是 tail、head 或 random")
+	}
+	if burstInterval < 0 {
+		log.Fatal("突发间隔不能为负数")
+	}
+	if burstInterval > 0 && (burstDuration <= 0 || burstDuration >= burstInterval) {
+		log.Fatal("突发持续时间必须大于0且小于突发间隔")
+	}
+	if burstMemory < 0 || burstMemory > 100 || burstCPU < 0 || burstCPU > 100 || burstDisk < 0 || burstDisk > 100 {
+		log.Fatal("突发目标百分比必须在 0-100 之间")
+	}
+	if gcPercent < -1 {
+		log.Fatal("GC目标百分比必须大于等于 -1")
+	}
+	switch memoryWave {
+	case occupy.MemoryWaveFlat, occupy.MemoryWaveSawtooth, occupy.MemoryWaveSine, occupy.MemoryWaveSquare:
+	default:
+		log.Fatal("内存目标波形必须是 flat、sawtooth、sine 或 square")
+	}
+	if waveAmplitude < 0 || waveAmplitude > 100 {
+		log.Fatal("内存目标波形振幅必须在 0-100 之间")
+	}
+	if wavePeriod <= 0 {
+		log.Fatal("内存目标波形周期必须大于0")
+	}
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	if diskFillMode == occupy.DiskFillRandom || memoryFill == occupy.MemoryFillRandom || memoryFragment ||
+		releasePolicy == occupy.MemoryReleaseRandom || memoryAccess == occupy.MemoryAccessRandom {
+		// 使用随机功能时输出实际使用的种子，便于复现
+		log.Printf("随机数种子: %d", seed)
+	}
+	switch memAllocator {
+	case occupy.MemoryAllocatorHeap, occupy.MemoryAllocatorMmap, occupy.MemoryAllocatorFile:
+	case occupy.MemoryAllocatorShm:
+		if runtime.GOOS != "linux" {
+			log.Fatal("共享内存分配方式仅支持Linux")
+		}
+	default:
+		log.Fatal("内存分配方式必须是 heap、mmap、shm 或 file")
+	}
+
+	leakRateBytes, err := parseOptionalSize(leakRate)
+	if err != nil {
+		log.Fatalf("泄漏速率: %v", err)
+	}
+	maxMemoryBytes, err := parseOptionalSize(maxMemory)
+	if err != nil {
+		log.Fatalf("内存上限: %v", err)
+	}
+	rlimitMemoryBytes, err := parseOptionalSize(rlimitMemory)
+	if err != nil {
+		log.Fatalf("进程内存限制: %v", err)
+	}
+	memoryFloorBytes, err := parseOptionalSize(memoryFloor)
+	if err != nil {
+		log.Fatalf("内存下限: %v", err)
+	}
+	memoryBaselineBytes, err := parseOptionalSize(memoryBaseline)
+	if err != nil {
+		log.Fatalf("内存基线: %v", err)
+	}
+	minFreeMemoryBytes, err := parseOptionalSize(minFreeMemory)
+	if err != nil {
+		log.Fatalf("保留可用内存: %v", err)
+	}
+	maxDiskBytes, err := parseOptionalSize(maxDisk)
+	if err != nil {
+		log.Fatalf("磁盘上限: %v", err)
+	}
+	diskFloorBytes, err := parseOptionalSize(diskFloor)
+	if err != nil {
+		log.Fatalf("磁盘下限: %v", err)
+	}
+	memoryFragmentMinBytes, err := occupy.ParseSize(memoryFragmentMin)
+	if err != nil || memoryFragmentMinBytes == 0 {
+		log.Fatalf("内存块最小大小无效: %q", memoryFragmentMin)
+	}
+	memoryFragmentMaxBytes, err := occupy.ParseSize(memoryFragmentMax)
+	if err != nil || memoryFragmentMaxBytes == 0 {
+		log.Fatalf("内存块最大大小无效: %q", memoryFragmentMax)
+	}
+	memoryOOMStepBytes, err := occupy.ParseSize(memoryOOMStep)
+	if err != nil || memoryOOMStepBytes == 0 {
+		log.Fatalf("OOM模式分配步长无效: %q", memoryOOMStep)
+	}
+	if memoryOOM {
+		if !confirmOOM {
+			log.Fatal("--memory-oom 会持续分配内存直到本进程被杀死，请同时设置 --confirm-oom 确认")
+		}
+		if memoryFloorBytes > 0 {
+			log.Fatal("--memory-oom 不能与 --memory-floor 同时使用")
+		}
+		log.Println("警告: 已启用OOM模式，本进程将持续分配内存直到无法分配或被 OOM killer 杀死")
+	}
+
+	targets := make([]occupy.DiskTarget, 0, len(diskTargets))
+	for _, value := range diskTargets {
+		target, err := occupy.ParseDiskTarget(value)
+		if err != nil {
+			log.Fatal(err)
+		}
+		targets = append(targets, target)
+	}
 
 	// 创建资源配置
 	config := occupy.ResourceConfig{
-		MemoryPercent: memoryPercent,
-		CPUPercent:    cpuPercent,
-		DiskPercent:   diskPercent,
-		Interval:      interval,
+		MemoryPercent:        memoryPercent,
+		CPUPercent:           cpuPercent,
+		CPUCoreLoad:          cpuCoreLoad,
+		CPUCount:             gomaxprocs,
+		RespectCgroups:       respectCgroups,
+		CgroupPath:           cgroupTarget,
+		PprofAddr:            pprofAddr,
+		GCPercentOverride:    cmd.Flags().Changed("gc-percent"),
+		GCPercent:            gcPercent,
+		StartupOrder:         stages,
+		CPUCooldown:          cpuCooldown,
+		CPUSmoothing:         cpuSmoothing,
+		CPUWorkloadType:      cpuWorkload,
+		CPUMix:               workloadMix,
+		CPUExclude:           excludedCPUs,
+		CPUControl:           cpuControl,
+		ControlGain:          controlGain,
+		DiskPercent:          diskPercent,
+		Interval:             interval,
+		DiskPath:             diskPath,
+		DiskTargets:          targets,
+		MemoryAllocator:      memAllocator,
+		MemoryFileDir:        memoryFileDir,
+		MemoryBasis:          memoryBasis,
+		MemoryReleasePolicy:  releasePolicy,
+		MemoryAccessPattern:  memoryAccess,
+		MemoryAccessWorkers:  memoryAccessWorkers,
+		MemoryVerify:         memoryVerify,
+		TagAllocations:       tagAllocations,
+		MemoryVerifyInterval: memoryVerifyEvery,
+		FilePrefix:           filePrefix,
+		MemoryWave:           memoryWave,
+		MemoryWaveAmplitude:  waveAmplitude,
+		MemoryWavePeriod:     wavePeriod,
+		BurstInterval:        burstInterval,
+		BurstDuration:        burstDuration,
+		BurstMemoryPercent:   burstMemory,
+		BurstCPUPercent:      burstCPU,
+		BurstDiskPercent:     burstDisk,
+		NUMABind:             numaNode >= 0,
+		NUMANode:             numaNode,
+		UseHugePages:         hugePages,
+		MemoryNoCommit:       !memoryCommit,
+		MemoryBaselineBytes:  memoryBaselineBytes,
+		WarmupDuration:       warmup,
+		DiskWriteMBps:        diskWriteRate,
+		DiskDirectIO:         diskDirectIO,
+		DiskFsync:            diskFsync,
+		DiskWriteRetries:     diskRetries,
+		DiskRetryDelay:       diskRetryDelay,
+		Seed:                 seed,
+		MemoryFill:           memoryFill,
+		DiskFilesPerDir:      filesPerDir,
+		DiskFillMode:         diskFillMode,
+		ReportInterval:       reportEvery,
+		MetricTimeout:        metricTimeout,
+		ConvergeDeadline:     convergeDeadline,
+		ReportSelf:           reportSelf,
+		Labels:               runLabels,
+		RampDown:             rampDown,
+		NoCleanupOnError:     noCleanupErr,
+		AllowTmpfsDisk:       allowTmpfs,
+		DiskMeasureOnly:      diskMeasureOnly,
+		MirrorPID:            mirrorPID,
+		MirrorFactor:         mirrorFactor,
+		Tolerance:            tolerance,
+		LeakMode:             leakMode,
+		LeakRateBytes:        leakRateBytes,
+		MemoryOOM:            memoryOOM,
+		MemoryOOMStep:        memoryOOMStepBytes,
+		MemoryFragment:       memoryFragment,
+		MemoryFragmentMin:    memoryFragmentMinBytes,
+		MemoryFragmentMax:    memoryFragmentMaxBytes,
+		MaxMemoryBytes:       maxMemoryBytes,
+		RlimitMemoryBytes:    rlimitMemoryBytes,
+		MemoryFloorBytes:     memoryFloorBytes,
+		MinFreeMemoryBytes:   minFreeMemoryBytes,
+		MaxDiskBytes:         maxDiskBytes,
+		GPUMemoryPercent:     gpuMemoryPercent,
+		GPUDevice:            gpuDevice,
+		DiskFloorBytes:       diskFloorBytes,
+	}
+	if showProgress {
+		config.ProgressOutput = os.Stdout
+	}
+
+	if err := occupy.ValidateConfig(config); err != nil {
+		log.Fatal(err)
+	}
+	if summaryJSON != "" && summaryJSON != "-" {
+		if dir := occupy.SharesDiskTarget(config, summaryJSON); dir != "" {
+			log.Printf("警告: 运行汇总文件 %s 与磁盘占用目录 %s 位于同一文件系统，磁盘写满时汇总可能无法写入（会保留原有文件）", summaryJSON, dir)
+		}
+	}
+
+	// 沙箱模式下本进程只负责启动和监督子进程
+	if sandbox {
+		os.Exit(runSandbox(config))
 	}
 
 	// 创建资源监控器
@@ -71,19 +645,322 @@ func runOccupy(cmd *cobra.Command, args []string) {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// 启动监控
-	go monitor.Start()
+	// SIGUSR1 输出状态快照（仅Unix）
+	if statusSignals := occupy.StatusSignals(); len(statusSignals) > 0 {
+		statusChan := make(chan os.Signal, 1)
+		signal.Notify(statusChan, statusSignals...)
+		go func() {
+			for range statusChan {
+				monitor.LogStatus()
+			}
+		}()
+	}
+
+	// SIGHUP 重新加载配置文件（仅Unix），配置无效时保持当前目标
+	if reloadSignals := occupy.ReloadSignals(); configPath != "" && len(reloadSignals) > 0 {
+		reloadChan := make(chan os.Signal, 1)
+		signal.Notify(reloadChan, reloadSignals...)
+		go func() {
+			for range reloadChan {
+				if err := monitor.ReloadConfig(configPath); err != nil {
+					log.Printf("重新加载配置失败，保持当前目标: %v", err)
+				}
+			}
+		}()
+	}
+
+	// Ctrl+Z 挂起时停止CPU负载，继续运行时恢复（仅Unix）
+	monitor.WatchSuspendSignals()
+
+	// 启动监控，设置 --grpc-addr 时同时启动gRPC控制服务
+	service := occupy.NewGRPCService(monitor)
+	auth := apiAuth()
+	var grpcServer *grpc.Server
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("gRPC服务监听失败: %v", err)
+		}
+		grpcServer = grpc.NewServer(grpc.UnaryInterceptor(auth.UnaryInterceptor()))
+		service.Register(grpcServer)
+		go func() {
+			log.Printf("gRPC服务已启动: %s", grpcAddr)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Printf("gRPC服务退出: %v", err)
+			}
+		}()
+	}
+	// 设置 --http-addr 时启动健康检查服务
+	var healthServer *http.Server
+	if httpAddr != "" {
+		listener, err := net.Listen("tcp", httpAddr)
+		if err != nil {
+			log.Fatalf("健康检查服务监听失败: %v", err)
+		}
+		healthServer = newHealthServer(monitor)
+		go func() {
+			log.Printf("健康检查服务已启动: %s", httpAddr)
+			if err := healthServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				log.Printf("健康检查服务退出: %v", err)
+			}
+		}()
+	}
+	// 设置 --block-until-ready 时先同步分配到目标，期间收到停止信号则清理后退出
+	if blockUntilReady {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- monitor.AllocateToTarget(ctx) }()
+		var err error
+		select {
+		case <-sigChan:
+			log.Println("收到停止信号，取消分配并清理...")
+			cancel()
+			<-done
+			monitor.CleanupAllResources()
+			return
+		case err = <-done:
+		}
+		cancel()
+		if err != nil {
+			log.Printf("分配到目标失败: %v", err)
+			monitor.CleanupAllResources()
+			os.Exit(occupy.ExitError)
+		}
+		log.Println("已达到内存和磁盘目标，开始监控")
+	}
+	service.StartMonitor()
+
+	// 等待信号、监控因错误退出或通过gRPC停止
+	select {
+	case <-sigChan:
+		log.Println("收到停止信号，正在优雅关闭...")
+
+		// 停止监控（会等待清理完成）
+		monitor.Stop()
+	case <-monitor.Done():
+	}
+
+	// 等待进行中的请求（如 Stop）返回后再关闭gRPC服务
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+	if healthServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		healthServer.Shutdown(ctx)
+		cancel()
+	}
+	summary := monitor.Summary()
+	summary.Log()
+	if summaryJSON != "" {
+		if err := writeSummary(summaryJSON, summary); err != nil {
+			log.Printf("写入运行汇总失败: %v", err)
+		}
+	}
+
+	// 按运行结果设置退出码，便于脚本判断占用是否成功
+	outcome := monitor.Outcome()
+	if outcome.Err != nil {
+		log.Printf("程序因错误退出: %v", outcome.Err)
+	}
+	if outcome.WatchdogTrips > 0 {
+		log.Printf("运行期间安全看门狗触发了 %d 次紧急释放", outcome.WatchdogTrips)
+	}
+	if outcome.CleanupErrors > 0 {
+		log.Printf("清理时有 %d 个临时文件删除失败", outcome.CleanupErrors)
+	}
+
+	log.Println("程序已退出")
+	if code := outcome.ExitCode(); code != occupy.ExitOK {
+		os.Exit(code)
+	}
+}
+
+// newHealthServer 创建健康检查服务。健康检查只读取运行状态，不经过 --api-token 认证，
+// 使 Kubernetes 等探针无需携带令牌
+func newHealthServer(monitor *occupy.ResourceMonitor) *http.Server {
+	return &http.Server{Handler: monitor.HealthHandler()}
+}
+
+// writeSummary 将运行汇总以JSON写入文件，"-" 表示标准输出
+func writeSummary(path string, summary occupy.Summary) error {
+	if path == "-" {
+		return summary.WriteJSON(os.Stdout)
+	}
+
+	// 原子写入：磁盘占用写满该文件系统时写入失败，但不会留下不完整的文件
+	return occupy.WriteFileAtomic(path, func(w io.Writer) error {
+		return summary.WriteJSON(w)
+	})
+}
+
+// parseOptionalSize 解析可选的字节大小参数，空字符串表示0
+func parseOptionalSize(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return occupy.ParseSize(value)
+}
+
+// apiAuth 根据 --api-token（或环境变量）和 --api-public-reads 生成控制接口的认证配置
+func apiAuth() occupy.APIAuth {
+	token := apiToken
+	if token == "" {
+		token = os.Getenv(occupy.APITokenEnv)
+	}
+	return occupy.APIAuth{Token: token, PublicReads: apiPublicReads}
+}
+
+// sandboxChildCmd 沙箱模式下由父进程启动的子进程，参数与主命令相同
+var sandboxChildCmd = &cobra.Command{
+	Use:    occupy.SandboxChildCommand,
+	Hidden: true,
+	Run:    runOccupy,
+}
+
+// runSandbox 以去掉 --sandbox 的相同参数启动子进程并监督，收到停止信号时停止子进程，返回子进程的退出码
+func runSandbox(config occupy.ResourceConfig) int {
+	exe, err := os.Executable()
+	if err != nil {
+		log.Fatalf("获取可执行文件路径失败: %v", err)
+	}
+	args := []string{occupy.SandboxChildCommand}
+	for _, arg := range os.Args[1:] {
+		if arg == "--sandbox" || strings.HasPrefix(arg, "--sandbox=") {
+			continue
+		}
+		args = append(args, arg)
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	stop := make(chan struct{})
+	go func() {
+		<-sigChan
+		log.Println("收到停止信号，正在停止子进程...")
+		close(stop)
+	}()
+
+	box := &occupy.Sandbox{Config: config, Path: exe, Args: args}
+	code, err := box.Run(stop)
+	if err != nil {
+		log.Printf("沙箱模式: %v", err)
+	}
+	log.Println("程序已退出")
+	return code
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "启动HTTP服务，管理多个占用任务",
+	Run:   runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	server := occupy.NewServer()
+	httpServer := &http.Server{
+		Addr:    serveAddr,
+		Handler: apiAuth().Middleware(server.Handler()),
+	}
+
+	// 设置信号处理
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		log.Printf("HTTP服务已启动: %s", serveAddr)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("HTTP服务启动失败: %v", err)
+		}
+	}()
 
 	// 等待信号
 	<-sigChan
 	log.Println("收到停止信号，正在优雅关闭...")
 
-	// 停止监控（会等待清理完成）
-	monitor.Stop()
-	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	httpServer.Shutdown(ctx)
+
+	// 停止所有任务（会等待清理完成）
+	server.StopAll()
+
 	log.Println("程序已退出")
 }
 
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "显示当前系统资源使用情况（不占用资源）",
+	Run:   runStatus,
+}
+
+func runStatus(cmd *cobra.Command, args []string) {
+	usage, err := occupy.CollectUsage(statusDiskPath, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if statusJSON {
+		if err := usage.WriteJSON(cmd.OutOrStdout()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	usage.WriteText(cmd.OutOrStdout())
+}
+
+var probeCmd = &cobra.Command{
+	Use:   "probe",
+	Short: "探测各资源可以安全达到的最高目标（不占用资源）",
+	Run:   runProbe,
+}
+
+func runProbe(cmd *cobra.Command, args []string) {
+	if probeMemoryMargin < 0 || probeMemoryMargin >= 100 {
+		log.Fatal("内存余量必须在0-100之间")
+	}
+	if probeDiskMargin < 0 || probeDiskMargin >= 100 {
+		log.Fatal("磁盘余量必须在0-100之间")
+	}
+
+	stats, err := occupy.CollectProbeStats(probeDiskPaths, time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	result := occupy.ComputeProbe(*stats, occupy.ProbeMargins{
+		Memory: probeMemoryMargin,
+		Disk:   probeDiskMargin,
+	})
+
+	if probeJSON {
+		if err := result.WriteJSON(cmd.OutOrStdout()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	result.WriteText(cmd.OutOrStdout())
+}
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "清理之前运行残留的临时文件",
+	Run:   runClean,
+}
+
+func runClean(cmd *cobra.Command, args []string) {
+	if cleanPrefix == "" {
+		log.Fatal("临时文件名前缀不能为空")
+	}
+	if err := occupy.ValidateFilePrefix(cleanPrefix); err != nil {
+		log.Fatal(err)
+	}
+
+	removed, reclaimed, err := occupy.RemoveTempFiles(cleanDir, cleanPrefix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "已删除 %d 个临时文件，回收 %s\n", removed, occupy.FormatBytes(reclaimed))
+}
+
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "显示版本信息",
@@ -104,10 +981,102 @@ var helpCmd = &cobra.Command{
 		fmt.Println("  go-occupy -m 80 -c 70 -d 90  # 自定义配置")
 		fmt.Println("")
 		fmt.Println("参数说明:")
+		fmt.Println("  --config JSON配置文件，收到 SIGHUP 时重新加载其中的目标")
 		fmt.Println("  -m, --memory   目标内存使用百分比 (默认: 50)")
 		fmt.Println("  -c, --cpu      目标CPU使用百分比 (默认: 30)")
+		fmt.Println("  --cpu-cores-load 需要保持忙碌的核心数，如 4.5 (覆盖 --cpu)")
+		fmt.Println("  --gomaxprocs 设置 GOMAXPROCS 并作为CPU工作线程计算的核心数 (默认: 0，使用 NumCPU)")
+		fmt.Println("  --startup-order 分阶段启动的顺序，如 mem,cpu,disk 或 mem,cpu:1m,disk (默认: 同时启动)")
+		fmt.Println("  --startup-delay 分阶段启动时相邻阶段之间的默认延迟 (默认: 30s)")
+		fmt.Println("  --respect-cgroups 按 cgroup CPU 配额计算CPU工作线程数 (默认: false)")
+		fmt.Println("  --cgroup       启动时将整个进程（而不只是工作线程）移入的 cgroup (仅Linux，默认: 不移动)")
+		fmt.Println("  --control-gain 比例控制增益，每次调整补齐差距的比例 (默认: 1)")
+		fmt.Println("  --cpu-control CPU负载控制方式 duty/tokens (默认: duty)")
+		fmt.Println("  --cpu-workload CPU负载计算类型 float/int/memory (默认: float)")
+		fmt.Println("  --cpu-exclude  不施加CPU负载的核心编号，如 0,1 (仅Linux，默认: 不排除)")
+		fmt.Println("  --cpu-mix      CPU混合负载，如 float:2,int:1,memory:1，可重复指定 (覆盖 --cpu-workload)")
+		fmt.Println("  --cpu-smoothing CPU使用率平滑系数 (默认: 0.3)")
+		fmt.Println("  --cpu-cooldown 停止CPU负载后重新启动前的冷却时间 (默认: 0)")
 		fmt.Println("  -d, --disk     目标磁盘使用百分比 (默认: 40)")
-		fmt.Println("  -i, --interval 监控间隔时间 (默认: 5s)")
+		fmt.Println("  -i, --interval 监控间隔时间，最小 100ms (默认: 5s)")
+		fmt.Println("  --disk-path    统计磁盘使用率的路径 (默认: 临时文件目录所在的文件系统)")
+		fmt.Println("  --disk-target  磁盘占用目标 PATH=PERCENT，可重复指定")
+		fmt.Println("  --memory-basis 内存目标的计算基准 total/available (默认: total)")
+		fmt.Println("  --memory-release-policy 释放内存时选择内存块的顺序 tail/head/random (默认: tail)")
+		fmt.Println("  --memory-access-pattern 已分配内存的访问模式 none/sequential/random/strided (默认: none)")
+		fmt.Println("  --memory-access-workers 内存访问工作线程数 (默认: 1)")
+		fmt.Println("  --memory-verify 定期校验已分配内存的内容 (默认: false)")
+		fmt.Println("  --memory-verify-interval 两轮内存校验之间的间隔 (默认: 10s)")
+		fmt.Println("  --tag-allocations 在每个内存块开头写入标记和块编号 (默认: false)")
+		fmt.Println("  --burst-interval 每隔该时间进入一次突发窗口 (默认: 0，不启用)")
+		fmt.Println("  --burst-duration 突发窗口持续时间 (默认: 30s)")
+		fmt.Println("  --burst-memory/--burst-cpu/--burst-disk 突发窗口内的目标百分比")
+		fmt.Println("  --memory-wave  内存目标波形 flat/sawtooth/sine/square (默认: flat)")
+		fmt.Println("  --memory-wave-amplitude 波形振幅，单位百分点 (默认: 20)")
+		fmt.Println("  --memory-wave-period 波形周期 (默认: 10m)")
+		fmt.Println("  --memory-allocator, --memory-backing 内存分配方式 heap/mmap/shm/file (默认: heap)")
+		fmt.Println("  --memory-file-dir file 分配方式下映射文件所在的目录 (默认: 磁盘占用的写入目录)")
+		fmt.Println("  --log-level    日志级别 debug/info/warn/error (默认: info)")
+		fmt.Println("  --log-format   日志格式 text 或 json (默认: text)")
+		fmt.Println("  --log-file     同时将日志写入该文件")
+		fmt.Println("  --log-max-size 日志文件超过该大小时轮转，如 100MB (默认: 不轮转)")
+		fmt.Println("  --tolerance    目标容忍度，单位百分点 (默认: 5)")
+		fmt.Println("  --numa-node    mmap分配时绑定的NUMA节点 (仅Linux)")
+		fmt.Println("  --huge-pages   mmap分配时使用大页 (仅Linux)")
+		fmt.Println("  --memory-commit 写入分配的内存使其常驻，false 时只保留地址空间 (默认: true)")
+		fmt.Println("  --warmup       预热时间，采样CPU基线 (默认: 1s)")
+		fmt.Println("  --seed         所有随机选择使用的随机数种子 (默认: 基于时间)")
+		fmt.Println("  --memory-fill  内存块内容: pattern 或 random (默认: pattern)")
+		fmt.Println("  --disk-fill    临时文件内容 sequential/random/zero (默认: sequential)")
+		fmt.Println("  --file-prefix  临时文件名前缀 (默认: go_occupy_temp_)")
+		fmt.Println("  --disk-files-per-dir 每个子目录最多写入的临时文件数 (默认: 0，不创建子目录)")
+		fmt.Println("  --disk-direct-io 以直接I/O方式写入临时文件，绕过页缓存 (仅Linux)")
+		fmt.Println("  --disk-fsync   每个临时文件写入后调用 fsync (默认: false)")
+		fmt.Println("  --disk-write-rate 临时文件写入速率上限 MB/s，最小为1 (默认: 不限速)")
+		fmt.Println("  --disk-retries 临时文件写入失败后的重试次数 (默认: 2)")
+		fmt.Println("  --disk-retry-delay 临时文件写入首次重试前的等待时间 (默认: 200ms)")
+		fmt.Println("  --leak         模拟内存泄漏，只增长、从不释放")
+		fmt.Println("  --leak-rate    泄漏模式下每次调整最多增长的内存 (如 10MB)")
+		fmt.Println("  --memory-oom   危险：持续分配内存直到分配失败或进程被杀死，需同时设置 --confirm-oom")
+		fmt.Println("  --memory-oom-step OOM模式下每次分配的内存 (默认: 16MB)")
+		fmt.Println("  --memory-fragment 碎片模式，内存块大小随机 (默认: false)")
+		fmt.Println("  --memory-fragment-min / --memory-fragment-max 碎片模式下内存块大小的范围 (默认: 64KB / 100MB)")
+		fmt.Println("  --max-memory   最多分配的内存总量 (如 8GB)")
+		fmt.Println("  --rlimit-memory 通过 setrlimit 设置本进程的内存硬限制 (如 4GB，仅Unix)")
+		fmt.Println("  --min-free-memory 至少保留的可用内存 (如 2GB)")
+		fmt.Println("  --memory-baseline 开始占用前分配的固定内存，不随目标调整释放 (如 1GB)")
+		fmt.Println("  --memory-floor 可用内存下限，低于时紧急释放并暂停 (如 100MB)")
+		fmt.Println("  --max-disk     临时文件最多占用的字节数 (如 50GB)")
+		fmt.Println("  --gpu-memory   目标GPU显存使用百分比 (默认: 0，不占用；需使用 -tags gpu 编译)")
+		fmt.Println("  --gpu-device   占用显存的GPU设备编号 (默认: 0)")
+		fmt.Println("  --disk-floor   磁盘剩余空间下限，低于时紧急释放并暂停 (如 1GB)")
+		fmt.Println("  --mirror-pid   镜像指定进程的资源使用作为动态目标")
+		fmt.Println("  --mirror-factor 镜像倍数 (默认: 1)")
+		fmt.Println("  --grpc-addr gRPC控制服务监听地址 (默认: 不启用)")
+		fmt.Println("  --gc-percent   运行期间的GC目标百分比，-1 关闭自动GC (默认: 不修改)")
+		fmt.Println("  --pprof-addr   pprof 性能分析接口监听地址 (默认: 不启用)")
+		fmt.Println("  --http-addr 健康检查服务监听地址，提供 /healthz、/readyz 和 /metrics (默认: 不启用)")
+		fmt.Println("  --converge-deadline 预热结束后在该时间内未达到目标则以错误退出 (默认: 0，不检查)")
+		fmt.Println("  --report-self 输出使用情况时同时输出本进程的RSS和CPU占用 (默认: false)")
+		fmt.Println("  --label       标签 KEY=VALUE，添加到每行日志、运行汇总和 /metrics 指标中，可重复指定")
+		fmt.Println("  --summary-json 退出时将各资源使用率分布以JSON写入该文件，- 表示标准输出 (默认: 不输出)")
+		fmt.Println("  --report-interval 输出当前使用情况的间隔 (默认: 每次调整时以 debug 级别输出)")
+		fmt.Println("  --metric-timeout 单次读取指标的超时时间，负数表示不限制 (默认: 2s)")
+		fmt.Println("  --progress     显示当前使用率与目标的对比")
+		fmt.Println("  --ramp-down    停止时逐步释放资源的时长 (默认: 0，立即清理)")
+		fmt.Println("  --no-cleanup-on-error 因错误退出时保留资源以便排查")
+		fmt.Println("  --allow-tmpfs-disk 允许在 tmpfs/ramfs 上进行磁盘占用")
+		fmt.Println("  --disk-measure-only 只测量磁盘使用率，不创建临时文件 (默认: false)")
+		fmt.Println("  --api-token    gRPC控制接口的 Bearer 令牌，健康检查服务不需要令牌 (默认: 不认证)")
+		fmt.Println("  --api-public-reads 只读接口（gRPC Status）不需要令牌 (默认: false)")
+		fmt.Println("  --block-until-ready 启动监控前同步分配到目标 (默认: false)")
+		fmt.Println("  --sandbox      在子进程中执行资源占用，本进程只负责监督 (默认: false)")
+		fmt.Println("")
+		fmt.Println("子命令:")
+		fmt.Println("  serve --addr :8080           # 启动HTTP服务管理多个任务")
+		fmt.Println("  status [--json]              # 显示当前资源使用情况")
+		fmt.Println("  probe [--json]               # 探测各资源可以安全达到的最高目标")
+		fmt.Println("  clean [--disk-path DIR]      # 清理残留的临时文件")
 		fmt.Println("")
 		fmt.Println("示例:")
 		fmt.Println("  go-occupy -m 30 -c 20 -d 30  # 开发模式")
@@ -116,5 +1085,6 @@ var helpCmd = &cobra.Command{
 		fmt.Println("")
 		fmt.Println("控制:")
 		fmt.Println("  按 Ctrl+C 停止程序")
+		fmt.Println("  kill -USR1 <pid> 输出状态快照 (仅Unix)")
 	},
-} 
\ No newline at end of file
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go-occupy/pkg/occupy"
+)
+
+func TestHealthServerDoesNotRequireToken(t *testing.T) {
+	t.Setenv(occupy.APITokenEnv, "secret")
+	apiToken = ""
+	apiPublicReads = false
+	if apiAuth().Token != "secret" {
+		t.Fatal("未从环境变量读取令牌")
+	}
+
+	handler := newHealthServer(occupy.NewResourceMonitor(occupy.ResourceConfig{})).Handler
+	for _, path := range []string{"/healthz", "/readyz"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		if recorder.Code == http.StatusUnauthorized {
+			t.Errorf("%s 不带令牌返回 401", path)
+		}
+		if recorder.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s 未运行时返回 %d, want %d", path, recorder.Code, http.StatusServiceUnavailable)
+		}
+	}
+}
+
+func TestStatusCommandPrintsAllResources(t *testing.T) {
+	statusDiskPath = t.TempDir()
+	for _, jsonOutput := range []bool{false, true} {
+		statusJSON = jsonOutput
+		var out bytes.Buffer
+		statusCmd.SetOut(&out)
+		statusCmd.Run(statusCmd, nil)
+
+		sections := []string{"内存:", "CPU:", "磁盘:"}
+		if jsonOutput {
+			sections = []string{`"memory"`, `"cpu"`, `"disk"`}
+		}
+		for _, section := range sections {
+			if !strings.Contains(out.String(), section) {
+				t.Errorf("json=%v 输出缺少 %s:\n%s", jsonOutput, section, out.String())
+			}
+		}
+	}
+	statusJSON = false
+}
+
+func TestCleanCommandRemovesTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]int{
+		occupy.DefaultFilePrefix + "1700000000000000000_0.dat": 3000,
+		occupy.DefaultFilePrefix + "1700000000000000001_1.dat": 1000,
+		occupy.DefaultFilePrefix + "4242_mem_0.dat":            96,
+		"other.dat": 500,
+	}
+	for name, size := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cleanDir, cleanPrefix = dir, occupy.DefaultFilePrefix
+	var out bytes.Buffer
+	cleanCmd.SetOut(&out)
+	cleanCmd.Run(cleanCmd, nil)
+
+	want := "已删除 3 个临时文件，回收 " + occupy.FormatBytes(4096)
+	if !strings.Contains(out.String(), want) {
+		t.Fatalf("输出 = %q, want 包含 %q", out.String(), want)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "other.dat" {
+		t.Fatalf("清理后剩余 %v, want 仅 other.dat", entries)
+	}
+}
+
+// crossPlatforms 交叉编译检查覆盖的平台，与 Makefile 中的 CROSS_PLATFORMS 保持一致
+var crossPlatforms = []string{
+	"linux/amd64", "linux/arm64", "linux/386", "darwin/amd64", "darwin/arm64", "windows/amd64",
+	"freebsd/amd64", "openbsd/amd64", "netbsd/amd64", "dragonfly/amd64", "solaris/amd64",
+}
+
+func TestCrossCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("short 模式跳过交叉编译检查")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skipf("未找到 go 命令: %v", err)
+	}
+	for _, platform := range crossPlatforms {
+		goos, goarch, _ := strings.Cut(platform, "/")
+		t.Run(platform, func(t *testing.T) {
+			// vet 同时编译测试文件，能发现只在测试中使用的平台相关代码
+			cmd := exec.Command(goBin, "vet", "./...")
+			cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("%s 编译失败: %v\n%s", platform, err, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package occupy
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Abort 因错误停止监控。与 Stop 不同，设置 NoCleanupOnError 时会保留已分配的内存和临时文件
+// 以便事后排查。Abort 不等待清理完成，可通过 Done 等待
+func (rm *ResourceMonitor) Abort(err error) {
+	rm.errMutex.Lock()
+	if rm.err == nil {
+		rm.err = err
+	}
+	rm.errMutex.Unlock()
+
+	rm.closeStop()
+}
+
+// Err 获取导致监控退出的错误，正常停止时返回 nil
+func (rm *ResourceMonitor) Err() error {
+	rm.errMutex.Lock()
+	defer rm.errMutex.Unlock()
+
+	return rm.err
+}
+
+// Done 返回在监控退出且清理完成后关闭的通道
+func (rm *ResourceMonitor) Done() <-chan bool {
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
+	return rm.cleanupDone
+}
+
+// safeMonitorAndAdjust 执行一次监控和调整，发生 panic 时以错误停止监控
+func (rm *ResourceMonitor) safeMonitorAndAdjust() {
+	defer func() {
+		if r := recover(); r != nil {
+			rm.Abort(fmt.Errorf("调整过程中发生异常: %v", r))
+		}
+	}()
+
+	rm.monitorAndAdjust()
+}
+
+// cleanupOnError 因错误退出时清理资源；设置 NoCleanupOnError 时只停止CPU负载，
+// 保留内存和临时文件并输出其位置和大小
+func (rm *ResourceMonitor) cleanupOnError(err error) {
+	logErrorf("监控因错误退出: %v", err)
+	if !rm.Config.NoCleanupOnError {
+		rm.cleanupAllResources()
+		return
+	}
+
+	rm.stopCPULoad()
+	rm.stopMemoryOOM()
+	rm.stopMemoryAccess()
+	rm.stopMemoryVerify()
+	logWarnf("保留资源以便排查: 已分配内存 %s (%d 块)", FormatBytes(rm.AllocatedBytes()), rm.AllocatedChunks())
+
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
+
+	files := make([]string, 0, len(rm.tempFileSizes))
+	for file := range rm.tempFileSizes {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		logWarnf("保留临时文件: %s (%s)", file, FormatBytes(rm.tempFileSizes[file]))
+	}
+	logWarnf("保留临时文件 %d 个，共 %s", len(files), FormatBytes(rm.tempFileBytes))
+}
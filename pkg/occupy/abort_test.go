@@ -0,0 +1,95 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCancelTestMonitor 创建只占用少量内存、不占用CPU和磁盘的监控器
+func newCancelTestMonitor(t *testing.T, noCleanupOnError bool) *ResourceMonitor {
+	t.Helper()
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	return NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:    20,
+		Interval:         100 * time.Millisecond,
+		MaxMemoryBytes:   16 * 1024 * 1024,
+		NoCleanupOnError: noCleanupOnError,
+	}, newFakeMetrics(1<<30, 1<<40))
+}
+
+// runUntilAllocated 在后台运行监控，分配内存后调用 cancel 并返回导致退出的错误
+func runUntilAllocated(t *testing.T, rm *ResourceMonitor, ctx context.Context, cancel func()) error {
+	t.Helper()
+	go rm.StartContext(ctx)
+	waitFor(t, 5*time.Second, "分配内存", func() bool { return rm.AllocatedBytes() > 0 })
+	cancel()
+	select {
+	case <-rm.Done():
+		return rm.Err()
+	case <-time.After(5 * time.Second):
+		t.Fatal("取消后监控未退出")
+		return nil
+	}
+}
+
+func TestContextCauseKeepsResourcesOnError(t *testing.T) {
+	rm := newCancelTestMonitor(t, true)
+	t.Cleanup(rm.CleanupAllResources)
+	boom := errors.New("boom")
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	err := runUntilAllocated(t, rm, ctx, func() { cancel(boom) })
+	if !errors.Is(err, boom) {
+		t.Fatalf("Err = %v, want %v", err, boom)
+	}
+	if rm.AllocatedBytes() == 0 {
+		t.Fatal("设置 NoCleanupOnError 时上下文因错误结束，内存不应被清理")
+	}
+}
+
+func TestContextCancelCleansUp(t *testing.T) {
+	rm := newCancelTestMonitor(t, true)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	if err := runUntilAllocated(t, rm, ctx, cancel); err != nil {
+		t.Fatalf("Err = %v, want nil", err)
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Fatalf("正常取消后仍保留 %d 字节内存", got)
+	}
+}
+
+func TestAbortKeepsTempFilesWhenRequested(t *testing.T) {
+	for _, keep := range []bool{true, false} {
+		dir := t.TempDir()
+		t.Setenv("GO_OCCUPY_TEMP_DIR", dir)
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			DiskPercent:      1,
+			Interval:         100 * time.Millisecond,
+			NoCleanupOnError: keep,
+		}, newFakeMetrics(1<<30, 100*1024*1024))
+		t.Cleanup(rm.CleanupAllResources)
+		buf := captureLog(t, LogInfo)
+
+		done := rm.Done()
+		go rm.Start()
+		waitFor(t, 5*time.Second, "写入临时文件", func() bool { return rm.TempFileBytes() > 0 })
+		rm.Abort(errors.New("boom"))
+		<-done
+
+		remaining := dirBytes(t, dir)
+		if keep {
+			if remaining == 0 || remaining != rm.TempFileBytes() {
+				t.Fatalf("NoCleanupOnError: 目录中剩余 %d 字节, 记录 %d 字节, want 保留全部临时文件", remaining, rm.TempFileBytes())
+			}
+			if !strings.Contains(buf.String(), "保留临时文件: "+dir) {
+				t.Fatalf("未记录保留的临时文件位置:\n%s", buf)
+			}
+		} else if remaining != 0 {
+			t.Fatalf("未设置 NoCleanupOnError 时错误退出后仍剩余 %d 字节", remaining)
+		}
+	}
+}
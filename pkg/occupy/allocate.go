@@ -0,0 +1,140 @@
+package occupy
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// allocateRounds AllocateToTarget 最多测量并补足的轮数：写入临时文件会占用页缓存，
+// 分配内存后其他进程也可能释放内存，一轮分配后的使用率不一定正好达到目标
+const allocateRounds = 5
+
+// allocateSlack 与目标相差不超过该值（百分点）时视为已达到目标，避免为测量误差反复分配零碎的量
+const allocateSlack = 0.1
+
+// AllocateToTarget 同步地写入临时文件、分配内存直到达到磁盘和内存目标后返回，
+// 不经过控制增益和泄漏速率限制，但遵守 MaxMemoryBytes、MaxDiskBytes、MinFreeMemoryBytes、MemoryFloorBytes 等安全上限；
+// 因安全上限无法达到目标时输出警告并返回 nil。CPU负载不在此处启动。
+// 可以在 Start 之前调用，使测试环境在监控开始前就处于确定的状态，之后由监控循环维持
+func (rm *ResourceMonitor) AllocateToTarget(ctx context.Context) error {
+	rm.applyMemoryRlimit()
+	targets := rm.baseTargets()
+
+	for round := 0; round < allocateRounds; round++ {
+		progressed := false
+		for i, target := range rm.diskTargets() {
+			target.Percent = targets.DiskPercents[i]
+			written, err := rm.allocateDiskToTarget(ctx, target)
+			if err != nil {
+				return err
+			}
+			progressed = progressed || written > 0
+		}
+
+		allocated, err := rm.allocateMemoryToTarget(ctx, targets.MemoryPercent)
+		if err != nil {
+			return err
+		}
+		progressed = progressed || allocated > 0
+
+		if !progressed {
+			return nil
+		}
+	}
+	logWarnf("%d 轮分配后仍未完全达到目标，交由监控循环继续调整", allocateRounds)
+	return nil
+}
+
+// allocateDiskToTarget 写入临时文件直到磁盘使用率达到目标，每个文件写入前检查 ctx，返回写入的字节数
+func (rm *ResourceMonitor) allocateDiskToTarget(ctx context.Context, target DiskTarget) (uint64, error) {
+	if rm.Config.DiskMeasureOnly || target.Percent <= 0 {
+		return 0, nil
+	}
+	diskInfo, err := rm.metricsProvider().Disk(rm.measurePath(target))
+	if err != nil {
+		return 0, fmt.Errorf("获取磁盘信息失败: %v", err)
+	}
+	if diskInfo.Total == 0 || diskInfo.UsedPercent >= target.Percent-allocateSlack {
+		return 0, nil
+	}
+
+	dir := rm.writeDir(target)
+	bytes := uint64((target.Percent - diskInfo.UsedPercent) / 100.0 * float64(diskInfo.Total))
+	logInfof("写入临时文件到目标: %s %.1f%% → %.1f%% (%s)", dir, diskInfo.UsedPercent, target.Percent, FormatBytes(bytes))
+
+	var written uint64
+	for written < bytes {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		step := uint64(math.Min(float64(bytes-written), tempFileSize))
+		before := rm.TempFileBytes()
+		if err := rm.createTempFiles(dir, step); err != nil {
+			return written, &DiskError{Dir: dir, Err: err}
+		}
+		added := rm.TempFileBytes() - before
+		if added == 0 {
+			// 已达到 MaxDiskBytes
+			break
+		}
+		written += added
+	}
+	return written, nil
+}
+
+// allocateMemoryToTarget 分配内存直到内存使用率达到目标，每块分配前检查 ctx，返回分配的字节数
+func (rm *ResourceMonitor) allocateMemoryToTarget(ctx context.Context, targetPercent float64) (uint64, error) {
+	if targetPercent <= 0 || rm.Config.MemoryOOM {
+		return 0, nil
+	}
+	memInfo, err := rm.metricsProvider().Memory()
+	if err != nil {
+		return 0, fmt.Errorf("获取内存信息失败: %v", err)
+	}
+	if memInfo.Total == 0 {
+		return 0, nil
+	}
+
+	var bytes uint64
+	if rm.Config.MemoryBasis == MemoryBasisAvailable {
+		allocated := rm.AllocatedBytes()
+		if target, _ := rm.availableMemoryTarget(memInfo, allocated); target > allocated {
+			bytes = target - allocated
+		}
+	} else if memInfo.UsedPercent < targetPercent-allocateSlack {
+		bytes = uint64((targetPercent - memInfo.UsedPercent) / 100.0 * float64(memInfo.Total))
+	}
+	if floor := rm.Config.MemoryFloorBytes; floor > 0 {
+		var headroom uint64
+		if memInfo.Available > floor {
+			headroom = memInfo.Available - floor
+		}
+		bytes = uint64(math.Min(float64(bytes), float64(headroom)))
+	}
+	if bytes = rm.freeMemoryLimit(bytes, memInfo); bytes == 0 {
+		return 0, nil
+	}
+	logInfof("分配内存到目标: %.1f%% → %.1f%% (%s)", memInfo.UsedPercent, targetPercent, FormatBytes(bytes))
+
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	var allocated uint64
+	for allocated < bytes {
+		if err := ctx.Err(); err != nil {
+			return allocated, err
+		}
+		step := rm.capMemoryBytes(uint64(math.Min(float64(bytes-allocated), memoryChunkSize)))
+		if step == 0 {
+			break
+		}
+		n, err := rm.allocateChunks(step)
+		allocated += n
+		if err != nil {
+			rm.rlimitAllocFailed()
+			return allocated, fmt.Errorf("分配内存失败: %v", err)
+		}
+	}
+	return allocated, nil
+}
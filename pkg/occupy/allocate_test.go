@@ -0,0 +1,65 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// newAllocateTestMonitor 创建基础内存用量 10MB、磁盘用量 5MB（总量均为 100MB）并反映自身占用的监控器
+func newAllocateTestMonitor(t *testing.T, config ResourceConfig) *ResourceMonitor {
+	t.Helper()
+	const mb = 1024 * 1024
+	metrics := &feedbackMetrics{fakeMetrics: newFakeMetrics(100*mb, 100*mb), cores: 1}
+	metrics.setMemoryUsed(10 * mb)
+	metrics.setDiskUsed(5 * mb)
+	config.DiskTargets = []DiskTarget{{Path: t.TempDir(), Percent: config.DiskPercent}}
+	config.Interval = MinInterval
+	rm := NewResourceMonitorWithMetrics(config, metrics)
+	metrics.rm = rm
+	t.Cleanup(rm.CleanupAllResources)
+	return rm
+}
+
+func TestAllocateToTargetMeetsTargetsBeforeTicks(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := newAllocateTestMonitor(t, ResourceConfig{MemoryPercent: 30, DiskPercent: 20})
+
+	if err := rm.AllocateToTarget(context.Background()); err != nil {
+		t.Fatalf("AllocateToTarget: %v", err)
+	}
+	if !rm.LastMeasurement().Time.IsZero() {
+		t.Fatal("AllocateToTarget 运行了监控循环")
+	}
+	if got := rm.AllocatedBytes(); got != 20*mb {
+		t.Errorf("AllocatedBytes = %d, want %d", got, 20*mb)
+	}
+	if got := rm.TempFileBytes(); got != 15*mb {
+		t.Errorf("TempFileBytes = %d, want %d", got, 15*mb)
+	}
+}
+
+func TestAllocateToTargetRespectsSafetyCaps(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := newAllocateTestMonitor(t, ResourceConfig{MemoryPercent: 30, MaxMemoryBytes: 8 * mb})
+
+	if err := rm.AllocateToTarget(context.Background()); err != nil {
+		t.Fatalf("AllocateToTarget: %v", err)
+	}
+	if got := rm.AllocatedBytes(); got != 8*mb {
+		t.Errorf("AllocatedBytes = %d, want 上限 %d", got, 8*mb)
+	}
+}
+
+func TestAllocateToTargetStopsOnCanceledContext(t *testing.T) {
+	rm := newAllocateTestMonitor(t, ResourceConfig{MemoryPercent: 30})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := rm.AllocateToTarget(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("AllocateToTarget = %v, want context.Canceled", err)
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("取消后 AllocatedBytes = %d, want 0", got)
+	}
+}
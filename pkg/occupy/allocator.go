@@ -0,0 +1,94 @@
+package occupy
+
+import (
+	"os"
+)
+
+// 内存分配方式
+const (
+	// MemoryAllocatorHeap 使用Go堆分配内存（默认）
+	MemoryAllocatorHeap = "heap"
+	// MemoryAllocatorMmap 使用匿名mmap分配内存，不受Go GC管理
+	MemoryAllocatorMmap = "mmap"
+	// MemoryAllocatorShm 使用 /dev/shm 共享内存段分配内存（仅Linux），计入共享内存
+	MemoryAllocatorShm = "shm"
+	// MemoryAllocatorFile 使用磁盘上的文件映射分配内存（仅Unix），同时计入文件页缓存和磁盘使用
+	MemoryAllocatorFile = "file"
+)
+
+// memoryAllocator 内存分配器
+type memoryAllocator interface {
+	// alloc 分配指定大小的内存块
+	alloc(size uint64) ([]byte, error)
+	// free 释放由 alloc 分配的内存块
+	free(chunk []byte) error
+	// managedByGC 分配的内存是否由Go GC回收
+	managedByGC() bool
+}
+
+// pageAlign 将内存块大小向上对齐到页大小的整数倍。
+// 每个内存块最多因此多分配不足一页的内存
+func pageAlign(size uint64) uint64 {
+	return alignUp(size, uint64(os.Getpagesize()))
+}
+
+// newMemoryAllocator 根据配置创建内存分配器
+func newMemoryAllocator(config ResourceConfig) memoryAllocator {
+	switch config.MemoryAllocator {
+	case "", MemoryAllocatorHeap:
+		if config.UseHugePages {
+			logWarnf("大页仅在 %s 分配方式下生效", MemoryAllocatorMmap)
+		}
+		if config.NUMABind {
+			logWarnf("NUMA绑定仅在 %s 分配方式下生效", MemoryAllocatorMmap)
+		}
+		return heapAllocator{}
+	case MemoryAllocatorMmap:
+		numaNode := -1
+		if config.NUMABind {
+			numaNode = config.NUMANode
+		}
+		return &mmapAllocator{hugePages: config.UseHugePages, numaNode: numaNode}
+	case MemoryAllocatorShm:
+		if config.UseHugePages || config.NUMABind {
+			logWarnf("大页和NUMA绑定仅在 %s 分配方式下生效", MemoryAllocatorMmap)
+		}
+		return newShmAllocator(config.FilePrefix)
+	case MemoryAllocatorFile:
+		if config.UseHugePages || config.NUMABind {
+			logWarnf("大页和NUMA绑定仅在 %s 分配方式下生效", MemoryAllocatorMmap)
+		}
+		return newFileAllocator(memoryFileDir(config), config.FilePrefix, "mem")
+	default:
+		logWarnf("未知的内存分配方式: %s，使用 %s", config.MemoryAllocator, MemoryAllocatorHeap)
+		return heapAllocator{}
+	}
+}
+
+// memoryFileDir 获取文件映射分配方式的文件目录：未设置 MemoryFileDir 时
+// 使用第一个磁盘目标的写入目录
+func memoryFileDir(config ResourceConfig) string {
+	if config.MemoryFileDir != "" {
+		return config.MemoryFileDir
+	}
+	if len(config.DiskTargets) > 0 && config.DiskTargets[0].Path != "" {
+		return config.DiskTargets[0].Path
+	}
+	return defaultTempDir()
+}
+
+// heapAllocator 基于Go堆的内存分配器，内存块起始地址按页对齐
+// （mmap分配的内存天然按页对齐）
+type heapAllocator struct{}
+
+func (heapAllocator) alloc(size uint64) ([]byte, error) {
+	return alignedBuffer(int(size), os.Getpagesize()), nil
+}
+
+func (heapAllocator) free(chunk []byte) error {
+	return nil
+}
+
+func (heapAllocator) managedByGC() bool {
+	return true
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package occupy
+
+import (
+	"errors"
+)
+
+// fileAllocator 当前平台不支持文件映射分配
+type fileAllocator struct{}
+
+func newFileAllocator(dir, prefix, kind string) *fileAllocator {
+	return &fileAllocator{}
+}
+
+func (a *fileAllocator) alloc(size uint64) ([]byte, error) {
+	return nil, errors.New("当前平台不支持文件映射内存分配")
+}
+
+func (a *fileAllocator) free(chunk []byte) error {
+	return nil
+}
+
+func (a *fileAllocator) managedByGC() bool {
+	return false
+}
@@ -0,0 +1,85 @@
+//go:build unix
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// fileAllocator 基于文件映射的内存分配器，每个内存块对应目录中的一个文件，
+// 以 MAP_SHARED 映射后写入内容使页面常驻，释放时解除映射并删除文件
+type fileAllocator struct {
+	dir    string
+	prefix string
+	kind   string // 文件名中的类型标识，如 shm、mem
+
+	mu       sync.Mutex
+	segments map[uintptr]string // 映射起始地址到文件路径
+	seq      int
+}
+
+// newFileAllocator 创建文件映射分配器，文件名使用临时文件前缀，
+// 异常退出后残留的文件可以用 clean --disk-path <dir> 清理
+func newFileAllocator(dir, prefix, kind string) *fileAllocator {
+	if prefix == "" {
+		prefix = DefaultFilePrefix
+	}
+	return &fileAllocator{dir: dir, prefix: prefix, kind: kind, segments: make(map[uintptr]string)}
+}
+
+func (a *fileAllocator) alloc(size uint64) ([]byte, error) {
+	a.mu.Lock()
+	a.seq++
+	path := filepath.Join(a.dir, fmt.Sprintf("%s%d_%s_%d.dat", a.prefix, os.Getpid(), a.kind, a.seq))
+	a.mu.Unlock()
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("创建映射文件失败: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Truncate(int64(size)); err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("设置映射文件大小失败: %w", err)
+	}
+	chunk, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("映射文件失败: %w", err)
+	}
+
+	a.mu.Lock()
+	a.segments[uintptr(unsafe.Pointer(&chunk[0]))] = path
+	a.mu.Unlock()
+	return chunk, nil
+}
+
+func (a *fileAllocator) free(chunk []byte) error {
+	if len(chunk) == 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	key := uintptr(unsafe.Pointer(&chunk[0]))
+	path, ok := a.segments[key]
+	delete(a.segments, key)
+	a.mu.Unlock()
+
+	err := syscall.Munmap(chunk)
+	if ok {
+		if removeErr := os.Remove(path); removeErr != nil && err == nil {
+			err = fmt.Errorf("删除映射文件失败: %w", removeErr)
+		}
+	}
+	return err
+}
+
+func (a *fileAllocator) managedByGC() bool {
+	return false
+}
@@ -0,0 +1,77 @@
+//go:build unix
+
+package occupy
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestFileAllocatorCleanupRemovesMappingAndFile(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryAllocator: MemoryAllocatorFile,
+		MemoryFileDir:   dir,
+		Interval:        MinInterval,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+
+	rm.AllocateMemory(2 * mb)
+	rm.memoryMutex.Lock()
+	addrs := make([]uintptr, 0, len(rm.AllocatedMemory))
+	for _, chunk := range rm.AllocatedMemory {
+		addrs = append(addrs, uintptr(unsafe.Pointer(&chunk[0])))
+	}
+	rm.memoryMutex.Unlock()
+	if len(addrs) == 0 {
+		t.Fatal("未分配任何内存块")
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, DefaultFilePrefix+"*_mem_*.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(addrs) {
+		rm.CleanupAllResources()
+		t.Fatalf("映射文件 = %v, want %d 个", files, len(addrs))
+	}
+	for _, addr := range addrs {
+		if mapped, ok := mappedAt(addr); ok && !mapped {
+			t.Fatalf("地址 %x 分配后映射不存在", addr)
+		}
+	}
+
+	rm.CleanupAllResources()
+	// 释放后的地址可能立即被运行时的其他映射复用，因此按文件路径检查映射是否已解除
+	for _, file := range files {
+		if mapped, ok := fileMapped(file); ok && mapped {
+			t.Errorf("映射文件 %s 清理后映射仍然存在", file)
+		}
+	}
+	for _, file := range files {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("清理后映射文件 %s 仍然存在: %v", file, err)
+		}
+	}
+}
+
+// fileMapped 报告 /proc/self/maps 中是否仍有映射指向 path，ok 为 false 表示无法读取映射表
+func fileMapped(path string) (mapped, ok bool) {
+	file, err := os.Open("/proc/self/maps")
+	if err != nil {
+		return false, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), path) {
+			return true, true
+		}
+	}
+	return false, true
+}
@@ -0,0 +1,26 @@
+//go:build !unix
+
+package occupy
+
+import (
+	"errors"
+)
+
+// mmapAllocator 当前平台不支持mmap分配
+
+type mmapAllocator struct {
+	hugePages bool
+	numaNode  int
+}
+
+func (a *mmapAllocator) alloc(size uint64) ([]byte, error) {
+	return nil, errors.New("当前平台不支持mmap内存分配")
+}
+
+func (a *mmapAllocator) free(chunk []byte) error {
+	return nil
+}
+
+func (a *mmapAllocator) managedByGC() bool {
+	return false
+}
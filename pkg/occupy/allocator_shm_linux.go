@@ -0,0 +1,17 @@
+//go:build linux
+
+package occupy
+
+// shmDir POSIX共享内存所在的目录
+const shmDir = "/dev/shm"
+
+// newShmAllocator 创建基于 /dev/shm 共享内存段的分配器，
+// 占用的内存计入共享内存（Shmem）而非进程私有的匿名页
+func newShmAllocator(prefix string) memoryAllocator {
+	return newFileAllocator(shmDir, prefix, "shm")
+}
+
+// shmSegmentDir 共享内存分配方式的文件所在目录
+func shmSegmentDir() string {
+	return shmDir
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package occupy
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestShmAllocatorUnlinksSegmentsOnCleanup(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	prefix := "go_occupy_shm_test_"
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryAllocator: MemoryAllocatorShm,
+		FilePrefix:      prefix,
+		Interval:        MinInterval,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+	pattern := filepath.Join(shmDir, prefix+"*")
+
+	rm.AllocateMemory(4 * mb)
+	if got := rm.AllocatedBytes(); got != 4*mb {
+		rm.CleanupAllResources()
+		t.Fatalf("AllocatedBytes = %d, want %d", got, 4*mb)
+	}
+	segments, err := filepath.Glob(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(segments) == 0 {
+		rm.CleanupAllResources()
+		t.Fatalf("%s 下没有共享内存段", shmDir)
+	}
+
+	rm.CleanupAllResources()
+	if segments, _ := filepath.Glob(pattern); len(segments) != 0 {
+		t.Fatalf("清理后仍有共享内存段: %v", segments)
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !linux
+
+package occupy
+
+import (
+	"errors"
+)
+
+// shmAllocator 当前平台不支持共享内存分配
+type shmAllocator struct{}
+
+func newShmAllocator(prefix string) memoryAllocator {
+	return shmAllocator{}
+}
+
+func (shmAllocator) alloc(size uint64) ([]byte, error) {
+	return nil, errors.New("共享内存分配仅支持Linux")
+}
+
+func (shmAllocator) free(chunk []byte) error {
+	return nil
+}
+
+func (shmAllocator) managedByGC() bool {
+	return false
+}
+
+// shmSegmentDir 当前平台不支持共享内存分配
+func shmSegmentDir() string {
+	return ""
+}
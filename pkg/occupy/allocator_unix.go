@@ -0,0 +1,56 @@
+//go:build unix
+
+package occupy
+
+import (
+	"syscall"
+)
+
+// mmapAllocator 基于匿名mmap的内存分配器
+type mmapAllocator struct {
+	// hugePages 是否尝试使用大页
+	hugePages bool
+	// hugePagesUnavailable 大页分配失败后回退到普通页
+	hugePagesUnavailable bool
+	// numaNode 绑定的NUMA节点，小于0表示不绑定
+	numaNode int
+	// numaUnavailable NUMA绑定失败后按默认策略分配
+	numaUnavailable bool
+}
+
+func (a *mmapAllocator) alloc(size uint64) ([]byte, error) {
+	chunk, err := a.mmap(size)
+	if err != nil {
+		return nil, err
+	}
+	if a.numaNode >= 0 && !a.numaUnavailable {
+		if err := bindNUMANode(chunk, a.numaNode); err != nil {
+			a.numaUnavailable = true
+			logWarnf("NUMA绑定不可用，按默认策略分配: %v", err)
+		}
+	}
+	return chunk, nil
+}
+
+// mmap 创建匿名映射，启用大页时优先使用大页
+func (a *mmapAllocator) mmap(size uint64) ([]byte, error) {
+	if a.hugePages && !a.hugePagesUnavailable {
+		chunk, err := mmapHugePages(size)
+		if err == nil {
+			return chunk, nil
+		}
+		a.hugePagesUnavailable = true
+		logWarnf("大页不可用，回退到普通页: %v", err)
+	}
+	return syscall.Mmap(-1, 0, int(size), syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// free 按容量释放整个映射：大页映射的长度向上取整到大页大小，切片长度可能小于容量，
+// 而 Munmap 要求长度与容量相同
+func (a *mmapAllocator) free(chunk []byte) error {
+	return syscall.Munmap(chunk[:cap(chunk)])
+}
+
+func (a *mmapAllocator) managedByGC() bool {
+	return false
+}
@@ -0,0 +1,132 @@
+//go:build unix
+
+package occupy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+// mappedAt 检查地址是否落在 /proc/self/maps 的某个映射内，无法读取时返回 ok=false
+func mappedAt(addr uintptr) (mapped, ok bool) {
+	file, err := os.Open("/proc/self/maps")
+	if err != nil {
+		return false, false
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var start, end uintptr
+		bounds, _, _ := strings.Cut(scanner.Text(), " ")
+		if _, err := fmt.Sscanf(bounds, "%x-%x", &start, &end); err != nil {
+			continue
+		}
+		if addr >= start && addr < end {
+			return true, true
+		}
+	}
+	return false, true
+}
+
+func TestMmapAllocatorAllocAndFree(t *testing.T) {
+	allocator := mmapAllocator{}
+	if allocator.managedByGC() {
+		t.Fatal("mmap 分配的内存不应由GC管理")
+	}
+
+	size := uint64(3*1024*1024 + os.Getpagesize())
+	chunk, err := allocator.alloc(size)
+	if err != nil {
+		t.Fatalf("alloc: %v", err)
+	}
+	if uint64(len(chunk)) != size {
+		t.Fatalf("len = %d, want %d", len(chunk), size)
+	}
+	for i := range chunk {
+		chunk[i] = byte(i % 251)
+	}
+	for i := range chunk {
+		if chunk[i] != byte(i%251) {
+			t.Fatalf("偏移 %d 内容被改变", i)
+		}
+	}
+
+	addr := uintptr(unsafe.Pointer(&chunk[0]))
+	if mapped, ok := mappedAt(addr); ok && !mapped {
+		t.Fatal("分配后映射不存在")
+	}
+	if err := allocator.free(chunk); err != nil {
+		t.Fatalf("free: %v", err)
+	}
+	if mapped, ok := mappedAt(addr); ok && mapped {
+		t.Fatal("释放后映射仍然存在")
+	}
+}
+
+func TestMmapMonitorReleasesMappings(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		Interval:        time.Second,
+		MemoryAllocator: MemoryAllocatorMmap,
+	})
+
+	rm.AllocateMemory(8 * 1024 * 1024)
+	if got := rm.getTotalAllocatedMemory(); got != 8*1024*1024 {
+		t.Fatalf("AllocatedBytes = %d, want %d", got, 8*1024*1024)
+	}
+	addr := uintptr(unsafe.Pointer(&rm.AllocatedMemory[0][0]))
+
+	rm.CleanupAllResources()
+	if got := rm.getTotalAllocatedMemory(); got != 0 {
+		t.Fatalf("清理后 AllocatedBytes = %d, want 0", got)
+	}
+	if mapped, ok := mappedAt(addr); ok && mapped {
+		t.Fatal("清理后映射仍然存在")
+	}
+}
+
+func TestMmapNoCommitReservesWithoutResidentPages(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		Interval:        MinInterval,
+		MemoryAllocator: MemoryAllocatorMmap,
+		MemoryNoCommit:  true,
+	}, newFakeMetrics(1<<30, 1<<40))
+	before, err := rm.SelfUsage()
+	if err != nil {
+		t.Skipf("无法获取本进程常驻内存: %v", err)
+	}
+
+	rm.AllocateMemory(512 * mb)
+	if got := rm.AllocatedBytes(); got != 512*mb {
+		t.Fatalf("AllocatedBytes = %d, want %d", got, 512*mb)
+	}
+	after, err := rm.SelfUsage()
+	if err != nil {
+		t.Fatalf("SelfUsage: %v", err)
+	}
+	// 未访问的页面不常驻，常驻内存几乎不增长
+	if after.RSS > before.RSS+64*mb {
+		t.Errorf("保留 512MB 地址空间后常驻内存从 %d 增长到 %d", before.RSS, after.RSS)
+	}
+
+	addrs := make([]uintptr, 0, len(rm.AllocatedMemory))
+	for _, chunk := range rm.AllocatedMemory {
+		addr := uintptr(unsafe.Pointer(&chunk[0]))
+		if mapped, ok := mappedAt(addr); ok && !mapped {
+			t.Fatalf("保留的地址 %#x 没有映射", addr)
+		}
+		addrs = append(addrs, addr)
+	}
+	rm.cleanupMemory()
+	for _, addr := range addrs {
+		if mapped, ok := mappedAt(addr); ok && mapped {
+			t.Errorf("清理后地址 %#x 仍有映射", addr)
+		}
+	}
+}
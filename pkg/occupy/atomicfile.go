@@ -0,0 +1,79 @@
+package occupy
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFileAtomic 先将内容写入 path 所在目录的临时文件并同步到磁盘，成功后再重命名为 path。
+// 写入失败（如磁盘占用已将该文件系统写满）时删除临时文件并返回错误，path 原有的内容保持不变
+func WriteFileAtomic(path string, write func(io.Writer) error) error {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	file, err := createTempNear(dir, "."+base+".tmp-")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmpPath := file.Name()
+
+	// 替换已有文件时沿用其权限，新文件的权限与 os.Create 相同
+	if info, statErr := os.Stat(path); statErr == nil {
+		err = file.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = write(file)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入 %s 失败，保留原有内容: %w", path, err)
+	}
+	return nil
+}
+
+// createTempNear 在 dir 中创建名称以 prefix 开头的新文件。与 os.CreateTemp 固定使用 0600 不同，
+// 权限与 os.Create 相同（0666，受 umask 限制）
+func createTempNear(dir, prefix string) (*os.File, error) {
+	for try := 0; ; try++ {
+		name := filepath.Join(dir, prefix+strconv.FormatUint(uint64(rand.Uint32()), 10))
+		file, err := os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0666)
+		if os.IsExist(err) && try < 10000 {
+			continue
+		}
+		return file, err
+	}
+}
+
+// SharesDiskTarget 返回与 path 位于同一文件系统的磁盘占用目录，没有时返回空字符串。
+// 写在这些文件系统上的文件可能因磁盘占用写满而无法写入
+func SharesDiskTarget(config ResourceConfig, path string) string {
+	if config.DiskMeasureOnly {
+		return ""
+	}
+	dir := filepath.Dir(path)
+	rm := &ResourceMonitor{Config: config}
+	for _, target := range rm.diskTargets() {
+		if target.Percent <= 0 {
+			continue
+		}
+		writeDir := rm.writeDir(target)
+		if same, err := sameFilesystem(dir, writeDir); err == nil && same {
+			return writeDir
+		}
+	}
+	return ""
+}
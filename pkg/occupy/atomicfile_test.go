@@ -0,0 +1,74 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomicFullVolumeKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.json")
+	if err := os.WriteFile(path, []byte(`{"old":true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 写入一部分后文件系统已满
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		if _, err := io.WriteString(w, `{"new":`); err != nil {
+			return err
+		}
+		return syscall.ENOSPC
+	})
+	if !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("WriteFileAtomic = %v, want ENOSPC", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"old":true}` {
+		t.Errorf("写入失败后文件内容 = %q, want 原有内容", data)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("写入失败后目录中有 %d 个文件, want 仅保留原文件", len(entries))
+	}
+}
+
+func TestWriteFileAtomicReplacesContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	for _, content := range []string{"first", "second"} {
+		err := WriteFileAtomic(path, func(w io.Writer) error {
+			_, err := fmt.Fprint(w, content)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("WriteFileAtomic: %v", err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != content {
+			t.Errorf("文件内容 = %q, want %q", data, content)
+		}
+	}
+}
+
+func TestSharesDiskTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "summary.json")
+	config := ResourceConfig{DiskTargets: []DiskTarget{{Path: dir, Percent: 50}}}
+
+	if got := SharesDiskTarget(config, path); got != dir {
+		t.Errorf("SharesDiskTarget = %q, want %q", got, dir)
+	}
+	config.DiskMeasureOnly = true
+	if got := SharesDiskTarget(config, path); got != "" {
+		t.Errorf("只测量磁盘时 SharesDiskTarget = %q, want 空", got)
+	}
+}
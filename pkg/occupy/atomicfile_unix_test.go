@@ -0,0 +1,51 @@
+//go:build unix
+
+package occupy
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFileAtomicKeepsFileMode(t *testing.T) {
+	old := syscall.Umask(0022)
+	defer syscall.Umask(old)
+
+	dir := t.TempDir()
+	write := func(w io.Writer) error {
+		_, err := io.WriteString(w, "{}")
+		return err
+	}
+	for _, c := range []struct {
+		name     string
+		existing os.FileMode // 0 表示文件不存在
+		want     os.FileMode
+	}{
+		// 新文件与 os.Create 相同：0666 去掉 umask
+		{"新文件", 0, 0644},
+		{"已有文件", 0640, 0640},
+	} {
+		path := filepath.Join(dir, c.name+".json")
+		if c.existing != 0 {
+			if err := os.WriteFile(path, nil, c.existing); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chmod(path, c.existing); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := WriteFileAtomic(path, write); err != nil {
+			t.Fatalf("%s: WriteFileAtomic: %v", c.name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm(); got != c.want {
+			t.Errorf("%s: 权限 = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
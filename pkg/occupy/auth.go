@@ -0,0 +1,73 @@
+package occupy
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"go-occupy/pkg/occupypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APITokenEnv 未通过参数指定令牌时读取的环境变量，避免令牌出现在进程列表中
+const APITokenEnv = "GO_OCCUPY_API_TOKEN"
+
+// APIAuth 控制接口的 Bearer 令牌认证
+type APIAuth struct {
+	// Token 请求需在 Authorization 头（gRPC 为 authorization 元数据）中携带 "Bearer <Token>"，为空表示不认证
+	Token string
+	// PublicReads 只读接口（HTTP GET/HEAD 请求和 gRPC Status、ListTempFiles）不需要令牌
+	PublicReads bool
+}
+
+// authorized 检查 Authorization 值是否携带正确的令牌
+func (a APIAuth) authorized(header string) bool {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1
+}
+
+// Middleware 为HTTP处理器添加令牌认证，令牌缺失或错误时返回 401
+func (a APIAuth) Middleware(next http.Handler) http.Handler {
+	if a.Token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		read := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if (a.PublicReads && read) || a.authorized(r.Header.Get("Authorization")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Bearer realm="go-occupy"`)
+		writeError(w, http.StatusUnauthorized, "缺少或错误的API令牌")
+	})
+}
+
+// publicGRPCMethods 设置 PublicReads 时不需要令牌的只读 gRPC 方法
+var publicGRPCMethods = map[string]bool{
+	occupypb.Occupy_Status_FullMethodName:        true,
+	occupypb.Occupy_ListTempFiles_FullMethodName: true,
+}
+
+// UnaryInterceptor 为 gRPC 服务添加令牌认证，令牌缺失或错误时返回 Unauthenticated
+func (a APIAuth) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if a.Token == "" || (a.PublicReads && publicGRPCMethods[info.FullMethod]) {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			if a.authorized(value) {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "缺少或错误的API令牌")
+	}
+}
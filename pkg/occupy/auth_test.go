@@ -0,0 +1,110 @@
+package occupy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-occupy/pkg/occupypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// authStatus 携带 Authorization 头（为空时不携带）发送请求，返回状态码
+func authStatus(t *testing.T, method, url, authorization string) int {
+	t.Helper()
+	var body string
+	if method == http.MethodPost {
+		body = `{"name":"auth"}`
+	}
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestAPIAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		publicReads   bool
+		method        string
+		authorization string
+		want          int
+	}{
+		{"缺少令牌", false, http.MethodPost, "", http.StatusUnauthorized},
+		{"错误令牌", false, http.MethodPost, "Bearer wrong", http.StatusUnauthorized},
+		{"缺少 Bearer 前缀", false, http.MethodPost, "secret", http.StatusUnauthorized},
+		{"正确令牌", false, http.MethodPost, "Bearer secret", http.StatusCreated},
+		{"读取也需要令牌", false, http.MethodGet, "", http.StatusUnauthorized},
+		{"公开读取", true, http.MethodGet, "", http.StatusOK},
+		{"公开读取时控制仍需要令牌", true, http.MethodPost, "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			auth := APIAuth{Token: "secret", PublicReads: tt.publicReads}
+			ts := httptest.NewServer(auth.Middleware(s.Handler()))
+			defer ts.Close()
+
+			if got := authStatus(t, tt.method, ts.URL+"/jobs", tt.authorization); got != tt.want {
+				t.Errorf("状态码 = %d, want %d", got, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && len(s.ListJobs()) != 0 {
+				t.Errorf("未认证的请求创建了任务")
+			}
+		})
+	}
+}
+
+func TestAPIAuthWithoutTokenAllowsAll(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(APIAuth{}.Middleware(s.Handler()))
+	defer ts.Close()
+
+	if got := authStatus(t, http.MethodPost, ts.URL+"/jobs", ""); got != http.StatusCreated {
+		t.Errorf("未设置令牌时状态码 = %d, want %d", got, http.StatusCreated)
+	}
+}
+
+func TestAPIAuthUnaryInterceptor(t *testing.T) {
+	interceptor := APIAuth{Token: "secret", PublicReads: true}.UnaryInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	call := func(method, authorization string) error {
+		ctx := context.Background()
+		if authorization != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", authorization))
+		}
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+		return err
+	}
+
+	if err := call(occupypb.Occupy_Stop_FullMethodName, "Bearer wrong"); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("错误令牌 = %v, want Unauthenticated", err)
+	}
+	if err := call(occupypb.Occupy_Stop_FullMethodName, "Bearer secret"); err != nil {
+		t.Errorf("正确令牌 = %v, want nil", err)
+	}
+	if err := call(occupypb.Occupy_Status_FullMethodName, ""); err != nil {
+		t.Errorf("公开读取 Status = %v, want nil", err)
+	}
+	if err := call(occupypb.Occupy_ListTempFiles_FullMethodName, ""); err != nil {
+		t.Errorf("公开读取 ListTempFiles = %v, want nil", err)
+	}
+	if err := call(occupypb.Occupy_DropTempFile_FullMethodName, ""); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("无令牌 DropTempFile = %v, want Unauthenticated", err)
+	}
+}
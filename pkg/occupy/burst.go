@@ -0,0 +1,49 @@
+package occupy
+
+import "time"
+
+// inBurst 判断 now 时刻是否处于突发窗口：从开始监控起每隔 BurstInterval
+// 进入一次突发，持续 BurstDuration（仅由监控协程调用）
+func (rm *ResourceMonitor) inBurst(now time.Time) bool {
+	interval := rm.Config.BurstInterval
+	if interval <= 0 || rm.Config.BurstDuration <= 0 {
+		return false
+	}
+
+	if rm.burstStart.IsZero() {
+		rm.burstStart = now
+	}
+	elapsed := now.Sub(rm.burstStart)
+	return elapsed >= interval && elapsed%interval < rm.Config.BurstDuration
+}
+
+// applyBurst 处于突发窗口时用突发目标覆盖基础目标，未设置的突发目标保持基础目标
+func (rm *ResourceMonitor) applyBurst(targets Targets, now time.Time) Targets {
+	bursting := rm.inBurst(now)
+	if bursting != rm.bursting {
+		rm.bursting = bursting
+		if bursting {
+			logInfof("进入突发窗口，持续 %v", rm.Config.BurstDuration)
+		} else {
+			logInfof("突发窗口结束，恢复基础目标")
+		}
+	}
+	if !bursting {
+		return targets
+	}
+
+	if rm.Config.BurstMemoryPercent > 0 {
+		targets.MemoryPercent = rm.Config.BurstMemoryPercent
+	}
+	if rm.Config.BurstCPUPercent > 0 {
+		targets.CPUPercent = rm.Config.BurstCPUPercent
+	}
+	if rm.Config.BurstDiskPercent > 0 {
+		diskPercents := make([]float64, len(targets.DiskPercents))
+		for i := range diskPercents {
+			diskPercents[i] = rm.Config.BurstDiskPercent
+		}
+		targets.DiskPercents = diskPercents
+	}
+	return targets
+}
@@ -0,0 +1,48 @@
+package occupy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBurstWindowSwitchesTargets(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent:      30,
+		CPUPercent:         20,
+		DiskPercent:        10,
+		BurstInterval:      10 * time.Second,
+		BurstDuration:      2 * time.Second,
+		BurstMemoryPercent: 80,
+		BurstCPUPercent:    90,
+	})
+	base := Targets{MemoryPercent: 30, CPUPercent: 20, DiskPercents: []float64{10}}
+	start := time.Unix(1700000000, 0)
+
+	for _, c := range []struct {
+		at    time.Duration
+		burst bool
+	}{
+		{0, false},
+		{5 * time.Second, false},
+		{10 * time.Second, true},
+		{11 * time.Second, true},
+		{12 * time.Second, false},
+		{19 * time.Second, false},
+		{21 * time.Second, true},
+		{23 * time.Second, false},
+	} {
+		got := rm.applyBurst(base, start.Add(c.at))
+		wantMem, wantCPU := 30.0, 20.0
+		if c.burst {
+			wantMem, wantCPU = 80, 90
+		}
+		if got.MemoryPercent != wantMem || got.CPUPercent != wantCPU {
+			t.Fatalf("%v: 目标 内存 %.0f%% CPU %.0f%%, want %.0f%% %.0f%%",
+				c.at, got.MemoryPercent, got.CPUPercent, wantMem, wantCPU)
+		}
+		// 未设置突发磁盘目标时保持基础目标
+		if len(got.DiskPercents) != 1 || got.DiskPercents[0] != 10 {
+			t.Fatalf("%v: 磁盘目标 = %v, want [10]", c.at, got.DiskPercents)
+		}
+	}
+}
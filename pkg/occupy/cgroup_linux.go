@@ -0,0 +1,100 @@
+//go:build linux
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot cgroup 文件系统的挂载点
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupCPUQuota 读取 root 下的 cgroup CPU 配额，返回配额对应的核心数（可为小数），
+// 依次尝试 v2 的 cpu.max 和 v1 的 cpu.cfs_quota_us/cpu.cfs_period_us；未设置配额时返回 0
+func cgroupCPUQuota(root string) (float64, error) {
+	if data, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("无法解析 cpu.max: %q", strings.TrimSpace(string(data)))
+		}
+		if fields[0] == "max" {
+			return 0, nil
+		}
+		return parseCPUQuota(fields[0], fields[1])
+	}
+
+	for _, dir := range []string{"cpu", "cpu,cpuacct", "cpuacct,cpu"} {
+		quota, err := os.ReadFile(filepath.Join(root, dir, "cpu.cfs_quota_us"))
+		if err != nil {
+			continue
+		}
+		period, err := os.ReadFile(filepath.Join(root, dir, "cpu.cfs_period_us"))
+		if err != nil {
+			return 0, err
+		}
+		if strings.TrimSpace(string(quota)) == "-1" {
+			return 0, nil
+		}
+		return parseCPUQuota(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+	}
+	return 0, nil
+}
+
+// parseCPUQuota 将配额和周期（微秒）换算为核心数
+func parseCPUQuota(quota, period string) (float64, error) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil {
+		return 0, fmt.Errorf("无法解析CPU配额 %q: %v", quota, err)
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, fmt.Errorf("无法解析CPU配额周期 %q", period)
+	}
+	if q <= 0 {
+		return 0, nil
+	}
+	return q / p, nil
+}
+
+// cgroupUnlimited cgroup v1 未设置内存限制时 memory.limit_in_bytes 为接近 int64 上限的值，超过该值视为未限制
+const cgroupUnlimited = 1 << 62
+
+// cgroupMemoryLimit 读取 root 下的 cgroup 内存限制和当前用量，
+// 依次尝试 v2 的 memory.max/memory.current 和 v1 的 memory.limit_in_bytes/memory.usage_in_bytes；未设置限制时 limit 为 0
+func cgroupMemoryLimit(root string) (limit, usage uint64, err error) {
+	files := [][2]string{
+		{"memory.max", "memory.current"},
+		{filepath.Join("memory", "memory.limit_in_bytes"), filepath.Join("memory", "memory.usage_in_bytes")},
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(filepath.Join(root, f[0]))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "max" {
+			return 0, 0, nil
+		}
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无法解析 cgroup 内存限制 %q: %v", value, err)
+		}
+		if limit >= cgroupUnlimited {
+			return 0, 0, nil
+		}
+		data, err = os.ReadFile(filepath.Join(root, f[1]))
+		if err != nil {
+			return 0, 0, err
+		}
+		usage, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("无法解析 cgroup 内存用量: %v", err)
+		}
+		return limit, usage, nil
+	}
+	return 0, 0, nil
+}
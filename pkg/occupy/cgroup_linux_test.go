@@ -0,0 +1,83 @@
+//go:build linux
+
+package occupy
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// writeCgroupFiles 在临时目录中写入伪造的 cgroup 文件，返回该目录
+func writeCgroupFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestCgroupCPUQuota(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  float64
+	}{
+		{"v2", map[string]string{"cpu.max": "50000 100000\n"}, 0.5},
+		{"v2 未限制", map[string]string{"cpu.max": "max 100000\n"}, 0},
+		{"v1", map[string]string{"cpu,cpuacct/cpu.cfs_quota_us": "150000\n", "cpu,cpuacct/cpu.cfs_period_us": "100000\n"}, 1.5},
+		{"v1 未限制", map[string]string{"cpu/cpu.cfs_quota_us": "-1\n", "cpu/cpu.cfs_period_us": "100000\n"}, 0},
+		{"无配额文件", nil, 0},
+	}
+	for _, tt := range tests {
+		got, err := cgroupCPUQuota(writeCgroupFiles(t, tt.files))
+		if err != nil {
+			t.Errorf("%s: cgroupCPUQuota: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: cgroupCPUQuota = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := cgroupCPUQuota(writeCgroupFiles(t, map[string]string{"cpu.max": "garbage\n"})); err == nil {
+		t.Error("无法解析的 cpu.max 未返回错误")
+	}
+}
+
+func TestCPUQuotaReducesEffectiveCores(t *testing.T) {
+	root := writeCgroupFiles(t, map[string]string{"cpu.max": "50000 100000\n"})
+	newMonitor := func(respect bool) *ResourceMonitor {
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			CPUPercent:     100,
+			RespectCgroups: respect,
+			CgroupPath:     root,
+			Interval:       MinInterval,
+		}, newFakeMetrics(1<<30, 1<<40))
+		rm.detectCPUQuota()
+		return rm
+	}
+
+	rm := newMonitor(true)
+	if got := rm.cpuCores(); got != 0.5 {
+		t.Fatalf("启用 RespectCgroups 后 cpuCores = %v, want 0.5", got)
+	}
+	rm.AdjustCPUUsage(0)
+	load := rm.Snapshot().CPULoad
+	rm.CleanupAllResources()
+	if load != 0.5 {
+		t.Fatalf("CPU 目标 100%% 的负载 = %.2f 核, want 0.5", load)
+	}
+
+	if got, want := newMonitor(false).cpuCores(), float64(runtime.NumCPU()); got != want {
+		t.Fatalf("未启用 RespectCgroups 时 cpuCores = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package occupy
+
+// cgroupRoot 当前平台没有 cgroup
+const cgroupRoot = ""
+
+// cgroupCPUQuota 当前平台不支持 cgroup，始终视为未设置配额
+func cgroupCPUQuota(root string) (float64, error) {
+	return 0, nil
+}
+
+// cgroupMemoryLimit 当前平台不支持 cgroup，始终视为未设置限制
+func cgroupMemoryLimit(root string) (limit, usage uint64, err error) {
+	return 0, 0, nil
+}
@@ -0,0 +1,42 @@
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// enterCgroup 设置了 CgroupPath 时将本进程移入该 cgroup
+func (rm *ResourceMonitor) enterCgroup() error {
+	if rm.Config.CgroupPath == "" {
+		return nil
+	}
+	if err := joinCgroup(rm.Config.CgroupPath); err != nil {
+		return err
+	}
+	logInfof("已加入 cgroup %s，CPU和内存占用将计入该 cgroup", rm.cgroupDir())
+	return nil
+}
+
+// cgroupDir 读取 cgroup 配额的目录：设置了 CgroupPath 时为该 cgroup，否则为 cgroupRoot
+func (rm *ResourceMonitor) cgroupDir() string {
+	if rm.Config.CgroupPath != "" {
+		return cgroupPath(rm.Config.CgroupPath)
+	}
+	return cgroupRoot
+}
+
+// ValidateCgroupPath 验证 CgroupPath 是存在的 cgroup 目录（包含 cgroup.procs），仅Linux支持
+func ValidateCgroupPath(path string) error {
+	if path == "" {
+		return nil
+	}
+	if cgroupRoot == "" {
+		return fmt.Errorf("当前平台不支持加入 cgroup（仅Linux）")
+	}
+	dir := cgroupPath(path)
+	if _, err := os.Stat(filepath.Join(dir, "cgroup.procs")); err != nil {
+		return fmt.Errorf("%s 不是 cgroup 目录: %v", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+//go:build linux
+
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// cgroupPath 将 CgroupPath 解析为 cgroup 目录，相对路径相对于 cgroupRoot
+func cgroupPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cgroupRoot, path)
+}
+
+// joinCgroup 将本进程（包括所有工作线程）移入 cgroup：向其 cgroup.procs 写入进程号，
+// 之后的CPU时间和新分配的内存计入该 cgroup。移动的是整个进程而不是单个工作线程：
+// 协程会在线程间迁移，cgroup v2 的内存控制器也不支持线程级（threaded）cgroup，
+// 按线程写入 tasks/cgroup.threads 无法使内存计入目标 cgroup
+func joinCgroup(path string) error {
+	if err := ValidateCgroupPath(path); err != nil {
+		return err
+	}
+	dir := cgroupPath(path)
+	procs := filepath.Join(dir, "cgroup.procs")
+
+	err := os.WriteFile(procs, []byte(strconv.Itoa(os.Getpid())), 0644)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("没有权限加入 cgroup %s，需要 root 或对 %s 及当前 cgroup 的写权限: %v", dir, procs, err)
+	case errors.Is(err, syscall.EBUSY):
+		// cgroup v2 中启用了子树控制器的非叶子 cgroup 不能直接包含进程
+		return fmt.Errorf("无法加入 cgroup %s（cgroup v2 只能加入叶子 cgroup）: %v", dir, err)
+	default:
+		return fmt.Errorf("加入 cgroup %s 失败: %v", dir, err)
+	}
+}
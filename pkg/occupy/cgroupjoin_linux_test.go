@@ -0,0 +1,91 @@
+//go:build linux
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// cgroupJoinChildEnv 设置为要加入的 cgroup 时在子进程中执行加入测试，避免把整个测试进程移入测试 cgroup
+const cgroupJoinChildEnv = "GO_OCCUPY_CGROUP_CHILD"
+
+// testCgroupParents 依次尝试创建测试 cgroup 的父目录：cgroup v2 的根，以及 cgroup v1 的 pids 层级
+var testCgroupParents = []string{"", "unified", "pids"}
+
+func TestJoinCgroup(t *testing.T) {
+	if path := os.Getenv(cgroupJoinChildEnv); path != "" {
+		runJoinCgroupChild(t, path)
+		return
+	}
+
+	name := fmt.Sprintf("go-occupy-test-%d", os.Getpid())
+	var path string
+	var errs []string
+	for _, parent := range testCgroupParents {
+		if _, err := os.Stat(filepath.Join(cgroupRoot, parent, "cgroup.procs")); err != nil {
+			continue
+		}
+		candidate := filepath.Join(parent, name)
+		if err := os.Mkdir(cgroupPath(candidate), 0755); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		path = candidate
+		break
+	}
+	if path == "" {
+		t.Skipf("无法创建测试 cgroup（需要 root）: %v", errs)
+	}
+	// 子进程退出后 cgroup 中没有进程，才能删除
+	defer os.Remove(cgroupPath(path))
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestJoinCgroup$", "-test.v")
+	cmd.Env = append(os.Environ(), cgroupJoinChildEnv+"="+path)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("子进程失败: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "--- SKIP") {
+		t.Skipf("子进程跳过:\n%s", out)
+	}
+	if !strings.Contains(string(out), "--- PASS") {
+		t.Fatalf("子进程未通过:\n%s", out)
+	}
+}
+
+// runJoinCgroupChild 在子进程中加入 cgroup path，并检查 /proc/self/cgroup 中的成员关系
+func runJoinCgroupChild(t *testing.T, path string) {
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{CgroupPath: path, Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+
+	if err := rm.enterCgroup(); err != nil {
+		// 没有权限时应给出说明原因的错误，而不是崩溃
+		if !strings.Contains(err.Error(), cgroupPath(path)) {
+			t.Fatalf("加入 cgroup 失败的错误未指出 cgroup: %v", err)
+		}
+		t.Skipf("无法加入测试 cgroup: %v", err)
+	}
+	if !strings.Contains(buf.String(), "已加入 cgroup "+cgroupPath(path)) {
+		t.Errorf("加入 cgroup 后未输出日志:\n%s", buf)
+	}
+	membership, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(membership), "/"+filepath.Base(path)+"\n") {
+		t.Fatalf("加入后 /proc/self/cgroup 中没有 %s:\n%s", path, membership)
+	}
+}
+
+func TestJoinCgroupRejectsNonCgroupDir(t *testing.T) {
+	dir := t.TempDir()
+	err := joinCgroup(dir)
+	if err == nil || !strings.Contains(err.Error(), "不是 cgroup 目录") {
+		t.Fatalf("joinCgroup(%s) = %v, want 不是 cgroup 目录", dir, err)
+	}
+}
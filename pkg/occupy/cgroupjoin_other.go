@@ -0,0 +1,17 @@
+//go:build !linux
+
+package occupy
+
+import (
+	"errors"
+)
+
+// cgroupPath 当前平台没有 cgroup
+func cgroupPath(path string) string {
+	return path
+}
+
+// joinCgroup 当前平台不支持 cgroup
+func joinCgroup(path string) error {
+	return errors.New("当前平台不支持加入 cgroup（仅Linux）")
+}
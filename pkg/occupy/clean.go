@@ -0,0 +1,167 @@
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tempFilePattern 获取目录中指定前缀临时文件的粗略匹配模式，匹配结果需再经 isTempFileName 精确判断
+func tempFilePattern(dir, prefix string) string {
+	return filepath.Join(dir, prefix+"[0-9]*.dat")
+}
+
+// memoryFileKinds 内存文件名中的类型标识，见 newFileAllocator 的调用方
+var memoryFileKinds = map[string]bool{
+	"mem": true,
+	"shm": true,
+}
+
+// isTempFileName 判断文件名是否为前缀 prefix 的实例创建的临时文件：前缀之后必须恰好是
+// 磁盘临时文件的 <纳秒时间>_<序号>.dat 或内存文件的 <pid>_<类型>_<序号>.dat。
+// 配合 ValidateFilePrefix 要求前缀不以数字结尾，前缀为 a_ 的清理不会匹配前缀为 a_9x_、a_1_ 等实例的文件
+func isTempFileName(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix)
+	if !ok {
+		return false
+	}
+	rest, ok = strings.CutSuffix(rest, ".dat")
+	if !ok {
+		return false
+	}
+	parts := strings.Split(rest, "_")
+	switch len(parts) {
+	case 2:
+		return isDigits(parts[0]) && isDigits(parts[1])
+	case 3:
+		return isDigits(parts[0]) && memoryFileKinds[parts[1]] && isDigits(parts[2])
+	default:
+		return false
+	}
+}
+
+// isTempSubdirName 判断目录名是否为前缀 prefix 的实例创建的临时子目录（<前缀>dir_<纳秒时间>）
+func isTempSubdirName(name, prefix string) bool {
+	rest, ok := strings.CutPrefix(name, prefix+"dir_")
+	return ok && isDigits(rest)
+}
+
+// isDigits 字符串是否非空且只包含ASCII数字
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// createdByProcess 返回判断临时文件或子目录是否由进程 pid 创建的函数（进程在 since 时刻之后启动）：
+// 内存文件按名称中的PID判断，磁盘临时文件和临时子目录按名称中的纳秒时间不早于 since 判断。
+// 同一目录中使用相同前缀、在 since 之后启动的其他实例的磁盘文件仍会被匹配
+func createdByProcess(prefix string, pid int, since time.Time) func(name string) bool {
+	after := func(nanos string) bool {
+		n, err := strconv.ParseInt(nanos, 10, 64)
+		return err == nil && n >= since.UnixNano()
+	}
+	return func(name string) bool {
+		rest := strings.TrimPrefix(name, prefix)
+		if nanos, ok := strings.CutPrefix(rest, "dir_"); ok {
+			return after(nanos)
+		}
+		parts := strings.Split(strings.TrimSuffix(rest, ".dat"), "_")
+		switch len(parts) {
+		case 2:
+			return after(parts[0])
+		case 3:
+			return parts[0] == strconv.Itoa(pid)
+		default:
+			return false
+		}
+	}
+}
+
+// globTempFiles 查找目录中属于前缀 prefix 的临时文件，owned 不为 nil 时只保留其返回 true 的文件
+func globTempFiles(dir, prefix string, owned func(name string) bool) ([]string, error) {
+	matches, err := filepath.Glob(tempFilePattern(dir, prefix))
+	if err != nil {
+		return nil, err
+	}
+	files := matches[:0]
+	for _, match := range matches {
+		name := filepath.Base(match)
+		if isTempFileName(name, prefix) && (owned == nil || owned(name)) {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// RemoveTempFiles 删除目录及其临时子目录中所有匹配前缀的临时文件，并删除清空后的子目录，
+// 返回删除的文件数和回收的字节数。dir 为空时使用默认临时目录，prefix 为空时使用 DefaultFilePrefix。
+// 单个文件删除失败时记录日志并继续
+func RemoveTempFiles(dir, prefix string) (removed int, reclaimed uint64, err error) {
+	removed, _, reclaimed, err = removeTempFiles(dir, prefix, nil)
+	return removed, reclaimed, err
+}
+
+// removeTempFiles 同 RemoveTempFiles，额外返回删除失败的文件数。
+// owned 不为 nil 时只删除其返回 true 的临时文件和子目录
+func removeTempFiles(dir, prefix string, owned func(name string) bool) (removed, failed int, reclaimed uint64, err error) {
+	if dir == "" {
+		dir = defaultTempDir()
+	}
+	if prefix == "" {
+		prefix = DefaultFilePrefix
+	}
+
+	matches, err := globTempFiles(dir, prefix, owned)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("查找临时文件失败: %v", err)
+	}
+	candidates, err := filepath.Glob(tempSubdirPattern(dir, prefix))
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("查找临时子目录失败: %v", err)
+	}
+	subdirs := candidates[:0]
+	for _, subdir := range candidates {
+		name := filepath.Base(subdir)
+		if isTempSubdirName(name, prefix) && (owned == nil || owned(name)) {
+			subdirs = append(subdirs, subdir)
+		}
+	}
+	for _, subdir := range subdirs {
+		files, err := globTempFiles(subdir, prefix, owned)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("查找临时文件失败: %v", err)
+		}
+		matches = append(matches, files...)
+	}
+
+	for _, file := range matches {
+		var size uint64
+		if info, err := os.Stat(file); err == nil {
+			size = uint64(info.Size())
+		}
+		if err := os.Remove(file); err != nil {
+			logErrorf("删除临时文件失败: %s, %v", file, err)
+			failed++
+			continue
+		}
+		removed++
+		reclaimed += size
+	}
+
+	for _, subdir := range subdirs {
+		if err := os.Remove(subdir); err != nil {
+			logErrorf("删除临时子目录失败: %s, %v", subdir, err)
+		}
+	}
+	return removed, failed, reclaimed, nil
+}
@@ -0,0 +1,107 @@
+package occupy
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+func TestRemoveTempFilesMatchesPrefixExactly(t *testing.T) {
+	dir := t.TempDir()
+	own := []string{
+		"a_1700000000000000000_0.dat",
+		"a_1700000000000000001_12.dat",
+		"a_4242_mem_3.dat",
+		"a_4242_shm_0.dat",
+	}
+	others := []string{
+		"a_9x_123.dat",
+		"a_1_1700000000000000000_0.dat",
+		"a_1_4242_mem_3.dat",
+		"a_123.dat",
+		"a_4242_tmp_3.dat",
+		"b_1700000000000000000_0.dat",
+	}
+	for _, name := range append(append([]string(nil), own...), others...) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"a_dir_1700000000000000000", "a_dir_9x_1"} {
+		if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, reclaimed, err := RemoveTempFiles(dir, "a_")
+	if err != nil {
+		t.Fatalf("RemoveTempFiles: %v", err)
+	}
+	if removed != len(own) || reclaimed != uint64(len(own)) {
+		t.Errorf("removed = %d, reclaimed = %d, want %d", removed, reclaimed, len(own))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, entry := range entries {
+		left = append(left, entry.Name())
+	}
+	want := append([]string{"a_dir_9x_1"}, others...)
+	sort.Strings(want)
+	if len(left) != len(want) {
+		t.Fatalf("剩余文件 = %v, want %v", left, want)
+	}
+	for i := range want {
+		if left[i] != want[i] {
+			t.Fatalf("剩余文件 = %v, want %v", left, want)
+		}
+	}
+}
+
+func TestValidateFilePrefixRejectsTrailingDigit(t *testing.T) {
+	for _, prefix := range []string{"", "a_", "go_occupy_job12_", "run-x"} {
+		if err := ValidateFilePrefix(prefix); err != nil {
+			t.Errorf("ValidateFilePrefix(%q) = %v, want nil", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"a_1", "job7", "a/b_", "a*_"} {
+		if err := ValidateFilePrefix(prefix); err == nil {
+			t.Errorf("ValidateFilePrefix(%q) = nil, want error", prefix)
+		}
+	}
+}
+
+func TestCleanupWithOnePrefixKeepsOtherInstanceFiles(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	newMonitor := func(prefix string) *ResourceMonitor {
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			DiskTargets: []DiskTarget{{Path: dir}},
+			FilePrefix:  prefix,
+			Interval:    MinInterval,
+		}, newFakeMetrics(1024*mb, 1024*mb))
+		if err := rm.createTempFiles(dir, 2*mb); err != nil {
+			t.Fatalf("%s: createTempFiles: %v", prefix, err)
+		}
+		return rm
+	}
+	first := newMonitor("first_")
+	second := newMonitor("second_")
+	defer second.CleanupAllTempFiles()
+
+	first.CleanupAllTempFiles()
+	if left, _ := filepath.Glob(filepath.Join(dir, "first_*")); len(left) != 0 {
+		t.Fatalf("清理后仍有 first_ 的文件: %v", left)
+	}
+	kept, _ := filepath.Glob(filepath.Join(dir, "second_*"))
+	if len(kept) == 0 || len(kept) != len(second.tempFiles[dir]) {
+		t.Fatalf("second_ 的文件 = %v, want 保留全部 %d 个", kept, len(second.tempFiles[dir]))
+	}
+	if got := dirBytes(t, dir); got != 2*mb {
+		t.Fatalf("目录剩余 %d 字节, want %d", got, 2*mb)
+	}
+}
@@ -0,0 +1,76 @@
+package occupy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileConfig 配置文件（JSON）中的资源目标，未设置的字段保持命令行参数或当前目标不变
+type FileConfig struct {
+	MemoryPercent *float64 `json:"memory_percent,omitempty"`
+	CPUPercent    *float64 `json:"cpu_percent,omitempty"`
+	DiskPercent   *float64 `json:"disk_percent,omitempty"`
+}
+
+// LoadConfig 读取并验证配置文件
+func LoadConfig(path string) (FileConfig, error) {
+	var config FileConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+
+	checks := []struct {
+		name  string
+		value *float64
+	}{
+		{"内存", config.MemoryPercent},
+		{"CPU", config.CPUPercent},
+		{"磁盘", config.DiskPercent},
+	}
+	for _, check := range checks {
+		if check.value == nil {
+			continue
+		}
+		if err := validatePercent(check.name, *check.value); err != nil {
+			return config, fmt.Errorf("配置文件 %s 无效: %v", path, err)
+		}
+	}
+	return config, nil
+}
+
+// ReloadConfig 重新读取配置文件并通过 Retarget 应用其中的目标，下一次调整时生效。
+// 配置文件无效时返回错误，当前目标保持不变
+func (rm *ResourceMonitor) ReloadConfig(path string) error {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+
+	t := rm.configuredTargets()
+	if config.MemoryPercent != nil {
+		t.MemoryPercent = *config.MemoryPercent
+	}
+	if config.CPUPercent != nil {
+		t.CPUPercent = *config.CPUPercent
+	}
+	t.DiskPercents = nil
+	if config.DiskPercent != nil {
+		// 配置了多个磁盘目标时数量不一致，由 Retarget 拒绝
+		t.DiskPercents = []float64{*config.DiskPercent}
+	}
+
+	if err := rm.Retarget(t); err != nil {
+		return fmt.Errorf("应用配置文件 %s 失败: %v", path, err)
+	}
+	logInfof("已重新加载配置文件: %s", path)
+	return nil
+}
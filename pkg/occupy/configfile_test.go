@@ -0,0 +1,43 @@
+package occupy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReloadConfigAppliesValidAndIgnoresInvalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	writeConfig := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent: 30,
+		CPUPercent:    20,
+		DiskTargets:   []DiskTarget{{Path: dir, Percent: 10}},
+		Interval:      MinInterval,
+	})
+
+	writeConfig(`{"memory_percent": 45, "disk_percent": 15}`)
+	if err := rm.ReloadConfig(path); err != nil {
+		t.Fatalf("ReloadConfig: %v", err)
+	}
+	got := rm.CurrentTargets()
+	if got.MemoryPercent != 45 || got.CPUPercent != 20 || len(got.DiskPercents) != 1 || got.DiskPercents[0] != 15 {
+		t.Fatalf("重新加载后目标 = %+v, want 内存 45, CPU 20, 磁盘 [15]", got)
+	}
+
+	for _, content := range []string{`{"memory_percent": 150}`, `{"memory":`, `{"unknown": 1}`} {
+		writeConfig(content)
+		if err := rm.ReloadConfig(path); err == nil {
+			t.Errorf("ReloadConfig(%s) = nil, want 错误", content)
+		}
+		if after := rm.CurrentTargets(); after.MemoryPercent != 45 || after.DiskPercents[0] != 15 {
+			t.Errorf("无效配置 %s 改变了目标: %+v", content, after)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package occupy
+
+import "math"
+
+// DefaultControlGain 默认控制增益，1 表示每次调整直接补齐全部差值
+const DefaultControlGain = 1.0
+
+// cpuLoadSnapThreshold CPU负载与期望值相差小于该值（核心数）时直接取期望值，避免无限逼近
+const cpuLoadSnapThreshold = 0.05
+
+// controlGain 获取比例控制增益 Kp
+func (rm *ResourceMonitor) controlGain() float64 {
+	if rm.Config.ControlGain > 0 && rm.Config.ControlGain < 1 {
+		return rm.Config.ControlGain
+	}
+	return DefaultControlGain
+}
+
+// scaleByGain 按控制增益缩放本次调整的字节数，经过多次调整逐步收敛到目标
+func (rm *ResourceMonitor) scaleByGain(bytes uint64) uint64 {
+	gain := rm.controlGain()
+	if gain >= 1 {
+		return bytes
+	}
+	return uint64(float64(bytes) * gain)
+}
+
+// stepCPULoad 按控制增益将CPU负载（核心数）向期望值移动
+func (rm *ResourceMonitor) stepCPULoad(desired float64) {
+	gain := rm.controlGain()
+	if gain < 1 {
+		rm.cpuLoadMutex.Lock()
+		current := rm.targetCPULoad
+		rm.cpuLoadMutex.Unlock()
+
+		next := current + gain*(desired-current)
+		if math.Abs(desired-next) >= cpuLoadSnapThreshold {
+			desired = next
+		}
+	}
+	rm.adjustCPULoad(desired)
+}
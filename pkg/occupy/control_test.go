@@ -0,0 +1,54 @@
+package occupy
+
+import (
+	"math"
+	"runtime"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestControlGainMemoryStep(t *testing.T) {
+	const mb = 1024 * 1024
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Available: 100 * mb}
+	for _, c := range []struct {
+		gain float64
+		want uint64
+	}{
+		{0.25, 5 * mb},
+		{0.5, 10 * mb},
+		{1, 20 * mb},
+	} {
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			MemoryPercent: 30,
+			ControlGain:   c.gain,
+		}, newFakeMetrics(100*mb, 1<<40))
+		rm.AdjustMemoryUsage(10, memInfo)
+		got := rm.AllocatedBytes()
+		rm.CleanupAllResources()
+		if got != c.want {
+			t.Errorf("增益 %.2f: 差额 20MB 时分配 %d 字节, want %d", c.gain, got, c.want)
+		}
+	}
+}
+
+func TestControlGainCPUStep(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		CPUPercent:  100,
+		ControlGain: 0.5,
+	}, newFakeMetrics(1<<30, 1<<40))
+	t.Cleanup(rm.CleanupAllResources)
+
+	// 期望负载为全部核心，每次移动剩余差距的一半
+	cores := float64(runtime.NumCPU())
+	for i, frac := range []float64{0.5, 0.75, 0.875} {
+		want := cores * frac
+		rm.AdjustCPUUsage(0)
+		rm.cpuLoadMutex.Lock()
+		got := rm.currentCPULoad
+		rm.cpuLoadMutex.Unlock()
+		if math.Abs(got-want) > 1e-9 {
+			t.Fatalf("第 %d 次调整后负载 = %.2f 核, want %.2f", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package occupy
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// unmetTargets 根据最近一次测量返回目标大于0但不在容忍范围内的资源说明，
+// 尚未完成测量和调整时返回一条说明
+func (rm *ResourceMonitor) unmetTargets() []string {
+	rm.measurementMutex.Lock()
+	m := rm.lastMeasurement
+	t := rm.lastTargets
+	rm.measurementMutex.Unlock()
+
+	if t == nil || m.Time.IsZero() || len(m.DiskPercents) != len(t.DiskPercents) {
+		return []string{"尚未完成测量"}
+	}
+
+	var unmet []string
+	check := func(name string, current, target, tolerance float64) {
+		if target > 0 && math.Abs(current-target) > tolerance {
+			unmet = append(unmet, fmt.Sprintf("%s (当前 %.1f%%, 目标 %.1f%%)", name, current, target))
+		}
+	}
+	check("内存", m.MemoryPercent, t.MemoryPercent, rm.memoryTolerance())
+	check("CPU", m.CPUPercent, t.CPUPercent, rm.cpuTolerance())
+	if rm.Config.DiskMeasureOnly {
+		return unmet
+	}
+	targets := rm.diskTargets()
+	for i, percent := range m.DiskPercents {
+		name := "磁盘"
+		if len(targets) > 1 && i < len(targets) {
+			name = "磁盘 " + targets[i].Path
+		}
+		check(name, percent, t.DiskPercents[i], rm.diskTolerance())
+	}
+	return unmet
+}
+
+// checkConvergence 到达 ConvergeDeadline 时检查各资源是否已达到目标，未达到时以错误停止监控
+func (rm *ResourceMonitor) checkConvergence() {
+	unmet := rm.unmetTargets()
+	if len(unmet) == 0 {
+		logInfof("已在 %v 内达到目标", rm.Config.ConvergeDeadline)
+		return
+	}
+
+	rm.Abort(fmt.Errorf("%w (%v): %s", ErrConvergeDeadline, rm.Config.ConvergeDeadline, strings.Join(unmet, ", ")))
+}
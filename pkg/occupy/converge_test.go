@@ -0,0 +1,34 @@
+package occupy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvergeDeadlineFailsOnImpossibleTarget(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	// 指标不随分配变化且分配量受限，内存使用率始终停留在 10%
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:    99,
+		MaxMemoryBytes:   4 * mb,
+		Interval:         MinInterval,
+		ConvergeDeadline: 1500 * time.Millisecond,
+	}, newFakeMetrics(100*mb, 100*mb))
+
+	done := rm.Done()
+	go rm.Start()
+	waitDone(t, done, 10*time.Second)
+
+	err := rm.Err()
+	if err == nil || !strings.Contains(err.Error(), "未能在") {
+		t.Fatalf("Err = %v, want 未能在期限内达到目标", err)
+	}
+	if !strings.Contains(err.Error(), "内存") {
+		t.Errorf("错误 %q 未指出未达到目标的资源", err)
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("退出后 AllocatedBytes = %d, want 0", got)
+	}
+}
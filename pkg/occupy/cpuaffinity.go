@@ -0,0 +1,117 @@
+package occupy
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseCPUList 解析核心编号列表，如 "0,1" 或 "0,2-3"，返回去重排序后的编号
+func ParseCPUList(value string) ([]int, error) {
+	seen := make(map[int]bool)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		first, last, isRange := strings.Cut(item, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 0 {
+			return nil, fmt.Errorf("无效的核心编号 %q", item)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("无效的核心编号范围 %q", item)
+			}
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			seen[cpu] = true
+		}
+	}
+
+	cpus := make([]int, 0, len(seen))
+	for cpu := range seen {
+		cpus = append(cpus, cpu)
+	}
+	sort.Ints(cpus)
+	return cpus, nil
+}
+
+// ValidateCPUExclude 验证排除的核心：仅Linux支持，编号必须在 0 到 NumCPU-1 之间，且至少保留一个可用核心
+func ValidateCPUExclude(exclude []int) error {
+	if len(exclude) == 0 {
+		return nil
+	}
+	if !cpuAffinitySupported {
+		return fmt.Errorf("当前平台不支持排除CPU核心（仅Linux）")
+	}
+	for _, cpu := range exclude {
+		if cpu < 0 || cpu >= runtime.NumCPU() {
+			return fmt.Errorf("排除的核心 %d 超出范围 (0-%d)", cpu, runtime.NumCPU()-1)
+		}
+	}
+	cpus, err := allowedCPUs(exclude)
+	if err != nil {
+		return err
+	}
+	if len(cpus) == 0 {
+		return fmt.Errorf("排除核心 %v 后没有可用于CPU负载的核心", exclude)
+	}
+	return nil
+}
+
+// allowedCPUs 本进程允许使用的核心中去掉 exclude 后剩余的核心
+func allowedCPUs(exclude []int) ([]int, error) {
+	cpus, err := processCPUs()
+	if err != nil {
+		return nil, fmt.Errorf("获取进程可用核心失败: %v", err)
+	}
+	excluded := make(map[int]bool, len(exclude))
+	for _, cpu := range exclude {
+		excluded[cpu] = true
+	}
+	allowed := cpus[:0]
+	for _, cpu := range cpus {
+		if !excluded[cpu] {
+			allowed = append(allowed, cpu)
+		}
+	}
+	return allowed, nil
+}
+
+// workerCPUs 设置 CPUExclude 时CPU工作线程依次绑定的核心，未设置或获取失败时返回 nil 表示不绑定
+func (rm *ResourceMonitor) workerCPUs() []int {
+	if len(rm.Config.CPUExclude) == 0 {
+		return nil
+	}
+	cpus, err := allowedCPUs(rm.Config.CPUExclude)
+	if err != nil || len(cpus) == 0 {
+		logErrorf("无法排除核心 %v，CPU工作线程不绑定核心: %v", rm.Config.CPUExclude, err)
+		return nil
+	}
+	return cpus
+}
+
+// workerCPU 第 i 个CPU工作线程绑定的核心，按可用核心轮流分配；-1 表示不绑定
+func workerCPU(cpus []int, i int) int {
+	if len(cpus) == 0 {
+		return -1
+	}
+	return cpus[i%len(cpus)]
+}
+
+// pinCPUWorker 将当前协程锁定到系统线程并绑定到核心 cpu，cpu 为 -1 时不绑定。
+// 绑定后协程退出时不解除锁定，使该线程随协程一起退出，不会把绑定带给其他协程
+func pinCPUWorker(cpu int) {
+	if cpu < 0 {
+		return
+	}
+	runtime.LockOSThread()
+	if err := setThreadCPU(cpu); err != nil {
+		logErrorf("CPU工作线程绑定核心 %d 失败: %v", cpu, err)
+	}
+}
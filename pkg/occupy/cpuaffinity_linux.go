@@ -0,0 +1,47 @@
+//go:build linux
+
+package occupy
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// cpuAffinitySupported 当前平台是否支持将工作线程绑定到核心
+const cpuAffinitySupported = true
+
+// cpuSet 与内核 cpu_set_t 布局相同的核心掩码，最多 1024 个核心
+type cpuSet [1024 / 64]uint64
+
+// processCPUs 主线程允许运行的核心，即本进程启动时的CPU亲和性（工作线程绑定核心不会改变它）
+func processCPUs() ([]int, error) {
+	var set cpuSet
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY,
+		uintptr(os.Getpid()), unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+	if errno != 0 {
+		return nil, errno
+	}
+
+	var cpus []int
+	for i, word := range set {
+		for bit := 0; bit < 64; bit++ {
+			if word&(1<<uint(bit)) != 0 {
+				cpus = append(cpus, i*64+bit)
+			}
+		}
+	}
+	return cpus, nil
+}
+
+// setThreadCPU 将当前线程绑定到核心 cpu（调用方需已 LockOSThread）
+func setThreadCPU(cpu int) error {
+	var set cpuSet
+	set[cpu/64] |= 1 << uint(cpu%64)
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY,
+		0, unsafe.Sizeof(set), uintptr(unsafe.Pointer(&set)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
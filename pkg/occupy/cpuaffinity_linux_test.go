@@ -0,0 +1,102 @@
+//go:build linux
+
+package occupy
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pinnedCores 读取本进程各线程的 Cpus_allowed_list，返回只允许运行在单个核心上的线程所绑定的核心及线程数
+// （进程可以使用多个核心时，只有绑定了核心的工作线程只允许运行在单个核心上）
+func pinnedCores(t *testing.T) map[int]int {
+	t.Helper()
+	statuses, err := filepath.Glob("/proc/self/task/*/status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinned := make(map[int]int)
+	for _, path := range statuses {
+		file, err := os.Open(path)
+		if err != nil {
+			// 线程已退出
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			value, ok := strings.CutPrefix(scanner.Text(), "Cpus_allowed_list:")
+			if !ok {
+				continue
+			}
+			cpus, err := ParseCPUList(value)
+			if err != nil {
+				t.Fatalf("解析 %s 失败: %v", path, err)
+			}
+			if len(cpus) == 1 {
+				pinned[cpus[0]]++
+			}
+		}
+		file.Close()
+	}
+	return pinned
+}
+
+func TestCPUExcludeWorkersAvoidExcludedCore(t *testing.T) {
+	cpus, err := processCPUs()
+	if err != nil {
+		t.Fatalf("processCPUs: %v", err)
+	}
+	if len(cpus) < 2 {
+		t.Skipf("进程只能使用 %d 个核心，无法排除核心后仍有可用核心", len(cpus))
+	}
+	excluded := cpus[0]
+	allowed := cpus[1:]
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		CPUPercent: 100,
+		CPUCount:   len(allowed),
+		CPUExclude: []int{excluded},
+		Interval:   MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+	defer rm.stopCPULoad()
+
+	rm.adjustCPUWorkers(len(allowed))
+	var pinned map[int]int
+	waitFor(t, 5*time.Second, "工作线程绑定到所有未排除的核心", func() bool {
+		pinned = pinnedCores(t)
+		return len(pinned) == len(allowed)
+	})
+	if pinned[excluded] != 0 {
+		t.Errorf("%d 个工作线程运行在排除的核心 %d 上", pinned[excluded], excluded)
+	}
+	for _, cpu := range allowed {
+		if pinned[cpu] == 0 {
+			t.Errorf("核心 %d 上没有工作线程", cpu)
+		}
+	}
+}
+
+func TestValidateCPUExclude(t *testing.T) {
+	cpus, err := processCPUs()
+	if err != nil {
+		t.Fatalf("processCPUs: %v", err)
+	}
+	if err := ValidateCPUExclude(nil); err != nil {
+		t.Errorf("未排除核心时 ValidateCPUExclude = %v, want nil", err)
+	}
+	if err := ValidateCPUExclude([]int{runtime.NumCPU()}); err == nil {
+		t.Errorf("排除超出范围的核心 %d 时未返回错误", runtime.NumCPU())
+	}
+	if err := ValidateCPUExclude(cpus); err == nil {
+		t.Errorf("排除全部核心 %v 时未返回错误", cpus)
+	}
+	if len(cpus) > 1 {
+		if err := ValidateCPUExclude(cpus[:1]); err != nil {
+			t.Errorf("排除核心 %v 时 ValidateCPUExclude = %v, want nil", cpus[:1], err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package occupy
+
+import (
+	"errors"
+)
+
+// cpuAffinitySupported 当前平台是否支持将工作线程绑定到核心
+const cpuAffinitySupported = false
+
+// processCPUs 非Linux平台不支持
+func processCPUs() ([]int, error) {
+	return nil, errors.New("仅Linux支持")
+}
+
+// setThreadCPU 非Linux平台不支持
+func setThreadCPU(cpu int) error {
+	return errors.New("仅Linux支持")
+}
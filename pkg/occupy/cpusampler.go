@@ -0,0 +1,103 @@
+package occupy
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultCPUSmoothing 默认CPU使用率指数加权移动平均的平滑系数
+const DefaultCPUSmoothing = 0.3
+
+// cpuSampleInterval 后台CPU采样间隔
+const cpuSampleInterval = 500 * time.Millisecond
+
+// ewma 指数加权移动平均
+type ewma struct {
+	alpha  float64
+	value  float64
+	primed bool
+}
+
+// update 加入新的采样值并返回新的平均值，首个采样值直接作为平均值
+func (e *ewma) update(sample float64) float64 {
+	if !e.primed {
+		e.value = sample
+		e.primed = true
+		return e.value
+	}
+	e.value = e.alpha*sample + (1-e.alpha)*e.value
+	return e.value
+}
+
+// cpuSampler 在后台以固定间隔采样CPU使用率，避免按监控间隔采样时的平均误差
+type cpuSampler struct {
+	mu      sync.Mutex
+	average ewma
+}
+
+// reset 使用指定的平滑系数重新开始采样
+func (s *cpuSampler) reset(alpha float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.average = ewma{alpha: alpha}
+}
+
+// value 获取平滑后的CPU使用率，尚未采样时返回 false
+func (s *cpuSampler) value() (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.average.value, s.average.primed
+}
+
+// add 加入一个采样值
+func (s *cpuSampler) add(sample float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.average.update(sample)
+}
+
+// cpuSmoothing 获取平滑系数
+func (rm *ResourceMonitor) cpuSmoothing() float64 {
+	if rm.Config.CPUSmoothing > 0 {
+		return rm.Config.CPUSmoothing
+	}
+	return DefaultCPUSmoothing
+}
+
+// runCPUSampler 后台采样CPU使用率，直到停止监控或 ctx 结束
+func (rm *ResourceMonitor) runCPUSampler(ctx context.Context) {
+	defer rm.cpuSamplerWg.Done()
+	rm.cpuSampler.reset(rm.cpuSmoothing())
+
+	ticker := time.NewTicker(cpuSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			percent, err := rm.metricsProvider().CPU()
+			if err != nil {
+				logDebugf("后台采样CPU失败: %v", err)
+				continue
+			}
+			rm.cpuSampler.add(percent)
+		case <-rm.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// readCPUPercent 获取当前CPU使用率，后台采样已有数据时使用平滑后的值
+func (rm *ResourceMonitor) readCPUPercent() (float64, error) {
+	if percent, ok := rm.cpuSampler.value(); ok {
+		return percent, nil
+	}
+
+	return rm.metricsProvider().CPU()
+}
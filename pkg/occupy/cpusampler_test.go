@@ -0,0 +1,46 @@
+package occupy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEWMAFollowsReadings(t *testing.T) {
+	e := ewma{alpha: 0.5}
+	for i, c := range []struct {
+		sample, want float64
+	}{
+		{40, 40},
+		{80, 60},
+		{80, 70},
+		{0, 35},
+		{0, 17.5},
+	} {
+		if got := e.update(c.sample); math.Abs(got-c.want) > 1e-9 {
+			t.Fatalf("第 %d 个采样 %.1f 后平均值 = %.4f, want %.4f", i+1, c.sample, got, c.want)
+		}
+	}
+}
+
+func TestCPUSamplerFeedsAdjustment(t *testing.T) {
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	rm := NewResourceMonitor(ResourceConfig{CPUSmoothing: 0.25})
+	t.Cleanup(rm.CleanupAllResources)
+
+	if got, err := rm.readCPUPercent(); err != nil || got < 0 || got > 100 {
+		t.Fatalf("尚未采样时 readCPUPercent = %.1f, %v, want 直接读取的使用率", got, err)
+	}
+
+	rm.cpuSampler.reset(rm.cpuSmoothing())
+	for _, sample := range []float64{20, 100, 100, 100} {
+		rm.cpuSampler.add(sample)
+	}
+	// 20 -> 40 -> 55 -> 66.25
+	if got, err := rm.readCPUPercent(); err != nil || math.Abs(got-66.25) > 1e-9 {
+		t.Fatalf("readCPUPercent = %.4f, %v, want 66.25", got, err)
+	}
+	rm.monitorAndAdjust()
+	if got := rm.LastMeasurement().CPUPercent; math.Abs(got-66.25) > 1e-9 {
+		t.Fatalf("调整时测量的CPU使用率 = %.4f, want 66.25", got)
+	}
+}
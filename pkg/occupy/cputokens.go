@@ -0,0 +1,132 @@
+package occupy
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// CPU负载的控制方式
+const (
+	// CPUControlDuty 满载工作线程加一个占空比工作线程（默认）
+	CPUControlDuty = "duty"
+	// CPUControlTokens 所有工作线程从共享的令牌桶中获取CPU时间，令牌补充速率即总负载
+	CPUControlTokens = "tokens"
+)
+
+const (
+	// cpuTokenSlice 工作线程每次获取并消耗的CPU时间
+	cpuTokenSlice = 10 * time.Millisecond
+	// cpuTokenBurst 令牌桶最多积累的时长，限制空闲后的突发负载
+	cpuTokenBurst = 100 * time.Millisecond
+	// cpuTokenIdle 补充速率为0时工作线程的等待时间
+	cpuTokenIdle = 100 * time.Millisecond
+)
+
+// cpuTokenBucket 以CPU时间为令牌的令牌桶，补充速率为每秒可消耗的CPU秒数（即核心数）
+type cpuTokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	tokens float64 // 当前可用的CPU时间（秒）
+	last   time.Time
+}
+
+// setRate 设置令牌补充速率（核心数）
+func (b *cpuTokenBucket) setRate(rate float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	b.rate = rate
+}
+
+// refill 按经过的时间补充令牌（调用方需持有 mu）
+func (b *cpuTokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens += b.rate * now.Sub(b.last).Seconds()
+	}
+	b.last = now
+
+	if capacity := math.Max(b.rate*cpuTokenBurst.Seconds(), cpuTokenSlice.Seconds()); b.tokens > capacity {
+		b.tokens = capacity
+	}
+}
+
+// take 尝试取出 slice 的CPU时间，令牌不足时返回需要等待的时间
+func (b *cpuTokenBucket) take(slice time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	need := slice.Seconds()
+	if b.tokens >= need {
+		b.tokens -= need
+		return 0
+	}
+	if b.rate <= 0 {
+		return cpuTokenIdle
+	}
+	return time.Duration((need - b.tokens) / b.rate * float64(time.Second))
+}
+
+// tokenControl 是否使用令牌桶控制CPU负载
+func (rm *ResourceMonitor) tokenControl() bool {
+	return rm.Config.CPUControl == CPUControlTokens
+}
+
+// cpuTokenWorker 令牌桶控制下的CPU工作协程，每取得一份令牌按 kind 类型计算 cpuTokenSlice；cpu 不为 -1 时绑定到该核心
+func (rm *ResourceMonitor) cpuTokenWorker(kind string, cpu int, bucket *cpuTokenBucket, stop chan bool) {
+	defer rm.cpuLoadWg.Done()
+	pinCPUWorker(cpu)
+
+	work := newCPUWorkload(kind)
+	for {
+		if wait := bucket.take(cpuTokenSlice); wait > 0 {
+			select {
+			case <-stop:
+				return
+			case <-time.After(wait):
+			}
+			continue
+		}
+
+		start := time.Now()
+		for time.Since(start) < cpuTokenSlice {
+			work()
+		}
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+// adjustCPUTokens 根据当前与目标CPU使用率的差值调整令牌补充速率：
+// 首次按目标设置，之后每次按控制增益累加差值，直到使用率进入容忍范围
+func (rm *ResourceMonitor) adjustCPUTokens(currentPercent, targetPercent float64) {
+	cores := rm.cpuCores()
+	if targetPercent <= 0 {
+		rm.adjustCPULoad(0)
+		return
+	}
+
+	rm.cpuLoadMutex.Lock()
+	rate := rm.targetCPULoad
+	active := rm.ActiveCPULoad
+	rm.cpuLoadMutex.Unlock()
+
+	if active && math.Abs(currentPercent-targetPercent) <= rm.cpuTolerance() {
+		return
+	}
+
+	if !active || rate <= 0 {
+		rate = targetPercent / 100.0 * cores
+	} else {
+		rate += rm.controlGain() * (targetPercent - currentPercent) / 100.0 * cores
+	}
+	rate = math.Max(0, math.Min(rate, cores))
+	logDebugf("令牌桶速率: %.2f 核 (当前 %.1f%%, 目标 %.1f%%)", rate, currentPercent, targetPercent)
+	rm.adjustCPULoad(rate)
+}
@@ -0,0 +1,43 @@
+package occupy
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+// bucketRate 读取令牌桶当前的补充速率
+func bucketRate(rm *ResourceMonitor) float64 {
+	rm.cpuTokens.mu.Lock()
+	defer rm.cpuTokens.mu.Unlock()
+	return rm.cpuTokens.rate
+}
+
+func TestCPUTokenRateFollowsReadings(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		CPUPercent:  50,
+		CPUControl:  CPUControlTokens,
+		ControlGain: 0.5,
+		Interval:    MinInterval,
+	})
+	defer rm.stopCPULoad()
+
+	// 速率以核心数为单位，want 为每个核心的份额
+	cores := float64(runtime.NumCPU())
+	steps := []struct {
+		reading float64
+		want    float64
+	}{
+		{10, 0.5},  // 首次按目标设置: 50%
+		{30, 0.6},  // 低于目标 20%: 0.5 + 0.5 × 0.2
+		{48, 0.6},  // 在容忍范围内保持不变
+		{80, 0.45}, // 高于目标 30%: 0.6 - 0.5 × 0.3
+		{100, 0.2}, // 高于目标 50%: 0.45 - 0.5 × 0.5
+	}
+	for _, step := range steps {
+		rm.AdjustCPUUsage(step.reading)
+		if got := bucketRate(rm); math.Abs(got-step.want*cores) > 1e-9 {
+			t.Fatalf("读数 %.0f%% 后令牌桶速率 = %.2f, want %.2f", step.reading, got, step.want*cores)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package occupy
+
+import (
+	"fmt"
+)
+
+// logDecision 以 debug 级别输出控制循环的一次决策：当前值与目标的偏差和采取的动作，用于调试控制参数
+func logDecision(resource string, currentPercent, targetPercent float64, format string, args ...interface{}) {
+	logDebugf("决策 %s: 当前 %.1f%%, 目标 %.1f%%, 偏差 %+.1f%% → %s",
+		resource, currentPercent, targetPercent, currentPercent-targetPercent, fmt.Sprintf(format, args...))
+}
+
+// cpuLoad 当前的目标CPU负载（核心数）
+func (rm *ResourceMonitor) cpuLoad() float64 {
+	rm.cpuLoadMutex.Lock()
+	defer rm.cpuLoadMutex.Unlock()
+
+	return rm.targetCPULoad
+}
+
+// decideCPULoad 按控制增益向 desired 调整CPU负载，并输出调整前后的负载
+func (rm *ResourceMonitor) decideCPULoad(currentPercent, targetPercent, desired float64) {
+	before := rm.cpuLoad()
+	rm.stepCPULoad(desired)
+	after := rm.cpuLoad()
+
+	if after == before {
+		logDecision("CPU", currentPercent, targetPercent, "负载保持 %.2f 核", after)
+		return
+	}
+	direction := "增加"
+	if after < before {
+		direction = "减少"
+	}
+	logDecision("CPU", currentPercent, targetPercent, "%s负载 %.2f → %.2f 核", direction, before, after)
+}
@@ -0,0 +1,60 @@
+package occupy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestDecisionLinesShowDeltaAndAction(t *testing.T) {
+	const mb = 1024 * 1024
+	buf := captureLog(t, LogDebug)
+	dir := t.TempDir()
+	target := DiskTarget{Path: dir, Percent: 20}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 50,
+		CPUPercent:    50,
+		CPUCount:      4,
+		DiskTargets:   []DiskTarget{target},
+		Interval:      MinInterval,
+	}, newFakeMetrics(100*mb, 100*mb))
+	defer rm.CleanupAllResources()
+
+	rm.AdjustMemoryUsage(38, &mem.VirtualMemoryStat{Total: 100 * mb, Used: 38 * mb, UsedPercent: 38})
+	rm.AdjustCPUUsage(10)
+	rm.AdjustCPUUsage(90)
+	if err := rm.AdjustDiskTarget(target, 10, &disk.UsageStat{Total: 100 * mb, Used: 10 * mb, UsedPercent: 10}); err != nil {
+		t.Fatalf("AdjustDiskTarget: %v", err)
+	}
+	if err := rm.AdjustDiskTarget(target, 30, &disk.UsageStat{Total: 100 * mb, Used: 30 * mb, UsedPercent: 30}); err != nil {
+		t.Fatalf("AdjustDiskTarget: %v", err)
+	}
+	if err := rm.AdjustDiskTarget(target, 20.2, &disk.UsageStat{Total: 100 * mb, Used: 20 * mb, UsedPercent: 20.2}); err != nil {
+		t.Fatalf("AdjustDiskTarget: %v", err)
+	}
+
+	for _, want := range []string{
+		"决策 内存: 当前 38.0%, 目标 50.0%, 偏差 -12.0% → 分配 12.0 MiB",
+		"决策 CPU: 当前 10.0%, 目标 50.0%, 偏差 -40.0% → 增加负载 0.00 → 2.00 核",
+		"决策 CPU: 当前 90.0%, 目标 50.0%, 偏差 +40.0% → 减少负载 2.00 → 0.00 核",
+		"决策 磁盘 " + dir + ": 当前 10.0%, 目标 20.0%, 偏差 -10.0% → 写入 10.0 MiB",
+		"决策 磁盘 " + dir + ": 当前 30.0%, 目标 20.0%, 偏差 +10.0% → 释放 10.0 MiB",
+		"决策 磁盘 " + dir + ": 当前 20.2%, 目标 20.0%, 偏差 +0.2% → 在容差",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("日志缺少决策 %q:\n%s", want, buf)
+		}
+	}
+}
+
+func TestDecisionLinesHiddenAtInfoLevel(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{MemoryPercent: 50, Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+
+	rm.AdjustMemoryUsage(50, &mem.VirtualMemoryStat{Total: 1 << 30, UsedPercent: 50})
+	if strings.Contains(buf.String(), "决策") {
+		t.Errorf("info 级别输出了决策日志:\n%s", buf)
+	}
+}
@@ -0,0 +1,41 @@
+package occupy
+
+import (
+	"os"
+	"unsafe"
+)
+
+// directIOAlignment 直接I/O要求的缓冲区地址、写入长度对齐字节数
+const directIOAlignment = 4096
+
+// alignUp 将 n 向上对齐到 align 的整数倍
+func alignUp(n, align uint64) uint64 {
+	return (n + align - 1) / align * align
+}
+
+// alignedBuffer 分配起始地址按 align 对齐的缓冲区
+func alignedBuffer(size, align int) []byte {
+	buf := make([]byte, size+align)
+	offset := 0
+	if rem := int(uintptr(unsafe.Pointer(&buf[0])) & uintptr(align-1)); rem != 0 {
+		offset = align - rem
+	}
+	return buf[offset : offset+size : offset+size]
+}
+
+// createTempFile 创建临时文件，启用 DiskDirectIO 时尝试绕过页缓存打开，
+// 不支持时输出一次警告并回退到普通写入（调用方需持有 diskWriteMutex）。
+// 返回的 direct 表示文件是否以直接I/O方式打开
+func (rm *ResourceMonitor) createTempFile(filePath string) (file *os.File, direct bool, err error) {
+	if rm.Config.DiskDirectIO && !rm.directIOUnavailable {
+		file, err := openDirect(filePath)
+		if err == nil {
+			return file, true, nil
+		}
+		rm.directIOUnavailable = true
+		logWarnf("无法以直接I/O方式创建临时文件，回退到普通写入（将占用页缓存）: %v", err)
+	}
+
+	file, err = os.Create(filePath)
+	return file, false, err
+}
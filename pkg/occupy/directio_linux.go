@@ -0,0 +1,13 @@
+//go:build linux
+
+package occupy
+
+import (
+	"os"
+	"syscall"
+)
+
+// openDirect 以 O_DIRECT 方式创建文件，写入绕过页缓存
+func openDirect(filePath string) (*os.File, error) {
+	return os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC|syscall.O_DIRECT, 0644)
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package occupy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectIOWriteSize(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitor(ResourceConfig{DiskDirectIO: true})
+	// 大小不是对齐长度的整数倍，最后一块补齐写入后需截断
+	size := uint64(3*writeChunkSize/2 + 123)
+	path := filepath.Join(t.TempDir(), "direct.dat")
+	if err := rm.writeTempFile(path, size, nil, nil); err != nil {
+		t.Fatalf("writeTempFile: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uint64(info.Size()) != size {
+		t.Fatalf("文件大小 = %d, want %d", info.Size(), size)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range data {
+		if b != byte(i%256) {
+			t.Fatalf("偏移 %d = %d, want %d", i, b, byte(i%256))
+		}
+	}
+
+	if rm.directIOUnavailable {
+		if !strings.Contains(buf.String(), "回退到普通写入") {
+			t.Fatalf("直接I/O不可用时未输出警告:\n%s", buf)
+		}
+		t.Log("临时目录所在文件系统不支持直接I/O，已回退到普通写入")
+	}
+}
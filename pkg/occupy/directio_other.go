@@ -0,0 +1,13 @@
+//go:build !linux
+
+package occupy
+
+import (
+	"errors"
+	"os"
+)
+
+// openDirect 当前平台不支持直接I/O
+func openDirect(filePath string) (*os.File, error) {
+	return nil, errors.New("当前平台不支持直接I/O")
+}
@@ -0,0 +1,566 @@
+package occupy
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// dirBytes 统计目录下所有文件的实际大小
+func dirBytes(t *testing.T, dir string) uint64 {
+	t.Helper()
+	var total uint64
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		total += uint64(info.Size())
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return total
+}
+
+func TestDiskTargetsFillEachDir(t *testing.T) {
+	const diskTotal = 100 * 1024 * 1024
+	first, second := t.TempDir(), t.TempDir()
+	rm := NewResourceMonitor(ResourceConfig{
+		Interval: time.Second,
+		DiskTargets: []DiskTarget{
+			{Path: first, Percent: 1},
+			{Path: second, Percent: 3},
+		},
+	})
+	t.Cleanup(rm.CleanupAllResources)
+
+	// 每个目标按各自路径的使用率调整，这里两个路径都从0开始
+	for _, target := range rm.diskTargets() {
+		rm.AdjustDiskTarget(target, 0, &disk.UsageStat{Path: target.Path, Total: diskTotal})
+	}
+
+	for _, c := range []struct {
+		dir  string
+		want uint64
+	}{
+		{first, diskTotal / 100},
+		{second, 3 * diskTotal / 100},
+	} {
+		if got := dirBytes(t, c.dir); got != c.want {
+			t.Errorf("%s 写入 %d 字节, want %d", c.dir, got, c.want)
+		}
+	}
+
+	rm.CleanupAllResources()
+	for _, dir := range []string{first, second} {
+		if got := dirBytes(t, dir); got != 0 {
+			t.Errorf("清理后 %s 仍有 %d 字节", dir, got)
+		}
+	}
+}
+
+func TestDiskWriteRateLimit(t *testing.T) {
+	const size = 10 * 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitor(ResourceConfig{DiskWriteMBps: 20})
+	t.Cleanup(rm.CleanupAllResources)
+
+	start := time.Now()
+	rm.createTempFiles(dir, size)
+	if elapsed, min := time.Since(start), 500*time.Millisecond; elapsed < min {
+		t.Fatalf("以 20MB/s 写入 10MB 用时 %v, want >= %v", elapsed, min)
+	}
+	if got := dirBytes(t, dir); got != size {
+		t.Fatalf("写入 %d 字节, want %d", got, size)
+	}
+}
+
+func TestValidateDiskWriteRate(t *testing.T) {
+	tests := []struct {
+		mbps    float64
+		wantErr string
+	}{
+		{0, ""},
+		{-1, "不能为负数"},
+		{0.05, "过低"},
+		{MinDiskWriteMBps, ""},
+		{200, ""},
+	}
+	for _, tt := range tests {
+		err := ValidateDiskWriteRate(tt.mbps)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateDiskWriteRate(%g) = %v, want nil", tt.mbps, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateDiskWriteRate(%g) = %v, want 包含 %q", tt.mbps, err, tt.wantErr)
+		}
+	}
+	if err := ValidateConfig(ResourceConfig{Interval: MinInterval, DiskWriteMBps: 0.05}); err == nil {
+		t.Error("ValidateConfig 接受了过低的磁盘写入速率")
+	}
+}
+
+func TestRateLimitWaitReturnsOnStop(t *testing.T) {
+	limiter := newRateLimiter(MinDiskWriteMBps)
+	stop := make(chan bool)
+	time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+
+	// 最低速率下一块需要等待约4秒，停止后应立即返回
+	start := time.Now()
+	if limiter.wait(writeChunkSize, stop) {
+		t.Error("停止后 wait = true, want false")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("停止后 wait 耗时 %v", elapsed)
+	}
+	if !(*rateLimiter)(nil).wait(writeChunkSize, stop) {
+		t.Error("不限速时 wait = false, want true")
+	}
+}
+
+// writeFillFiles 以指定的填充方式写入两个临时文件并返回其内容
+func writeFillFiles(t *testing.T, mode string) (first, second []byte) {
+	t.Helper()
+	dir := t.TempDir()
+	rm := NewResourceMonitor(ResourceConfig{DiskFillMode: mode})
+	var contents [2][]byte
+	for i := range contents {
+		path := filepath.Join(dir, fmt.Sprintf("fill_%d.dat", i))
+		if err := rm.writeTempFile(path, 3*writeChunkSize/2, nil, nil); err != nil {
+			t.Fatalf("%s: writeTempFile: %v", mode, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		contents[i] = data
+	}
+	return contents[0], contents[1]
+}
+
+func TestDiskFillModes(t *testing.T) {
+	first, second := writeFillFiles(t, DiskFillRandom)
+	if bytes.Equal(first, second) {
+		t.Error("random: 两个文件内容相同")
+	}
+	if bytes.Equal(first[:writeChunkSize/2], first[writeChunkSize:3*writeChunkSize/2]) {
+		t.Error("random: 同一文件的不同写入块内容相同")
+	}
+
+	first, second = writeFillFiles(t, DiskFillZero)
+	for _, data := range [][]byte{first, second} {
+		for i, b := range data {
+			if b != 0 {
+				t.Fatalf("zero: 偏移 %d = %d, want 0", i, b)
+			}
+		}
+	}
+
+	first, _ = writeFillFiles(t, DiskFillSequential)
+	for i, b := range first {
+		if b != byte(i%256) {
+			t.Fatalf("sequential: 偏移 %d = %d, want %d", i, b, byte(i%256))
+		}
+	}
+}
+
+func TestMaxDiskBytesCapsWrites(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	target := DiskTarget{Path: dir, Percent: 90}
+	rm := NewResourceMonitor(ResourceConfig{
+		DiskTargets:  []DiskTarget{target},
+		MaxDiskBytes: 5 * mb,
+	})
+	t.Cleanup(rm.CleanupAllResources)
+
+	diskInfo := &disk.UsageStat{Path: dir, Total: 1 << 30}
+	for i := 0; i < 3; i++ {
+		rm.AdjustDiskTarget(target, 0, diskInfo)
+		if got := dirBytes(t, dir); got > 5*mb {
+			t.Fatalf("第 %d 次调整后写入 %d 字节, 超过上限 %d", i+1, got, 5*mb)
+		}
+	}
+	if got := rm.TempFileBytes(); got != 5*mb {
+		t.Fatalf("TempFileBytes = %d, want %d", got, 5*mb)
+	}
+}
+
+func TestTempFileQueriesDoNotWaitForWrite(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitor(ResourceConfig{DiskTargets: []DiskTarget{{Path: dir, Percent: 50}}})
+	t.Cleanup(rm.CleanupAllResources)
+	if err := rm.createTempFiles(dir, mb); err != nil {
+		t.Fatalf("createTempFiles: %v", err)
+	}
+
+	// 第二个文件的写入一直阻塞，直到测试结束
+	writing, release := make(chan struct{}), make(chan struct{})
+	original := writeTempFileOnce
+	writeTempFileOnce = func(rm *ResourceMonitor, filePath string, size uint64, limiter *rateLimiter, stop <-chan bool) error {
+		close(writing)
+		<-release
+		return original(rm, filePath, size, limiter, stop)
+	}
+	t.Cleanup(func() { writeTempFileOnce = original })
+	written := make(chan error, 1)
+	go func() { written <- rm.createTempFiles(dir, mb) }()
+	<-writing
+
+	read := make(chan uint64, 1)
+	go func() { read <- rm.TempFileBytes() }()
+	select {
+	case got := <-read:
+		if got != mb {
+			t.Errorf("写入期间 TempFileBytes = %d, want %d", got, mb)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("TempFileBytes 等待写入完成")
+	}
+	listed := make(chan []FileInfo, 1)
+	go func() { listed <- rm.ListTempFiles() }()
+	select {
+	case files := <-listed:
+		if len(files) != 1 {
+			t.Errorf("写入期间 ListTempFiles = %+v, want 1 个已写完的文件", files)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("ListTempFiles 等待写入完成")
+	}
+
+	close(release)
+	if err := <-written; err != nil {
+		t.Fatalf("createTempFiles: %v", err)
+	}
+	if got := rm.TempFileBytes(); got != 2*mb {
+		t.Errorf("写入后 TempFileBytes = %d, want %d", got, 2*mb)
+	}
+}
+
+func TestUnwritableDiskSelfDisables(t *testing.T) {
+	const mb = 1024 * 1024
+	// 以普通文件作为父目录，即使以 root 运行也无法在其中创建文件
+	parent := filepath.Join(t.TempDir(), "readonly")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(parent, "tmp")
+	target := DiskTarget{Path: dir, Percent: 10}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{target},
+	}, newFakeMetrics(1<<30, 10*mb))
+	t.Cleanup(rm.CleanupAllResources)
+	buf := captureLog(t, LogInfo)
+
+	diskInfo := &disk.UsageStat{Path: dir, Total: 10 * mb}
+	var failures int
+	for i := 0; i < 2*diskFailureLimit; i++ {
+		if err := rm.AdjustDiskTarget(target, 0, diskInfo); err != nil {
+			failures++
+		}
+	}
+	if failures != diskFailureLimit {
+		t.Fatalf("写入失败 %d 次, want %d（之后应暂停磁盘占用）", failures, diskFailureLimit)
+	}
+	if n := strings.Count(buf.String(), "暂停该目录的磁盘占用"); n != 1 {
+		t.Fatalf("暂停日志输出 %d 次, want 1:\n%s", n, buf)
+	}
+
+	if err := os.Remove(parent); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.AdjustDiskTarget(target, 0, diskInfo); err != nil {
+		t.Fatalf("目录恢复可写后 AdjustDiskTarget = %v", err)
+	}
+	if !strings.Contains(buf.String(), "恢复可写") || rm.TempFileBytes() != mb {
+		t.Fatalf("目录恢复可写后未重新启用磁盘占用 (TempFileBytes = %d):\n%s", rm.TempFileBytes(), buf)
+	}
+}
+
+func TestDiskFilesPerDirSpreadsFiles(t *testing.T) {
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskFilesPerDir: 2,
+	}, newFakeMetrics(1<<30, 1<<40))
+	t.Cleanup(rm.CleanupAllResources)
+
+	for i := 0; i < 5; i++ {
+		if err := rm.createTempFiles(dir, 4096); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var counts []int
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			t.Fatalf("临时文件 %s 未写入子目录", entry.Name())
+		}
+		files, err := os.ReadDir(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts = append(counts, len(files))
+	}
+	if len(counts) != 3 || counts[0] != 2 || counts[1] != 2 || counts[2] != 1 {
+		t.Fatalf("各子目录文件数 = %v, want [2 2 1]", counts)
+	}
+
+	rm.CleanupAllTempFiles()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("清理后仍有 %d 个条目", len(entries))
+	}
+}
+
+func TestDiskFsyncWritesFullSize(t *testing.T) {
+	const size = 3*1024*1024 + 123
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{{Path: dir}},
+		DiskFsync:   true,
+		Interval:    MinInterval,
+	}, newFakeMetrics(1<<30, 1<<40))
+	defer rm.CleanupAllTempFiles()
+
+	if err := rm.createTempFiles(dir, size); err != nil {
+		t.Fatalf("createTempFiles: %v", err)
+	}
+	files := rm.tempFiles[dir]
+	if len(files) != 1 {
+		t.Fatalf("临时文件 = %v, want 1 个", files)
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("同步后临时文件不存在: %v", err)
+	}
+	if info.Size() != size || rm.TempFileBytes() != size {
+		t.Fatalf("文件大小 = %d, 记录大小 = %d, want %d", info.Size(), rm.TempFileBytes(), size)
+	}
+}
+
+// pathRecordingMetrics 记录读取磁盘信息时使用的路径
+type pathRecordingMetrics struct {
+	*fakeMetrics
+	paths []string
+}
+
+func (m *pathRecordingMetrics) Disk(path string) (*disk.UsageStat, error) {
+	m.paths = append(m.paths, path)
+	return m.fakeMetrics.Disk(path)
+}
+
+func TestMeasurePathFollowsTempDir(t *testing.T) {
+	tempDir := filepath.Join(t.TempDir(), "elsewhere")
+	if err := os.Mkdir(tempDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GO_OCCUPY_TEMP_DIR", tempDir)
+	metrics := &pathRecordingMetrics{fakeMetrics: newFakeMetrics(1<<30, 100*1024*1024)}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{DiskPercent: 1, Interval: MinInterval}, metrics)
+	defer rm.CleanupAllResources()
+
+	rm.MonitorAndAdjust()
+	if len(metrics.paths) == 0 {
+		t.Fatal("未读取磁盘信息")
+	}
+	for _, path := range metrics.paths {
+		if path != tempDir {
+			t.Fatalf("统计使用率的路径 = %s, want 临时文件目录 %s", path, tempDir)
+		}
+	}
+}
+
+func TestMeasurePathOnOtherFilesystemWarns(t *testing.T) {
+	stubFilesystemType(t, "ext4")
+	tempDir := t.TempDir()
+	t.Setenv("GO_OCCUPY_TEMP_DIR", tempDir)
+	if same, err := sameFilesystem("/proc", tempDir); err != nil || same {
+		t.Skipf("无法构造不同的文件系统: same=%v err=%v", same, err)
+	}
+
+	buf := captureLog(t, LogInfo)
+	if err := ValidateConfig(ResourceConfig{DiskPercent: 1, DiskPath: "/proc", Interval: MinInterval}); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if !strings.Contains(buf.String(), "不在同一文件系统") {
+		t.Fatalf("测量路径与临时文件目录不在同一文件系统时未警告:\n%s", buf)
+	}
+
+	buf = captureLog(t, LogInfo)
+	if err := ValidateConfig(ResourceConfig{DiskPercent: 1, Interval: MinInterval}); err != nil {
+		t.Fatalf("ValidateConfig: %v", err)
+	}
+	if strings.Contains(buf.String(), "不在同一文件系统") {
+		t.Fatalf("默认测量路径也输出了警告:\n%s", buf)
+	}
+}
+
+func TestDiskOverTargetRemovesProportionalFiles(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		current float64
+		want    int
+	}{
+		{11, 9}, // 超出目标 10%（1MB）只删除 1 个文件
+		{13, 7},
+		{10.4, 10}, // 在容差内不删除
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		target := DiskTarget{Path: dir, Percent: 10}
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			DiskTargets:   []DiskTarget{target},
+			DiskTolerance: 0.5,
+			Interval:      MinInterval,
+		}, newFakeMetrics(1<<30, 100*mb))
+		for i := 0; i < 10; i++ {
+			if err := rm.createTempFiles(dir, mb); err != nil {
+				t.Fatalf("createTempFiles: %v", err)
+			}
+		}
+
+		diskInfo := &disk.UsageStat{Total: 100 * mb, Used: uint64(tt.current * mb), UsedPercent: tt.current}
+		if err := rm.AdjustDiskTarget(target, tt.current, diskInfo); err != nil {
+			t.Fatalf("AdjustDiskTarget: %v", err)
+		}
+		if got := len(rm.tempFiles[dir]); got != tt.want {
+			t.Errorf("使用率 %.1f%%: 剩余 %d 个文件, want %d", tt.current, got, tt.want)
+		}
+		if got := dirBytes(t, dir); got != uint64(tt.want)*mb {
+			t.Errorf("使用率 %.1f%%: 目录剩余 %d 字节, want %d", tt.current, got, uint64(tt.want)*mb)
+		}
+		rm.CleanupAllTempFiles()
+	}
+}
+
+func TestZeroTotalIsSkippedWithWarning(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	dir := t.TempDir()
+	target := DiskTarget{Path: dir, Percent: 50}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 50,
+		DiskTargets:   []DiskTarget{target},
+		Interval:      MinInterval,
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+
+	if err := rm.AdjustDiskTarget(target, 0, &disk.UsageStat{Total: 0}); err != nil {
+		t.Fatalf("AdjustDiskTarget: %v", err)
+	}
+	if !strings.Contains(buf.String(), "总量为0，磁盘信息无效") {
+		t.Errorf("磁盘总量为0时未输出警告:\n%s", buf)
+	}
+	if files := rm.tempFiles[dir]; len(files) != 0 {
+		t.Errorf("磁盘总量为0时创建了 %d 个临时文件", len(files))
+	}
+	if got := dirBytes(t, dir); got != 0 {
+		t.Errorf("磁盘总量为0时写入了 %d 字节", got)
+	}
+
+	rm.AdjustMemoryUsage(0, &mem.VirtualMemoryStat{Total: 0})
+	if !strings.Contains(buf.String(), "内存总量为0，内存信息无效") {
+		t.Errorf("内存总量为0时未输出警告:\n%s", buf)
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("内存总量为0时分配了 %d 字节", got)
+	}
+}
+
+func TestDiskMeasureOnlyMeasuresWithoutWriting(t *testing.T) {
+	dir := t.TempDir()
+	metrics := &pathRecordingMetrics{fakeMetrics: newFakeMetrics(1<<30, 100*1024*1024)}
+	metrics.setDiskUsed(10 * 1024 * 1024)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets:     []DiskTarget{{Path: dir, Percent: 50}},
+		DiskMeasureOnly: true,
+		Interval:        MinInterval,
+	}, metrics)
+	defer rm.CleanupAllResources()
+
+	rm.MonitorAndAdjust()
+	if len(metrics.paths) == 0 || metrics.paths[0] != dir {
+		t.Fatalf("读取磁盘信息的路径 = %v, want [%s]", metrics.paths, dir)
+	}
+	if got := rm.LastMeasurement().DiskPercents; len(got) != 1 || got[0] != 10 {
+		t.Errorf("DiskPercents = %v, want [10]", got)
+	}
+	if files := rm.tempFiles[dir]; len(files) != 0 {
+		t.Errorf("只测量磁盘时创建了 %d 个临时文件", len(files))
+	}
+	if got := dirBytes(t, dir); got != 0 {
+		t.Errorf("只测量磁盘时写入了 %d 字节", got)
+	}
+}
+
+func TestStopDuringFileCreationLeavesNoPartialFile(t *testing.T) {
+	const mb = 1024 * 1024
+	buf := captureLog(t, LogDebug)
+	dir := t.TempDir()
+	// 限速 8MB/s 写入 200MB 的文件，停止时文件一定还在写入中
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets:   []DiskTarget{{Path: dir, Percent: 50}},
+		DiskWriteMBps: 8,
+		Interval:      MinInterval,
+	}, newFakeMetrics(1<<30, 400*mb))
+
+	done := rm.Done()
+	go rm.Start()
+	waitFor(t, 10*time.Second, "开始写入临时文件", func() bool {
+		return dirBytes(t, dir) > 0
+	})
+	if got := dirBytes(t, dir); got >= 200*mb {
+		t.Fatalf("停止前已写完整个文件 (%d 字节)", got)
+	}
+
+	stopped := time.Now()
+	rm.Stop()
+	waitDone(t, done, 5*time.Second)
+	if elapsed := time.Since(stopped); elapsed > 3*time.Second {
+		t.Errorf("停止耗时 %v，未中断写入中的文件", elapsed)
+	}
+	if got := dirBytes(t, dir); got != 0 {
+		t.Errorf("停止后残留 %d 字节", got)
+	}
+	if files := rm.tempFiles[dir]; len(files) != 0 {
+		t.Errorf("停止后仍持有 %d 个临时文件", len(files))
+	}
+	if !strings.Contains(buf.String(), errWriteStopped.Error()) {
+		t.Errorf("未中断写入中的文件:\n%s", buf)
+	}
+}
+
+func TestWriteTempFileHonorsStopChannel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stopped.dat")
+	rm := NewResourceMonitor(ResourceConfig{})
+	stop := make(chan bool)
+	close(stop)
+
+	if err := rm.writeTempFile(path, 3*writeChunkSize, nil, stop); err != errWriteStopped {
+		t.Fatalf("writeTempFile = %v, want %v", err, errWriteStopped)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("停止后残留不完整的文件: %v", err)
+	}
+}
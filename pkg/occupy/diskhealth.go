@@ -0,0 +1,61 @@
+package occupy
+
+import (
+	"os"
+)
+
+// diskFailureLimit 连续写入失败达到该次数后暂停该目录的磁盘占用
+const diskFailureLimit = 3
+
+// diskWriteFailed 记录临时文件写入失败，连续失败达到 diskFailureLimit 次后暂停该目录的磁盘占用
+func (rm *ResourceMonitor) diskWriteFailed(dir string, err error) {
+	if rm.diskFailures == nil {
+		rm.diskFailures = make(map[string]int)
+		rm.diskDisabled = make(map[string]bool)
+	}
+
+	rm.diskFailures[dir]++
+	if rm.diskFailures[dir] < diskFailureLimit {
+		return
+	}
+
+	rm.diskDisabled[dir] = true
+	logWarnf("临时文件目录 %s 连续写入失败 %d 次，暂停该目录的磁盘占用（内存/CPU占用不受影响）: %v",
+		dir, rm.diskFailures[dir], err)
+}
+
+// diskWriteSucceeded 写入成功后重置失败计数
+func (rm *ResourceMonitor) diskWriteSucceeded(dir string) {
+	if rm.diskFailures != nil {
+		delete(rm.diskFailures, dir)
+	}
+}
+
+// diskWriteEnabled 检查目录是否允许进行磁盘占用；已暂停时尝试写入探测文件，成功则恢复
+func (rm *ResourceMonitor) diskWriteEnabled(dir string) bool {
+	if !rm.diskDisabled[dir] {
+		return true
+	}
+	if !probeDiskWrite(dir) {
+		return false
+	}
+
+	delete(rm.diskDisabled, dir)
+	delete(rm.diskFailures, dir)
+	logInfof("临时文件目录 %s 恢复可写，重新启用磁盘占用", dir)
+	return true
+}
+
+// probeDiskWrite 在目录中写入并删除一个探测文件，检查目录是否可写
+func probeDiskWrite(dir string) bool {
+	file, err := os.CreateTemp(dir, ".go_occupy_probe_*")
+	if err != nil {
+		return false
+	}
+	_, err = file.Write([]byte{0})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	os.Remove(file.Name())
+	return err == nil
+}
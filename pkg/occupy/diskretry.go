@@ -0,0 +1,74 @@
+package occupy
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+)
+
+// DefaultDiskWriteRetries 单个临时文件写入失败后默认的重试次数
+const DefaultDiskWriteRetries = 2
+
+// DefaultDiskRetryDelay 临时文件写入重试的默认初始等待时间，之后每次重试加倍
+const DefaultDiskRetryDelay = 200 * time.Millisecond
+
+// diskWriteRetries 获取单个临时文件的重试次数，小于0表示不重试
+func (rm *ResourceMonitor) diskWriteRetries() int {
+	if rm.Config.DiskWriteRetries == 0 {
+		return DefaultDiskWriteRetries
+	}
+	if rm.Config.DiskWriteRetries < 0 {
+		return 0
+	}
+	return rm.Config.DiskWriteRetries
+}
+
+// diskRetryDelay 获取首次重试前的等待时间
+func (rm *ResourceMonitor) diskRetryDelay() time.Duration {
+	if rm.Config.DiskRetryDelay <= 0 {
+		return DefaultDiskRetryDelay
+	}
+	return rm.Config.DiskRetryDelay
+}
+
+// errWriteStopped 写入临时文件期间收到停止信号，已删除写了一半的文件
+var errWriteStopped = errors.New("收到停止信号，放弃写入临时文件")
+
+// stopRequested 停止通道 stop 是否已关闭（不阻塞）
+func stopRequested(stop <-chan bool) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// writeTempFileOnce 写入一次临时文件（可在测试中替换）
+var writeTempFileOnce = (*ResourceMonitor).writeTempFile
+
+// permanentWriteError 判断写入错误是否为重试也无法恢复的错误（只读文件系统、权限不足、收到停止信号）
+func permanentWriteError(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || isReadOnlyFS(err) || errors.Is(err, errWriteStopped)
+}
+
+// writeTempFileRetry 写入临时文件，失败时按指数退避重试；只读文件系统等永久错误不重试，
+// stop 关闭时放弃等待（调用方需持有 diskWriteMutex）
+func (rm *ResourceMonitor) writeTempFileRetry(filePath string, size uint64, limiter *rateLimiter, stop <-chan bool) error {
+	retries := rm.diskWriteRetries()
+	delay := rm.diskRetryDelay()
+	for attempt := 0; ; attempt++ {
+		err := writeTempFileOnce(rm, filePath, size, limiter, stop)
+		if err == nil || attempt >= retries || permanentWriteError(err) {
+			return err
+		}
+
+		logWarnf("写入临时文件失败，%v 后重试 (%d/%d): %v", delay, attempt+1, retries, err)
+		select {
+		case <-stop:
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
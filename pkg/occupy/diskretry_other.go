@@ -0,0 +1,8 @@
+//go:build !unix
+
+package occupy
+
+// isReadOnlyFS 非Unix平台无法区分只读文件系统，按临时错误处理
+func isReadOnlyFS(err error) bool {
+	return false
+}
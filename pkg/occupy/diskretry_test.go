@@ -0,0 +1,77 @@
+//go:build unix
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// stubWriteTempFile 替换单次写入临时文件：前 failures 次返回 err，之后正常写入，返回调用次数
+func stubWriteTempFile(t *testing.T, failures int, err error) *int {
+	t.Helper()
+	calls := new(int)
+	original := writeTempFileOnce
+	writeTempFileOnce = func(rm *ResourceMonitor, filePath string, size uint64, limiter *rateLimiter, stop <-chan bool) error {
+		*calls++
+		if *calls <= failures {
+			return fmt.Errorf("写入临时文件失败: %w", err)
+		}
+		return original(rm, filePath, size, limiter, stop)
+	}
+	t.Cleanup(func() { writeTempFileOnce = original })
+	return calls
+}
+
+func TestTransientWriteErrorIsRetried(t *testing.T) {
+	calls := stubWriteTempFile(t, 1, syscall.ENOSPC)
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets:    []DiskTarget{{Path: dir, Percent: 50}},
+		DiskRetryDelay: time.Millisecond,
+		Interval:       MinInterval,
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+	defer rm.CleanupAllResources()
+
+	if err := rm.createTempFiles(dir, 1024*1024); err != nil {
+		t.Fatalf("createTempFiles: %v", err)
+	}
+	if *calls != 2 {
+		t.Errorf("写入次数 = %d, want 2", *calls)
+	}
+	files := rm.tempFiles[dir]
+	if len(files) != 1 {
+		t.Fatalf("临时文件数量 = %d, want 1", len(files))
+	}
+	info, err := os.Stat(files[0])
+	if err != nil {
+		t.Fatalf("重试后临时文件不存在: %v", err)
+	}
+	if info.Size() != 1024*1024 {
+		t.Errorf("临时文件大小 = %d, want %d", info.Size(), 1024*1024)
+	}
+}
+
+func TestPermanentWriteErrorIsNotRetried(t *testing.T) {
+	calls := stubWriteTempFile(t, 10, syscall.EROFS)
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets:    []DiskTarget{{Path: dir, Percent: 50}},
+		DiskRetryDelay: time.Millisecond,
+		Interval:       MinInterval,
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+	defer rm.CleanupAllResources()
+
+	if err := rm.createTempFiles(dir, 1024*1024); err == nil {
+		t.Fatal("只读文件系统时 createTempFiles 未返回错误")
+	}
+	if *calls != 1 {
+		t.Errorf("只读文件系统时写入次数 = %d, want 1", *calls)
+	}
+	if files := rm.tempFiles[dir]; len(files) != 0 {
+		t.Errorf("临时文件数量 = %d, want 0", len(files))
+	}
+}
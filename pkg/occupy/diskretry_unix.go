@@ -0,0 +1,13 @@
+//go:build unix
+
+package occupy
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isReadOnlyFS 判断错误是否由只读文件系统引起
+func isReadOnlyFS(err error) bool {
+	return errors.Is(err, syscall.EROFS)
+}
@@ -0,0 +1,26 @@
+package occupy
+
+import "fmt"
+
+// DiskError 在指定目录进行磁盘占用失败
+type DiskError struct {
+	Dir string
+	Err error
+}
+
+func (e *DiskError) Error() string {
+	return fmt.Sprintf("磁盘占用失败 (%s): %v", e.Dir, e.Err)
+}
+
+func (e *DiskError) Unwrap() error {
+	return e.Err
+}
+
+// reportError 报告调整过程中的错误：设置了 OnError 时交由调用方处理，否则记录日志
+func (rm *ResourceMonitor) reportError(err error) {
+	if rm.OnError != nil {
+		rm.OnError(err)
+		return
+	}
+	logErrorf("%v", err)
+}
@@ -0,0 +1,55 @@
+package occupy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// unwritableDir 返回一个无法在其中创建文件的目录路径（父路径是普通文件，root 也无法写入）
+func unwritableDir(t *testing.T) string {
+	t.Helper()
+	parent := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(parent, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(parent, "tmp")
+}
+
+func TestDiskCreateFailureReturnsError(t *testing.T) {
+	dir := unwritableDir(t)
+	target := DiskTarget{Path: dir, Percent: 10}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{target},
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+
+	err := rm.AdjustDiskTarget(target, 0, &disk.UsageStat{Path: dir, Total: 100 * 1024 * 1024})
+	var diskErr *DiskError
+	if !errors.As(err, &diskErr) || diskErr.Dir != dir {
+		t.Fatalf("AdjustDiskTarget = %v, want *DiskError for %s", err, dir)
+	}
+	if errors.Unwrap(diskErr) == nil {
+		t.Fatal("DiskError 未包含底层错误")
+	}
+}
+
+func TestDiskCreateFailureReachesOnError(t *testing.T) {
+	dir := unwritableDir(t)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{{Path: dir, Percent: 10}},
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+	var reported []error
+	rm.OnError = func(err error) { reported = append(reported, err) }
+
+	rm.MonitorAndAdjust()
+	if len(reported) != 1 {
+		t.Fatalf("OnError 调用 %d 次, want 1", len(reported))
+	}
+	var diskErr *DiskError
+	if !errors.As(reported[0], &diskErr) || diskErr.Dir != dir {
+		t.Fatalf("OnError(%v), want *DiskError for %s", reported[0], dir)
+	}
+}
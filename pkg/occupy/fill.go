@@ -0,0 +1,38 @@
+package occupy
+
+import (
+	"fmt"
+)
+
+// Fill 分配最多 bytes 字节的内存并保持占用，返回实际分配的字节数。
+// 设置了 MemoryFloorBytes 时，分配量不会使可用内存低于该下限；
+// 设置了 MaxMemoryBytes 或 RlimitMemoryBytes 时，总分配量不会超过对应的上限。
+// 分配的内存由监控器管理，可被后续的调整释放，并在 Stop 时清理。
+// Fill 是并发安全的，可以在监控运行期间调用。
+func (rm *ResourceMonitor) Fill(bytes uint64) (allocated uint64, err error) {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	if floor := rm.Config.MemoryFloorBytes; floor > 0 {
+		memInfo, err := rm.metricsProvider().Memory()
+		if err != nil {
+			return 0, fmt.Errorf("获取内存信息失败: %v", err)
+		}
+		if memInfo.Available <= floor {
+			return 0, fmt.Errorf("可用内存 %s 已低于下限 %s", FormatBytes(memInfo.Available), FormatBytes(floor))
+		}
+		if limit := memInfo.Available - floor; bytes > limit {
+			bytes = limit
+		}
+	}
+
+	if bytes = rm.capMemoryBytes(bytes); bytes == 0 {
+		maxBytes, _ := rm.maxMemoryBytes()
+		return 0, fmt.Errorf("已达到内存分配上限 %s", FormatBytes(maxBytes))
+	}
+	allocated, err = rm.allocateChunks(bytes)
+	if err != nil {
+		rm.rlimitAllocFailed()
+	}
+	return allocated, err
+}
@@ -0,0 +1,61 @@
+package occupy
+
+import (
+	"testing"
+)
+
+// allocatedLocked 在持有 memoryMutex 时读取 getTotalAllocatedMemory
+func allocatedLocked(rm *ResourceMonitor) uint64 {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+	return rm.getTotalAllocatedMemory()
+}
+
+func TestFillReturnsAllocatedDelta(t *testing.T) {
+	const mb = 1024 * 1024
+	// 受控的内存数据：可用内存固定为 1GB，不受本机其他进程影响
+	const available = 1024 * mb
+
+	for _, c := range []struct {
+		name    string
+		floor   uint64
+		request uint64
+		// want 实际分配的字节数，wantErr 时应为0
+		want    uint64
+		wantErr bool
+	}{
+		{"无下限", 0, 4 * mb, 4 * mb, false},
+		// 可用内存只比下限多8MB，分配量受下限限制
+		{"接近下限", available - 8*mb, 64 * mb, 8 * mb, false},
+		{"低于下限", available * 2, mb, 0, true},
+	} {
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{MemoryFloorBytes: c.floor}, newFakeMetrics(available, 1<<30))
+		before := allocatedLocked(rm)
+		allocated, err := rm.Fill(c.request)
+		if (err != nil) != c.wantErr {
+			t.Fatalf("%s: Fill(%d) err = %v, wantErr %v", c.name, c.request, err, c.wantErr)
+		}
+		if delta := allocatedLocked(rm) - before; allocated != delta || allocated != c.want {
+			t.Fatalf("%s: Fill(%d) = %d, 已分配增加 %d, want %d", c.name, c.request, allocated, delta, c.want)
+		}
+		if c.floor > 0 && c.floor < available && allocated > available-c.floor {
+			t.Fatalf("%s: Fill(%d) = %d, 超出下限允许的 %d", c.name, c.request, allocated, available-c.floor)
+		}
+		rm.CleanupAllResources()
+	}
+}
+
+func BenchmarkFill(b *testing.B) {
+	const chunk = 64 * 1024 * 1024
+	rm := NewResourceMonitor(ResourceConfig{})
+	b.SetBytes(chunk)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := rm.Fill(chunk); err != nil {
+			b.Fatal(err)
+		}
+		b.StopTimer()
+		rm.CleanupAllResources()
+		b.StartTimer()
+	}
+}
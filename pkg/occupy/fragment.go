@@ -0,0 +1,60 @@
+package occupy
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// DefaultMemoryFragmentMin 碎片模式下内存块的默认最小大小
+const DefaultMemoryFragmentMin = 64 * 1024
+
+// DefaultMemoryFragmentMax 碎片模式下内存块的默认最大大小，与均匀分配时的块大小相同
+const DefaultMemoryFragmentMax = memoryChunkSize
+
+// memoryFragmentBounds 获取碎片模式下内存块大小的范围，未设置时使用默认值
+func (rm *ResourceMonitor) memoryFragmentBounds() (min, max uint64) {
+	min, max = rm.Config.MemoryFragmentMin, rm.Config.MemoryFragmentMax
+	if min == 0 {
+		min = DefaultMemoryFragmentMin
+	}
+	if max == 0 {
+		max = DefaultMemoryFragmentMax
+	}
+	return min, max
+}
+
+// ValidateMemoryFragment 验证碎片模式的块大小范围
+func ValidateMemoryFragment(config ResourceConfig) error {
+	if !config.MemoryFragment {
+		return nil
+	}
+	rm := &ResourceMonitor{Config: config}
+	min, max := rm.memoryFragmentBounds()
+	if min > max {
+		return fmt.Errorf("内存块最小大小 %s 不能大于最大大小 %s", FormatBytes(min), FormatBytes(max))
+	}
+	return nil
+}
+
+// nextChunkSize 本次分配的内存块大小：默认为固定的 memoryChunkSize；碎片模式下在
+// [MemoryFragmentMin, MemoryFragmentMax] 内均匀随机选取。不超过剩余字节数（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) nextChunkSize(remaining uint64) uint64 {
+	size := uint64(memoryChunkSize)
+	if rm.Config.MemoryFragment {
+		min, max := rm.memoryFragmentBounds()
+		size = min + uint64(rm.memoryRandom().Int63n(int64(max-min+1)))
+	}
+	if remaining < size {
+		return remaining
+	}
+	return size
+}
+
+// memoryRandom 获取内存相关的随机数生成器：碎片模式的块大小、随机填充的内容和随机释放顺序
+// 都从中取值，由 Seed 决定（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) memoryRandom() *rand.Rand {
+	if rm.memoryRand == nil {
+		rm.memoryRand = rand.New(rand.NewSource(rm.randomSeed()))
+	}
+	return rm.memoryRand
+}
@@ -0,0 +1,54 @@
+package occupy
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestFragmentChunkSizesVaryWithinBounds(t *testing.T) {
+	const (
+		min     = 64 * 1024
+		max     = 1024 * 1024
+		request = 16 * 1024 * 1024
+	)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:     50,
+		MemoryFragment:    true,
+		MemoryFragmentMin: min,
+		MemoryFragmentMax: max,
+		Seed:              1,
+		Interval:          MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+	defer rm.cleanupMemory()
+
+	rm.AllocateMemory(request)
+	sizes := make(map[int]bool)
+	var total uint64
+	for i, chunk := range rm.AllocatedMemory {
+		size := len(chunk)
+		total += uint64(size)
+		sizes[size] = true
+		// 最后一块可能小于最小值；块大小向上对齐到页
+		if size > int(pageAlign(max)) || (size < min && i != len(rm.AllocatedMemory)-1) {
+			t.Errorf("第 %d 块大小 %d 不在 [%d, %d] 内", i, size, min, max)
+		}
+	}
+	if len(sizes) < 2 {
+		t.Errorf("碎片模式下 %d 块的大小都相同", len(rm.AllocatedMemory))
+	}
+	pad := uint64(len(rm.AllocatedMemory) * os.Getpagesize())
+	if total < request || total >= request+pad {
+		t.Errorf("内存块总大小 = %d, want [%d, %d)", total, request, request+pad)
+	}
+	if got := rm.AllocatedBytes(); got != total {
+		t.Errorf("AllocatedBytes = %d, want %d", got, total)
+	}
+
+	// 释放全部超出量后不再持有内存块
+	rm.ReleaseMemory(100, &mem.VirtualMemoryStat{Total: 4 * request, UsedPercent: 100})
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("释放后 AllocatedBytes = %d, want 0", got)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package occupy
+
+// sameFilesystem 当前平台无法比较设备号，视为同一个文件系统
+func sameFilesystem(a, b string) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package occupy
+
+import (
+	"syscall"
+)
+
+// sameFilesystem 两个路径是否位于同一个文件系统（设备号相同）
+func sameFilesystem(a, b string) (bool, error) {
+	var statA, statB syscall.Stat_t
+	if err := syscall.Stat(a, &statA); err != nil {
+		return false, err
+	}
+	if err := syscall.Stat(b, &statB); err != nil {
+		return false, err
+	}
+	return statA.Dev == statB.Dev, nil
+}
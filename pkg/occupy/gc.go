@@ -0,0 +1,46 @@
+package occupy
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// forceGC 强制垃圾回收（可在测试中替换）
+var forceGC = runtime.GC
+
+// freeOSMemory 强制垃圾回收并将内存归还操作系统（可在测试中替换）
+var freeOSMemory = debug.FreeOSMemory
+
+// setGCPercent 设置GC目标百分比并返回原值（可在测试中替换）
+var setGCPercent = debug.SetGCPercent
+
+// applyGCPercent 设置 GCPercentOverride 时在运行开始时设置GC目标百分比，并记录原值以便结束时恢复
+func (rm *ResourceMonitor) applyGCPercent() {
+	if !rm.Config.GCPercentOverride {
+		return
+	}
+	rm.previousGCPercent = setGCPercent(rm.Config.GCPercent)
+	rm.gcPercentApplied = true
+	if rm.Config.GCPercent < 0 {
+		logWarnf("已关闭自动垃圾回收，堆内存只在主动GC时回收")
+	} else {
+		logInfof("GC目标百分比: %d (原为 %d)", rm.Config.GCPercent, rm.previousGCPercent)
+	}
+	if !rm.memoryAllocator().managedByGC() {
+		logWarnf("内存分配方式 %s 不受GC管理，GC目标百分比只影响程序自身的堆内存", rm.Config.MemoryAllocator)
+	}
+}
+
+// restoreGCPercent 运行结束时恢复运行前的GC目标百分比
+func (rm *ResourceMonitor) restoreGCPercent() {
+	if !rm.gcPercentApplied {
+		return
+	}
+	setGCPercent(rm.previousGCPercent)
+	rm.gcPercentApplied = false
+}
+
+// forcedGCEnabled 是否在释放内存后主动触发垃圾回收
+func (rm *ResourceMonitor) forcedGCEnabled() bool {
+	return !rm.Config.DisableForcedGC && rm.memoryAllocator().managedByGC()
+}
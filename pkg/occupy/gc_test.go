@@ -0,0 +1,108 @@
+package occupy
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// stubGC 将 forceGC 和 freeOSMemory 替换为只计数的函数，测试结束时恢复
+func stubGC(t *testing.T) (gcCalls, freeCalls *atomic.Int32) {
+	t.Helper()
+	gcCalls, freeCalls = new(atomic.Int32), new(atomic.Int32)
+	origGC, origFree := forceGC, freeOSMemory
+	forceGC = func() { gcCalls.Add(1) }
+	freeOSMemory = func() { freeCalls.Add(1) }
+	t.Cleanup(func() {
+		forceGC, freeOSMemory = origGC, origFree
+	})
+	return gcCalls, freeCalls
+}
+
+func TestDisableForcedGCSkipsGC(t *testing.T) {
+	const mb = 1024 * 1024
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb}
+	for _, disabled := range []bool{true, false} {
+		gcCalls, freeCalls := stubGC(t)
+		rm := NewResourceMonitor(ResourceConfig{
+			MemoryPercent:   10,
+			DisableForcedGC: disabled,
+		})
+		rm.AllocateMemory(8 * mb)
+
+		rm.ReleaseMemory(12, memInfo)
+		rm.CleanupAllResources()
+		if got := rm.AllocatedBytes(); got != 0 {
+			t.Fatalf("DisableForcedGC=%v: 清理后仍保留 %d 字节", disabled, got)
+		}
+		called := gcCalls.Load()+freeCalls.Load() > 0
+		if called == disabled {
+			t.Errorf("DisableForcedGC=%v: 强制GC调用 %d 次, FreeOSMemory 调用 %d 次",
+				disabled, gcCalls.Load(), freeCalls.Load())
+		}
+	}
+}
+
+// stubSetGCPercent 将 setGCPercent 替换为记录参数的函数（原值为 100），返回获取已记录参数的函数，测试结束时恢复
+func stubSetGCPercent(t *testing.T) func() []int {
+	t.Helper()
+	var mu sync.Mutex
+	var calls []int
+	current := 100
+	orig := setGCPercent
+	setGCPercent = func(percent int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, percent)
+		previous := current
+		current = percent
+		return previous
+	}
+	t.Cleanup(func() { setGCPercent = orig })
+	return func() []int {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]int(nil), calls...)
+	}
+}
+
+func TestGCPercentAppliedAndRestored(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ResourceConfig
+		want   string
+	}{
+		{"未设置", ResourceConfig{}, "[]"},
+		{"设置为 400", ResourceConfig{GCPercentOverride: true, GCPercent: 400}, "[400 100]"},
+		{"关闭GC", ResourceConfig{GCPercentOverride: true, GCPercent: -1}, "[-1 100]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := stubSetGCPercent(t)
+			buf := captureLog(t, LogInfo)
+			tt.config.Interval = MinInterval
+			rm := NewResourceMonitorWithMetrics(tt.config, newFakeMetrics(1<<30, 1<<30))
+
+			done := rm.Done()
+			go rm.Start()
+			waitFor(t, 5*time.Second, "开始调整", func() bool { return !rm.LastMeasurement().Time.IsZero() })
+			if got := calls(); tt.config.GCPercentOverride && (len(got) != 1 || got[0] != tt.config.GCPercent) {
+				t.Errorf("运行中 setGCPercent 调用 = %v, want [%d]", got, tt.config.GCPercent)
+			}
+			rm.Stop()
+			waitDone(t, done, 10*time.Second)
+
+			if got := fmt.Sprint(calls()); got != tt.want {
+				t.Errorf("setGCPercent 调用 = %s, want %s", got, tt.want)
+			}
+			if warned := strings.Contains(buf.String(), "已关闭自动垃圾回收"); warned != (tt.config.GCPercent < 0) {
+				t.Errorf("关闭GC的警告输出 = %v, want %v:\n%s", warned, tt.config.GCPercent < 0, buf)
+			}
+		})
+	}
+}
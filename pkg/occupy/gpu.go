@@ -0,0 +1,160 @@
+package occupy
+
+import (
+	"math"
+)
+
+// gpuChunkSize GPU显存每次分配的块大小
+const gpuChunkSize = 256 * 1024 * 1024
+
+// gpuDevice GPU显存的查询和分配接口，由 gpu_nvml.go（-tags gpu）或 gpu_other.go 提供
+type gpuDevice interface {
+	// name 设备名称
+	name() string
+	// memory 整个设备的显存已用量和总量（包括其他进程的占用）
+	memory() (used, total uint64, err error)
+	// alloc 分配 size 字节显存并写入，返回设备指针
+	alloc(size uint64) (uintptr, error)
+	// free 释放 alloc 返回的显存
+	free(ptr uintptr) error
+	// close 释放设备上下文
+	close() error
+}
+
+// gpuChunk 已分配的一块显存
+type gpuChunk struct {
+	ptr  uintptr
+	size uint64
+}
+
+// startGPU 设置了 GPUMemoryPercent 时打开GPU设备；没有GPU或NVML/CUDA不可用时输出日志并跳过GPU占用
+func (rm *ResourceMonitor) startGPU() {
+	if rm.Config.GPUMemoryPercent <= 0 {
+		return
+	}
+
+	rm.gpuMutex.Lock()
+	defer rm.gpuMutex.Unlock()
+
+	if rm.gpu != nil {
+		return
+	}
+	device, err := openGPU(rm.Config.GPUDevice)
+	if err != nil {
+		logWarnf("GPU不可用，跳过GPU显存占用: %v", err)
+		return
+	}
+	rm.gpu = device
+	logInfof("GPU显存占用: 设备 %d (%s)，目标 %.1f%%", rm.Config.GPUDevice, device.name(), rm.Config.GPUMemoryPercent)
+}
+
+// adjustGPUUsage 调整GPU显存占用：低于目标时按块分配，超出目标加容差时从最后分配的块开始释放
+func (rm *ResourceMonitor) adjustGPUUsage() {
+	rm.gpuMutex.Lock()
+	defer rm.gpuMutex.Unlock()
+
+	if rm.gpu == nil {
+		return
+	}
+	used, total, err := rm.gpu.memory()
+	if err != nil {
+		logErrorf("获取GPU显存信息失败: %v", err)
+		return
+	}
+	if total == 0 {
+		return
+	}
+
+	currentPercent := float64(used) / float64(total) * 100
+	target := rm.Config.GPUMemoryPercent
+	logDebugf("GPU显存使用率: %.1f%% (已用 %s / %s)", currentPercent, FormatBytes(used), FormatBytes(total))
+
+	if currentPercent < target {
+		bytes := uint64(math.Round((target - currentPercent) / 100 * float64(total)))
+		rm.allocateGPU(bytes)
+	} else if currentPercent > target+rm.tolerance() {
+		excess := uint64(math.Round((currentPercent - target) / 100 * float64(total)))
+		rm.releaseGPU(excess)
+	}
+}
+
+// allocateGPU 按块分配 bytes 字节显存，分配失败时停止本次分配（调用方需持有 gpuMutex）
+func (rm *ResourceMonitor) allocateGPU(bytes uint64) {
+	var allocated uint64
+	for allocated < bytes {
+		size := uint64(gpuChunkSize)
+		if bytes-allocated < size {
+			size = bytes - allocated
+		}
+		ptr, err := rm.gpu.alloc(size)
+		if err != nil {
+			logErrorf("分配GPU显存失败: %v", err)
+			break
+		}
+		rm.gpuChunks = append(rm.gpuChunks, gpuChunk{ptr: ptr, size: size})
+		allocated += size
+	}
+	if allocated > 0 {
+		logDebugf("分配GPU显存: %d bytes", allocated)
+	}
+}
+
+// releaseGPU 从最后分配的块开始释放，直到释放至少 bytes 字节或没有已分配的显存（调用方需持有 gpuMutex）
+func (rm *ResourceMonitor) releaseGPU(bytes uint64) {
+	var released uint64
+	for len(rm.gpuChunks) > 0 && released < bytes {
+		chunk := rm.gpuChunks[len(rm.gpuChunks)-1]
+		if err := rm.gpu.free(chunk.ptr); err != nil {
+			logErrorf("释放GPU显存失败: %v", err)
+		}
+		rm.gpuChunks = rm.gpuChunks[:len(rm.gpuChunks)-1]
+		released += chunk.size
+	}
+	if released > 0 {
+		logDebugf("释放GPU显存: %d bytes", released)
+	}
+}
+
+// cleanupGPU 释放所有GPU显存并关闭设备
+func (rm *ResourceMonitor) cleanupGPU() {
+	rm.gpuMutex.Lock()
+	defer rm.gpuMutex.Unlock()
+
+	if rm.gpu == nil {
+		return
+	}
+	total := rm.gpuAllocatedBytes()
+	rm.releaseGPU(total)
+	if err := rm.gpu.close(); err != nil {
+		logErrorf("关闭GPU设备失败: %v", err)
+	}
+	rm.gpu = nil
+	if total > 0 {
+		logInfof("清理GPU显存: %d bytes", total)
+	}
+}
+
+// gpuAllocatedBytes 已分配的GPU显存字节数（调用方需持有 gpuMutex）
+func (rm *ResourceMonitor) gpuAllocatedBytes() uint64 {
+	var total uint64
+	for _, chunk := range rm.gpuChunks {
+		total += chunk.size
+	}
+	return total
+}
+
+// GPUAllocatedBytes 获取当前已分配的GPU显存字节数
+func (rm *ResourceMonitor) GPUAllocatedBytes() uint64 {
+	rm.gpuMutex.Lock()
+	defer rm.gpuMutex.Unlock()
+
+	return rm.gpuAllocatedBytes()
+}
+
+// GPUAvailable 是否已打开GPU设备（设置了 GPUMemoryPercent 且 NVML/CUDA 可用）
+func (rm *ResourceMonitor) GPUAvailable() bool {
+	rm.gpuMutex.Lock()
+	defer rm.gpuMutex.Unlock()
+
+	return rm.gpu != nil
+}
@@ -0,0 +1,213 @@
+//go:build gpu && linux && cgo
+
+package occupy
+
+/*
+#cgo LDFLAGS: -ldl
+#include <dlfcn.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef unsigned long long CUdeviceptr;
+
+typedef struct {
+	unsigned long long total;
+	unsigned long long free;
+	unsigned long long used;
+} nvmlMemory_t;
+
+static void *libcuda;
+static void *libnvml;
+
+static int (*p_cuInit)(unsigned int);
+static int (*p_cuDeviceGet)(int *, int);
+static int (*p_cuDeviceGetPCIBusId)(char *, int, int);
+static int (*p_cuCtxCreate)(void **, unsigned int, int);
+static int (*p_cuCtxDestroy)(void *);
+static int (*p_cuCtxSetCurrent)(void *);
+static int (*p_cuMemAlloc)(CUdeviceptr *, size_t);
+static int (*p_cuMemFree)(CUdeviceptr);
+static int (*p_cuMemsetD8)(CUdeviceptr, unsigned char, size_t);
+
+static int (*p_nvmlInit)(void);
+static int (*p_nvmlShutdown)(void);
+static int (*p_nvmlDeviceGetHandleByPciBusId)(const char *, void **);
+static int (*p_nvmlDeviceGetName)(void *, char *, unsigned int);
+static int (*p_nvmlDeviceGetMemoryInfo)(void *, nvmlMemory_t *);
+
+// gpu_load 加载 libcuda 和 libnvidia-ml，返回 0 表示成功，1 表示缺少 CUDA 驱动库，2 表示缺少 NVML 库，3 表示缺少符号
+static int gpu_load(void) {
+	if (libcuda && libnvml) {
+		return 0;
+	}
+	libcuda = dlopen("libcuda.so.1", RTLD_NOW);
+	if (!libcuda) {
+		return 1;
+	}
+	libnvml = dlopen("libnvidia-ml.so.1", RTLD_NOW);
+	if (!libnvml) {
+		return 2;
+	}
+	p_cuInit = dlsym(libcuda, "cuInit");
+	p_cuDeviceGet = dlsym(libcuda, "cuDeviceGet");
+	p_cuDeviceGetPCIBusId = dlsym(libcuda, "cuDeviceGetPCIBusId");
+	p_cuCtxCreate = dlsym(libcuda, "cuCtxCreate_v2");
+	p_cuCtxDestroy = dlsym(libcuda, "cuCtxDestroy_v2");
+	p_cuCtxSetCurrent = dlsym(libcuda, "cuCtxSetCurrent");
+	p_cuMemAlloc = dlsym(libcuda, "cuMemAlloc_v2");
+	p_cuMemFree = dlsym(libcuda, "cuMemFree_v2");
+	p_cuMemsetD8 = dlsym(libcuda, "cuMemsetD8_v2");
+	p_nvmlInit = dlsym(libnvml, "nvmlInit_v2");
+	p_nvmlShutdown = dlsym(libnvml, "nvmlShutdown");
+	p_nvmlDeviceGetHandleByPciBusId = dlsym(libnvml, "nvmlDeviceGetHandleByPciBusId_v2");
+	p_nvmlDeviceGetName = dlsym(libnvml, "nvmlDeviceGetName");
+	p_nvmlDeviceGetMemoryInfo = dlsym(libnvml, "nvmlDeviceGetMemoryInfo");
+	if (!p_cuInit || !p_cuDeviceGet || !p_cuDeviceGetPCIBusId || !p_cuCtxCreate || !p_cuCtxDestroy ||
+		!p_cuCtxSetCurrent || !p_cuMemAlloc || !p_cuMemFree || !p_cuMemsetD8 || !p_nvmlInit ||
+		!p_nvmlShutdown || !p_nvmlDeviceGetHandleByPciBusId || !p_nvmlDeviceGetName || !p_nvmlDeviceGetMemoryInfo) {
+		return 3;
+	}
+	return 0;
+}
+
+static int gpu_cu_init(void) { return p_cuInit(0); }
+static int gpu_cu_device_get(int *dev, int index) { return p_cuDeviceGet(dev, index); }
+static int gpu_cu_pci_bus_id(char *buf, int len, int dev) { return p_cuDeviceGetPCIBusId(buf, len, dev); }
+static int gpu_cu_ctx_create(void **ctx, int dev) { return p_cuCtxCreate(ctx, 0, dev); }
+static int gpu_cu_ctx_destroy(void *ctx) { return p_cuCtxDestroy(ctx); }
+static int gpu_cu_ctx_set_current(void *ctx) { return p_cuCtxSetCurrent(ctx); }
+static int gpu_cu_mem_alloc(CUdeviceptr *ptr, size_t size) { return p_cuMemAlloc(ptr, size); }
+static int gpu_cu_mem_free(CUdeviceptr ptr) { return p_cuMemFree(ptr); }
+static int gpu_cu_memset(CUdeviceptr ptr, unsigned char value, size_t size) { return p_cuMemsetD8(ptr, value, size); }
+
+static int gpu_nvml_init(void) { return p_nvmlInit(); }
+static int gpu_nvml_shutdown(void) { return p_nvmlShutdown(); }
+static int gpu_nvml_handle(const char *busId, void **handle) { return p_nvmlDeviceGetHandleByPciBusId(busId, handle); }
+static int gpu_nvml_name(void *handle, char *buf, unsigned int len) { return p_nvmlDeviceGetName(handle, buf, len); }
+static int gpu_nvml_memory(void *handle, unsigned long long *used, unsigned long long *total) {
+	nvmlMemory_t info;
+	memset(&info, 0, sizeof(info));
+	int ret = p_nvmlDeviceGetMemoryInfo(handle, &info);
+	*used = info.used;
+	*total = info.total;
+	return ret;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// nvmlDevice 通过 NVML 查询显存、通过 CUDA 驱动 API 分配显存的GPU设备。
+// 两个库均在运行时 dlopen，没有安装驱动的主机上也可以编译和运行
+type nvmlDevice struct {
+	ctx        unsafe.Pointer
+	handle     unsafe.Pointer
+	deviceName string
+}
+
+// openGPU 打开第 index 个 CUDA 设备，并通过 PCI 总线号找到对应的 NVML 设备
+func openGPU(index int) (gpuDevice, error) {
+	switch C.gpu_load() {
+	case 1:
+		return nil, fmt.Errorf("未找到 CUDA 驱动库 libcuda.so.1（没有GPU或未安装NVIDIA驱动）")
+	case 2:
+		return nil, fmt.Errorf("未找到 NVML 库 libnvidia-ml.so.1")
+	case 3:
+		return nil, fmt.Errorf("CUDA 驱动库或 NVML 库版本过旧，缺少所需的函数")
+	}
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if ret := C.gpu_cu_init(); ret != 0 {
+		return nil, fmt.Errorf("cuInit 失败: 错误码 %d", int(ret))
+	}
+	var dev C.int
+	if ret := C.gpu_cu_device_get(&dev, C.int(index)); ret != 0 {
+		return nil, fmt.Errorf("没有编号为 %d 的GPU设备: 错误码 %d", index, int(ret))
+	}
+	busID := make([]byte, 32)
+	if ret := C.gpu_cu_pci_bus_id((*C.char)(unsafe.Pointer(&busID[0])), C.int(len(busID)), dev); ret != 0 {
+		return nil, fmt.Errorf("获取GPU设备 PCI 总线号失败: 错误码 %d", int(ret))
+	}
+
+	if ret := C.gpu_nvml_init(); ret != 0 {
+		return nil, fmt.Errorf("nvmlInit 失败: 错误码 %d", int(ret))
+	}
+	var handle unsafe.Pointer
+	if ret := C.gpu_nvml_handle((*C.char)(unsafe.Pointer(&busID[0])), &handle); ret != 0 {
+		C.gpu_nvml_shutdown()
+		return nil, fmt.Errorf("NVML 中找不到GPU设备 %d: 错误码 %d", index, int(ret))
+	}
+	nameBuf := make([]byte, 96)
+	deviceName := "未知设备"
+	if ret := C.gpu_nvml_name(handle, (*C.char)(unsafe.Pointer(&nameBuf[0])), C.uint(len(nameBuf))); ret == 0 {
+		deviceName = C.GoString((*C.char)(unsafe.Pointer(&nameBuf[0])))
+	}
+
+	var ctx unsafe.Pointer
+	if ret := C.gpu_cu_ctx_create(&ctx, dev); ret != 0 {
+		C.gpu_nvml_shutdown()
+		return nil, fmt.Errorf("创建 CUDA 上下文失败: 错误码 %d", int(ret))
+	}
+	return &nvmlDevice{ctx: ctx, handle: handle, deviceName: deviceName}, nil
+}
+
+func (d *nvmlDevice) name() string {
+	return d.deviceName
+}
+
+func (d *nvmlDevice) memory() (used, total uint64, err error) {
+	var u, t C.ulonglong
+	if ret := C.gpu_nvml_memory(d.handle, &u, &t); ret != 0 {
+		return 0, 0, fmt.Errorf("nvmlDeviceGetMemoryInfo 失败: 错误码 %d", int(ret))
+	}
+	return uint64(u), uint64(t), nil
+}
+
+// alloc 分配显存并写入，避免驱动延迟提交而未实际占用
+func (d *nvmlDevice) alloc(size uint64) (uintptr, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if ret := C.gpu_cu_ctx_set_current(d.ctx); ret != 0 {
+		return 0, fmt.Errorf("切换 CUDA 上下文失败: 错误码 %d", int(ret))
+	}
+	var ptr C.CUdeviceptr
+	if ret := C.gpu_cu_mem_alloc(&ptr, C.size_t(size)); ret != 0 {
+		return 0, fmt.Errorf("cuMemAlloc %s 失败: 错误码 %d", FormatBytes(size), int(ret))
+	}
+	if ret := C.gpu_cu_memset(ptr, 0xA5, C.size_t(size)); ret != 0 {
+		C.gpu_cu_mem_free(ptr)
+		return 0, fmt.Errorf("写入GPU显存失败: 错误码 %d", int(ret))
+	}
+	return uintptr(ptr), nil
+}
+
+func (d *nvmlDevice) free(ptr uintptr) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if ret := C.gpu_cu_ctx_set_current(d.ctx); ret != 0 {
+		return fmt.Errorf("切换 CUDA 上下文失败: 错误码 %d", int(ret))
+	}
+	if ret := C.gpu_cu_mem_free(C.CUdeviceptr(ptr)); ret != 0 {
+		return fmt.Errorf("cuMemFree 失败: 错误码 %d", int(ret))
+	}
+	return nil
+}
+
+func (d *nvmlDevice) close() error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	C.gpu_nvml_shutdown()
+	if ret := C.gpu_cu_ctx_destroy(d.ctx); ret != 0 {
+		return fmt.Errorf("销毁 CUDA 上下文失败: 错误码 %d", int(ret))
+	}
+	return nil
+}
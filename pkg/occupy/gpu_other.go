@@ -0,0 +1,12 @@
+//go:build !gpu || !linux || !cgo
+
+package occupy
+
+import (
+	"errors"
+)
+
+// openGPU 未启用GPU支持
+func openGPU(index int) (gpuDevice, error) {
+	return nil, errors.New("未编译GPU支持，需在 Linux 上启用 cgo 并使用 -tags gpu 编译")
+}
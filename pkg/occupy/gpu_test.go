@@ -0,0 +1,33 @@
+package occupy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGPUUnavailableIsReportedAndSkipped(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		GPUMemoryPercent: 50,
+		Interval:         MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+
+	rm.startGPU()
+	if rm.GPUAvailable() {
+		rm.cleanupGPU()
+		t.Skip("本机GPU可用，跳过GPU不可用的测试")
+	}
+	if !strings.Contains(buf.String(), "GPU不可用，跳过GPU显存占用") {
+		t.Fatalf("GPU不可用时未输出日志:\n%s", buf)
+	}
+
+	// 没有GPU时调整和清理都不做任何事
+	rm.adjustGPUUsage()
+	if got := rm.GPUAllocatedBytes(); got != 0 {
+		t.Errorf("GPUAllocatedBytes = %d, want 0", got)
+	}
+	rm.cleanupGPU()
+	if strings.Contains(buf.String(), "GPU显存失败") {
+		t.Errorf("GPU不可用时输出了显存错误:\n%s", buf)
+	}
+}
@@ -0,0 +1,181 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go-occupy/pkg/occupypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 监控器状态
+const (
+	StatePending = "pending"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateStopped = "stopped"
+)
+
+// GRPCService 通过 gRPC 控制单个资源监控器，接口定义见 proto/occupy.proto
+type GRPCService struct {
+	occupypb.UnimplementedOccupyServer
+
+	monitor *ResourceMonitor
+	mu      sync.Mutex
+	started bool
+}
+
+// NewGRPCService 创建控制指定监控器的 gRPC 服务
+func NewGRPCService(monitor *ResourceMonitor) *GRPCService {
+	return &GRPCService{monitor: monitor}
+}
+
+// Register 将服务注册到 gRPC 服务器
+func (s *GRPCService) Register(server *grpc.Server) {
+	occupypb.RegisterOccupyServer(server, s)
+}
+
+// StartMonitor 在后台启动监控，正在运行时返回 false；上一次运行已停止时重新启动
+func (s *GRPCService) StartMonitor() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.monitor.beginRun() {
+		return false
+	}
+	s.started = true
+	go s.monitor.run(context.Background())
+	return true
+}
+
+// state 获取监控器当前状态
+func (s *GRPCService) state() string {
+	select {
+	case <-s.monitor.Done():
+		return StateStopped
+	default:
+	}
+
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+
+	switch {
+	case !started:
+		return StatePending
+	case s.monitor.Paused():
+		return StatePaused
+	default:
+		return StateRunning
+	}
+}
+
+// status 生成状态响应
+func (s *GRPCService) status() *occupypb.StatusResponse {
+	t := s.monitor.CurrentTargets()
+	resp := &occupypb.StatusResponse{
+		State: s.state(),
+		Targets: &occupypb.Targets{
+			MemoryPercent: t.MemoryPercent,
+			CpuPercent:    t.CPUPercent,
+			DiskPercents:  t.DiskPercents,
+		},
+		AllocatedBytes: s.monitor.AllocatedBytes(),
+		TempFileBytes:  s.monitor.TempFileBytes(),
+	}
+
+	if m := s.monitor.LastMeasurement(); !m.Time.IsZero() {
+		resp.Measurement = &occupypb.Measurement{
+			TimeUnixNano:  m.Time.UnixNano(),
+			MemoryPercent: m.MemoryPercent,
+			CpuPercent:    m.CPUPercent,
+			DiskPercents:  m.DiskPercents,
+		}
+	}
+	return resp
+}
+
+// Start 启动监控，已启动时直接返回当前状态，已停止时重新启动
+func (s *GRPCService) Start(ctx context.Context, req *occupypb.StartRequest) (*occupypb.StatusResponse, error) {
+	if s.StartMonitor() {
+		logInfof("gRPC: 启动监控")
+	}
+	return s.status(), nil
+}
+
+// Stop 停止监控并等待资源清理完成
+func (s *GRPCService) Stop(ctx context.Context, req *occupypb.StopRequest) (*occupypb.StatusResponse, error) {
+	if s.state() == StatePending {
+		return nil, status.Error(codes.FailedPrecondition, "监控尚未启动")
+	}
+	logInfof("gRPC: 停止监控")
+	s.monitor.Stop()
+	return s.status(), nil
+}
+
+// Retarget 修改资源目标，未设置的字段保持当前目标不变
+func (s *GRPCService) Retarget(ctx context.Context, req *occupypb.RetargetRequest) (*occupypb.StatusResponse, error) {
+	t := s.monitor.configuredTargets()
+	if req.MemoryPercent != nil {
+		t.MemoryPercent = req.GetMemoryPercent()
+	}
+	if req.CpuPercent != nil {
+		t.CPUPercent = req.GetCpuPercent()
+	}
+	t.DiskPercents = req.GetDiskPercents()
+
+	if err := s.monitor.Retarget(t); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return s.status(), nil
+}
+
+// Status 获取当前状态
+func (s *GRPCService) Status(ctx context.Context, req *occupypb.StatusRequest) (*occupypb.StatusResponse, error) {
+	return s.status(), nil
+}
+
+// Pause 暂停调整，保持已占用的资源不变
+func (s *GRPCService) Pause(ctx context.Context, req *occupypb.PauseRequest) (*occupypb.StatusResponse, error) {
+	s.monitor.Pause()
+	return s.status(), nil
+}
+
+// Resume 恢复调整
+func (s *GRPCService) Resume(ctx context.Context, req *occupypb.ResumeRequest) (*occupypb.StatusResponse, error) {
+	s.monitor.Resume()
+	return s.status(), nil
+}
+
+// tempFiles 生成临时文件列表响应
+func (s *GRPCService) tempFiles() *occupypb.ListTempFilesResponse {
+	files := s.monitor.ListTempFiles()
+	resp := &occupypb.ListTempFilesResponse{Files: make([]*occupypb.TempFile, 0, len(files))}
+	for _, file := range files {
+		resp.Files = append(resp.Files, &occupypb.TempFile{Dir: file.Dir, Path: file.Path, Size: file.Size})
+	}
+	return resp
+}
+
+// ListTempFiles 列出当前持有的临时文件
+func (s *GRPCService) ListTempFiles(ctx context.Context, req *occupypb.ListTempFilesRequest) (*occupypb.ListTempFilesResponse, error) {
+	return s.tempFiles(), nil
+}
+
+// DropTempFile 删除一个当前持有的临时文件，不是当前持有的文件时返回 NotFound
+func (s *GRPCService) DropTempFile(ctx context.Context, req *occupypb.DropTempFileRequest) (*occupypb.ListTempFilesResponse, error) {
+	if req.GetPath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "缺少参数 path")
+	}
+	if err := s.monitor.DropTempFile(req.GetPath()); err != nil {
+		if errors.Is(err, ErrTempFileNotTracked) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return s.tempFiles(), nil
+}
@@ -0,0 +1,123 @@
+package occupy
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"go-occupy/pkg/occupypb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestGRPCClient 通过内存连接启动 gRPC 服务并返回客户端
+func newTestGRPCClient(t *testing.T, monitor *ResourceMonitor) occupypb.OccupyClient {
+	t.Helper()
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	NewGRPCService(monitor).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("连接 gRPC 服务失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return occupypb.NewOccupyClient(conn)
+}
+
+func TestGRPCStatusReflectsTargets(t *testing.T) {
+	dir := t.TempDir()
+	monitor := NewResourceMonitor(ResourceConfig{
+		MemoryPercent: 35,
+		CPUPercent:    20,
+		DiskTargets:   []DiskTarget{{Path: dir, Percent: 5}},
+		Interval:      MinInterval,
+	})
+	client := newTestGRPCClient(t, monitor)
+	ctx := context.Background()
+
+	resp, err := client.Status(ctx, &occupypb.StatusRequest{})
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if resp.GetState() != StatePending {
+		t.Errorf("State = %q, want %q", resp.GetState(), StatePending)
+	}
+	targets := resp.GetTargets()
+	if targets.GetMemoryPercent() != 35 || targets.GetCpuPercent() != 20 {
+		t.Errorf("目标 = 内存 %v, CPU %v, want 35, 20", targets.GetMemoryPercent(), targets.GetCpuPercent())
+	}
+	if disks := targets.GetDiskPercents(); len(disks) != 1 || disks[0] != 5 {
+		t.Errorf("磁盘目标 = %v, want [5]", disks)
+	}
+
+	memory := 15.0
+	if _, err := client.Retarget(ctx, &occupypb.RetargetRequest{MemoryPercent: &memory}); err != nil {
+		t.Fatalf("Retarget: %v", err)
+	}
+	resp, err = client.Status(ctx, &occupypb.StatusRequest{})
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if got := resp.GetTargets().GetMemoryPercent(); got != 15 {
+		t.Errorf("修改后内存目标 = %v, want 15", got)
+	}
+	if got := resp.GetTargets().GetCpuPercent(); got != 20 {
+		t.Errorf("未设置的CPU目标 = %v, want 保持 20", got)
+	}
+}
+
+func TestGRPCListAndDropTempFiles(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	monitor := NewResourceMonitor(ResourceConfig{DiskTargets: []DiskTarget{{Path: dir, Percent: 5}}})
+	t.Cleanup(monitor.CleanupAllResources)
+	for i := 0; i < 2; i++ {
+		if err := monitor.createTempFiles(dir, mb); err != nil {
+			t.Fatalf("createTempFiles: %v", err)
+		}
+	}
+	client := newTestGRPCClient(t, monitor)
+	ctx := context.Background()
+
+	resp, err := client.ListTempFiles(ctx, &occupypb.ListTempFilesRequest{})
+	if err != nil {
+		t.Fatalf("ListTempFiles: %v", err)
+	}
+	files := resp.GetFiles()
+	if len(files) != 2 || files[0].GetDir() != dir || files[0].GetSize() != mb {
+		t.Fatalf("临时文件 = %v, want 2 个 %s 中 %d 字节的文件", files, dir, mb)
+	}
+
+	dropped := files[0].GetPath()
+	resp, err = client.DropTempFile(ctx, &occupypb.DropTempFileRequest{Path: dropped})
+	if err != nil {
+		t.Fatalf("DropTempFile: %v", err)
+	}
+	if got := resp.GetFiles(); len(got) != 1 || got[0].GetPath() != files[1].GetPath() {
+		t.Errorf("删除后临时文件 = %v, want 仅 %s", got, files[1].GetPath())
+	}
+	if _, err := os.Stat(dropped); !os.IsNotExist(err) {
+		t.Errorf("删除后文件仍存在: %v", err)
+	}
+
+	_, err = client.DropTempFile(ctx, &occupypb.DropTempFileRequest{Path: dropped})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("删除未持有的文件 = %v, want NotFound", err)
+	}
+	_, err = client.DropTempFile(ctx, &occupypb.DropTempFileRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("缺少 path = %v, want InvalidArgument", err)
+	}
+}
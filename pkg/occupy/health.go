@@ -0,0 +1,57 @@
+package occupy
+
+import "net/http"
+
+// HealthStatus 健康检查接口返回的内容
+type HealthStatus struct {
+	Status string `json:"status"`
+	Memory *bool  `json:"memory,omitempty"`
+	CPU    *bool  `json:"cpu,omitempty"`
+	Disk   *bool  `json:"disk,omitempty"`
+}
+
+// HealthHandler 返回健康检查的HTTP处理器：
+// /healthz 在监控循环运行期间返回200，/readyz 在最近一次测量的使用率均达到目标（容差内）后返回200，
+// 否则均返回503；/metrics 以 Prometheus 文本格式输出指标
+func (rm *ResourceMonitor) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", rm.handleHealthz)
+	mux.HandleFunc("/readyz", rm.handleReadyz)
+	mux.HandleFunc("/metrics", rm.handleMetrics)
+	return mux
+}
+
+// handleHealthz 监控循环是否正在运行
+func (rm *ResourceMonitor) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+
+	if !rm.Running() {
+		writeJSON(w, http.StatusServiceUnavailable, HealthStatus{Status: "stopped"})
+		return
+	}
+	writeJSON(w, http.StatusOK, HealthStatus{Status: "running"})
+}
+
+// handleReadyz 各资源是否已达到目标
+func (rm *ResourceMonitor) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+
+	if !rm.Running() {
+		writeJSON(w, http.StatusServiceUnavailable, HealthStatus{Status: "stopped"})
+		return
+	}
+	memory, cpu, disk := rm.TargetsMet()
+	status := HealthStatus{Status: "ready", Memory: &memory, CPU: &cpu, Disk: &disk}
+	if !memory || !cpu || !disk {
+		status.Status = "not_ready"
+		writeJSON(w, http.StatusServiceUnavailable, status)
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
@@ -0,0 +1,61 @@
+package occupy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthAndReadinessEndpoints(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	// 指标不随分配变化，内存使用率始终为 0%，目标 50% 无法达到
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:  50,
+		MaxMemoryBytes: mb,
+		Interval:       MinInterval,
+	}, newFakeMetrics(100*mb, 100*mb))
+	ts := httptest.NewServer(rm.HealthHandler())
+	defer ts.Close()
+
+	var status HealthStatus
+	doJSON(t, http.MethodGet, ts.URL+"/healthz", "", http.StatusServiceUnavailable, &status)
+	if status.Status != "stopped" {
+		t.Errorf("启动前 /healthz status = %q, want stopped", status.Status)
+	}
+	doJSON(t, http.MethodGet, ts.URL+"/readyz", "", http.StatusServiceUnavailable, nil)
+
+	done := rm.Done()
+	go rm.Start()
+	defer func() {
+		rm.Stop()
+		<-done
+	}()
+	waitFor(t, 3*time.Second, "完成第一次测量", func() bool {
+		return rm.Running() && !rm.LastMeasurement().Time.IsZero()
+	})
+
+	doJSON(t, http.MethodGet, ts.URL+"/healthz", "", http.StatusOK, &status)
+	if status.Status != "running" {
+		t.Errorf("运行中 /healthz status = %q, want running", status.Status)
+	}
+	status = HealthStatus{}
+	doJSON(t, http.MethodGet, ts.URL+"/readyz", "", http.StatusServiceUnavailable, &status)
+	if status.Status != "not_ready" || status.Memory == nil || *status.Memory {
+		t.Errorf("未达到目标时 /readyz = %+v, want not_ready 且内存未达到", status)
+	}
+
+	if err := rm.Retarget(Targets{}); err != nil {
+		t.Fatalf("Retarget: %v", err)
+	}
+	waitFor(t, 3*time.Second, "按新目标完成测量", func() bool {
+		memory, cpu, disk := rm.TargetsMet()
+		return memory && cpu && disk
+	})
+	status = HealthStatus{}
+	doJSON(t, http.MethodGet, ts.URL+"/readyz", "", http.StatusOK, &status)
+	if status.Status != "ready" || !*status.Memory || !*status.CPU || !*status.Disk {
+		t.Errorf("达到目标后 /readyz = %+v, want ready", status)
+	}
+}
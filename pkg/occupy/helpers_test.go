@@ -0,0 +1,18 @@
+package occupy
+
+import (
+	"testing"
+	"time"
+)
+
+// waitFor 在 timeout 内轮询直到 cond 成立，超时时测试失败
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待超时: %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
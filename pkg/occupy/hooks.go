@@ -0,0 +1,19 @@
+package occupy
+
+// notifyStarted 本次运行首次完成调整后调用 OnStarted（仅由监控协程调用）
+func (rm *ResourceMonitor) notifyStarted() {
+	if rm.started {
+		return
+	}
+	rm.started = true
+	if rm.OnStarted != nil {
+		rm.OnStarted()
+	}
+}
+
+// notifyStopped 资源清理完成后调用 OnStopped
+func (rm *ResourceMonitor) notifyStopped() {
+	if rm.OnStopped != nil {
+		rm.OnStopped()
+	}
+}
@@ -0,0 +1,53 @@
+package occupy
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHooksFireInOrder(t *testing.T) {
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:  20,
+		Interval:       100 * time.Millisecond,
+		MaxMemoryBytes: 4 * 1024 * 1024,
+	}, newFakeMetrics(1<<30, 1<<40))
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	started := make(chan struct{})
+	rm.OnStarted = func() {
+		// 首次调整已完成，此时应已分配内存
+		if rm.AllocatedBytes() == 0 {
+			t.Error("OnStarted 调用时尚未分配内存")
+		}
+		record("started")
+		close(started)
+	}
+	rm.OnStopped = func() {
+		if got := rm.AllocatedBytes(); got != 0 {
+			t.Errorf("OnStopped 调用时仍保留 %d 字节内存", got)
+		}
+		record("stopped")
+	}
+
+	go rm.Start()
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnStarted 未被调用")
+	}
+	rm.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || events[0] != "started" || events[1] != "stopped" {
+		t.Fatalf("事件顺序 = %v, want [started stopped]", events)
+	}
+}
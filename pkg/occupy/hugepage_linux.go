@@ -0,0 +1,27 @@
+//go:build linux
+
+package occupy
+
+import (
+	"syscall"
+)
+
+const (
+	// hugePageSize 默认大页大小（2MB）
+	hugePageSize = 2 * 1024 * 1024
+	// mapHugeShift/mapHuge2MB 用于在 mmap 标志中指定大页大小
+	mapHugeShift = 26
+	mapHuge2MB   = 21 << mapHugeShift
+)
+
+// mmapHugePages 使用 MAP_HUGETLB 分配2MB大页，映射长度向上取整到大页大小。
+// 返回的切片长度为 size、容量为完整映射长度，释放时需按容量 Munmap（见 mmapAllocator.free）
+func mmapHugePages(size uint64) ([]byte, error) {
+	length := (size + hugePageSize - 1) / hugePageSize * hugePageSize
+	mapping, err := syscall.Mmap(-1, 0, int(length), syscall.PROT_READ|syscall.PROT_WRITE,
+		syscall.MAP_ANON|syscall.MAP_PRIVATE|syscall.MAP_HUGETLB|mapHuge2MB)
+	if err != nil {
+		return nil, err
+	}
+	return mapping[:size], nil
+}
@@ -0,0 +1,89 @@
+//go:build linux
+
+package occupy
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// hugePagesFree 读取 /proc/meminfo 中的 HugePages_Free
+func hugePagesFree(t *testing.T) int {
+	t.Helper()
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		t.Skipf("无法读取 /proc/meminfo: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "HugePages_Free:" {
+			free, err := strconv.Atoi(fields[1])
+			if err != nil {
+				t.Fatalf("解析 HugePages_Free 失败: %v", err)
+			}
+			return free
+		}
+	}
+	t.Skip("/proc/meminfo 中没有 HugePages_Free")
+	return 0
+}
+
+func TestHugePageChunkNotAlignedIsFreed(t *testing.T) {
+	before := hugePagesFree(t)
+	size := uint64(hugePageSize + 4096)
+
+	chunk, err := mmapHugePages(size)
+	if err != nil {
+		t.Skipf("大页不可用: %v", err)
+	}
+	if uint64(len(chunk)) != size {
+		t.Fatalf("len = %d, want %d", len(chunk), size)
+	}
+	if cap(chunk) != 2*hugePageSize {
+		t.Fatalf("cap = %d, want %d", cap(chunk), 2*hugePageSize)
+	}
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+	if used := hugePagesFree(t); used != before-2 {
+		t.Fatalf("HugePages_Free after alloc = %d, want %d", used, before-2)
+	}
+
+	allocator := &mmapAllocator{hugePages: true}
+	if err := allocator.free(chunk); err != nil {
+		t.Fatalf("free: %v", err)
+	}
+	if after := hugePagesFree(t); after != before {
+		t.Fatalf("HugePages_Free after free = %d, want %d", after, before)
+	}
+}
+
+func TestHugePageAllocatorUsesOrReportsHugePages(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	allocator := &mmapAllocator{hugePages: true}
+	chunk, err := allocator.alloc(hugePageSize)
+	if err != nil {
+		t.Fatalf("alloc: %v", err)
+	}
+	defer allocator.free(chunk)
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+
+	if allocator.hugePagesUnavailable {
+		if !strings.Contains(buf.String(), "大页不可用，回退到普通页") {
+			t.Fatalf("大页不可用时未输出日志:\n%s", buf)
+		}
+		t.Log("大页不可用，已回退到普通页")
+		return
+	}
+	if cap(chunk)%hugePageSize != 0 {
+		t.Fatalf("大页映射容量 %d 不是大页大小的整数倍", cap(chunk))
+	}
+}
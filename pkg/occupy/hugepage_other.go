@@ -0,0 +1,12 @@
+//go:build unix && !linux
+
+package occupy
+
+import (
+	"errors"
+)
+
+// mmapHugePages 当前平台不支持 MAP_HUGETLB
+func mmapHugePages(size uint64) ([]byte, error) {
+	return nil, errors.New("当前平台不支持大页")
+}
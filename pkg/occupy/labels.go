@@ -0,0 +1,93 @@
+package occupy
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// labelKeyPattern 标签名格式，与 Prometheus 标签名相同，便于集中汇总时直接作为维度使用
+var labelKeyPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseLabel 解析 KEY=VALUE 格式的标签
+func ParseLabel(value string) (string, string, error) {
+	key, labelValue, ok := strings.Cut(value, "=")
+	if !ok {
+		return "", "", fmt.Errorf("标签格式错误: %q (应为 KEY=VALUE)", value)
+	}
+	if !labelKeyPattern.MatchString(key) {
+		return "", "", fmt.Errorf("标签名只能包含字母、数字和下划线且不能以数字开头: %q", key)
+	}
+	return key, labelValue, nil
+}
+
+// ParseLabels 解析多个 KEY=VALUE 格式的标签，同一标签名不能重复
+func ParseLabels(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(values))
+	for _, value := range values {
+		key, labelValue, err := ParseLabel(value)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := labels[key]; ok {
+			return nil, fmt.Errorf("标签 %s 重复指定", key)
+		}
+		labels[key] = labelValue
+	}
+	return labels, nil
+}
+
+// FormatLabels 按标签名排序格式化为 "k1=v1 k2=v2"，值包含空白、引号或为空时加引号
+func FormatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := labels[key]
+		if value == "" || strings.ContainsAny(value, " \t\n\"=") {
+			value = strconv.Quote(value)
+		}
+		parts = append(parts, key+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// logLabels SetLogLabels 设置的标签，JSON格式的日志将其作为 labels 字段输出
+var logLabels atomic.Pointer[map[string]string]
+
+// currentLogLabels 获取 SetLogLabels 设置的标签
+func currentLogLabels() map[string]string {
+	if labels := logLabels.Load(); labels != nil {
+		return *labels
+	}
+	return nil
+}
+
+// SetLogLabels 为每一行日志添加标签，便于集中收集多个实例的日志后区分来源：文本格式下作为前缀
+// （位于时间之后），JSON格式下作为 labels 字段（见 SetLogFormat）；labels 为空时清除标签
+func SetLogLabels(labels map[string]string) {
+	copied := make(map[string]string, len(labels))
+	for key, value := range labels {
+		copied[key] = value
+	}
+	logLabels.Store(&copied)
+
+	if _, isJSON := log.Writer().(*jsonLogWriter); isJSON || len(labels) == 0 {
+		log.SetPrefix("")
+		log.SetFlags(log.Flags() &^ log.Lmsgprefix)
+		return
+	}
+	log.SetPrefix("[" + FormatLabels(labels) + "] ")
+	log.SetFlags(log.Flags() | log.Lmsgprefix)
+}
@@ -0,0 +1,126 @@
+package occupy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLabelsAppearInLogsAndSummary(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	labels, err := ParseLabels([]string{"run=a b", "env=ci"})
+	if err != nil {
+		t.Fatalf("ParseLabels: %v", err)
+	}
+	SetLogLabels(labels)
+	t.Cleanup(func() { SetLogLabels(nil) })
+
+	logInfof("测试日志")
+	if want := `[env=ci run="a b"] 测试日志`; !strings.Contains(buf.String(), want) {
+		t.Errorf("日志 = %q, want 包含 %q", buf.String(), want)
+	}
+
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Labels: labels, Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+	var out bytes.Buffer
+	if err := rm.Summary().WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var summary struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &summary); err != nil {
+		t.Fatalf("解析运行汇总失败: %v", err)
+	}
+	if summary.Labels["run"] != "a b" || summary.Labels["env"] != "ci" {
+		t.Errorf("运行汇总的标签 = %v, want run=a b, env=ci", summary.Labels)
+	}
+	if got := rm.Result().Labels; got["run"] != "a b" {
+		t.Errorf("运行结果的标签 = %v, want run=a b", got)
+	}
+
+	SetLogLabels(nil)
+	logInfof("无标签")
+	if got := buf.String(); !strings.HasSuffix(got, "\n无标签\n") {
+		t.Errorf("清除标签后日志 = %q, want 最后一行无前缀", got)
+	}
+}
+
+func TestLabelsInJSONLogsAndMetrics(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	labels := map[string]string{"run": "nightly", "host": `a"b`}
+	SetLogFormat(LogFormatJSON)
+	SetLogLabels(labels)
+	t.Cleanup(func() {
+		SetLogFormat(LogFormatText)
+		SetLogLabels(nil)
+	})
+
+	logWarnf("磁盘接近写满")
+	log.Printf("来自标准库 log")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("JSON日志 = %q, want 2 行", buf.String())
+	}
+	for i, want := range []struct{ level, msg string }{
+		{"warn", "磁盘接近写满"},
+		{"info", "来自标准库 log"},
+	} {
+		var entry jsonLogEntry
+		if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+			t.Fatalf("第 %d 行不是JSON: %q: %v", i+1, lines[i], err)
+		}
+		if entry.Level != want.level || entry.Msg != want.msg || entry.Time.IsZero() {
+			t.Errorf("第 %d 行 = %+v, want level=%s msg=%s", i+1, entry, want.level, want.msg)
+		}
+		if !reflect.DeepEqual(entry.Labels, labels) {
+			t.Errorf("第 %d 行的标签 = %v, want %v", i+1, entry.Labels, labels)
+		}
+	}
+
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Labels: labels, Interval: MinInterval}, newFakeMetrics(1<<30, 1<<40))
+	rm.recordMeasurement(Measurement{Time: time.Now(), MemoryPercent: 12.5, DiskPercents: []float64{40}})
+	rm.recordTargets(Targets{MemoryPercent: 20, DiskPercents: []float64{50}})
+	ts := httptest.NewServer(rm.HealthHandler())
+	defer ts.Close()
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := string(body)
+	for _, want := range []string{
+		`go_occupy_up{host="a\"b",run="nightly"} 0`,
+		`go_occupy_memory_target_percent{host="a\"b",run="nightly"} 20`,
+		`go_occupy_memory_used_percent{host="a\"b",run="nightly"} 12.5`,
+		`go_occupy_disk_used_percent{disk="0",host="a\"b",run="nightly"} 40`,
+		`go_occupy_allocated_bytes{host="a\"b",run="nightly"} 0`,
+	} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("/metrics 缺少 %q:\n%s", want, metrics)
+		}
+	}
+}
+
+func TestParseLabelsRejectsInvalid(t *testing.T) {
+	for _, values := range [][]string{
+		{"novalue"},
+		{"1key=v"},
+		{"bad-key=v"},
+		{"k=a", "k=b"},
+	} {
+		if _, err := ParseLabels(values); err == nil {
+			t.Errorf("ParseLabels(%q) 未返回错误", values)
+		}
+	}
+}
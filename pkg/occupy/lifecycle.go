@@ -0,0 +1,109 @@
+package occupy
+
+import (
+	"sync"
+	"time"
+)
+
+// beginRun 开始一次运行：已在运行时返回 false；上一次运行已结束时重置停止通道和运行状态，
+// 使同一个监控器可以在 Stop 之后再次 Start
+func (rm *ResourceMonitor) beginRun() bool {
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
+	if rm.running {
+		return false
+	}
+	if rm.runs > 0 {
+		rm.resetRun()
+	}
+	rm.runs++
+	rm.running = true
+	return true
+}
+
+// endRun 结束本次运行：等待后台采样协程退出后关闭 cleanupDone
+func (rm *ResourceMonitor) endRun() {
+	rm.cpuSamplerWg.Wait()
+	rm.restoreGCPercent()
+	rm.markRunEnd()
+
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
+	rm.running = false
+	close(rm.cleanupDone)
+}
+
+// Running 监控是否正在运行（已 Start 且尚未完成清理）
+func (rm *ResourceMonitor) Running() bool {
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
+	return rm.running
+}
+
+// resetRun 重置上一次运行留下的状态（调用方需持有 lifecycleMutex，且上一次运行已结束）
+func (rm *ResourceMonitor) resetRun() {
+	rm.stop = make(chan bool)
+	rm.stopOnce = sync.Once{}
+	rm.cleanupDone = make(chan bool)
+
+	rm.errMutex.Lock()
+	rm.err = nil
+	rm.errMutex.Unlock()
+	rm.started = false
+
+	rm.cpuLoadMutex.Lock()
+	rm.targetCPUWorkers = 0
+	rm.targetCPULoad = 0
+	rm.cpuStoppedAt = time.Time{}
+	rm.cpuSuspended = false
+	rm.suspendedCPULoad = 0
+	rm.cpuLoadMutex.Unlock()
+
+	rm.memoryMutex.Lock()
+	rm.memoryCapped = false
+	rm.memoryVerifyErrors = 0
+	rm.releasedSinceFree = 0
+	rm.memoryMutex.Unlock()
+
+	rm.diskMutex.Lock()
+	rm.diskCapped = false
+	rm.cleanupErrors = 0
+	rm.diskMutex.Unlock()
+
+	rm.measurementMutex.Lock()
+	rm.lastMeasurement = Measurement{}
+	rm.lastTargets = nil
+	rm.levelsStart = time.Time{}
+	rm.levelsEnd = time.Time{}
+	rm.memoryLevels = levelReservoir{}
+	rm.cpuLevels = levelReservoir{}
+	rm.diskLevels = nil
+	rm.runStart = time.Time{}
+	rm.runEnd = time.Time{}
+	rm.convergedAt = time.Time{}
+	rm.measurementMutex.Unlock()
+
+	rm.retargetMutex.Lock()
+	rm.paused = false
+	rm.retargetMutex.Unlock()
+
+	// 以下状态仅由监控协程访问，上一次运行已结束
+	rm.metricFailures = 0
+	rm.skipTicks = 0
+	rm.watchdogPaused = false
+	rm.watchdogTrips = 0
+	rm.diskFailures = nil
+	rm.diskDisabled = nil
+	rm.startupBegan = time.Time{}
+	rm.startupStages = 0
+	rm.activeTargets = nil
+	rm.waveStart = time.Time{}
+	rm.progressShown = false
+	rm.burstStart = time.Time{}
+	rm.bursting = false
+	rm.mirrorProcess = nil
+	rm.mirrorLost = false
+}
@@ -0,0 +1,112 @@
+package occupy
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// newLifecycleTestMonitor 创建占用少量内存和磁盘、不占用CPU的监控器，临时文件写入返回的目录
+func newLifecycleTestMonitor(t *testing.T) (*ResourceMonitor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GO_OCCUPY_TEMP_DIR", dir)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:  20,
+		DiskPercent:    1,
+		Interval:       MinInterval,
+		MaxMemoryBytes: 16 * 1024 * 1024,
+	}, newFakeMetrics(1<<30, 100*1024*1024))
+	return rm, dir
+}
+
+// waitDone 等待本次运行结束并完成清理
+func waitDone(t *testing.T, done <-chan bool, timeout time.Duration) {
+	t.Helper()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatal("监控未在预期时间内结束")
+	}
+}
+
+func TestStartContextDeadlineCleansUp(t *testing.T) {
+	rm, dir := newLifecycleTestMonitor(t)
+	done := rm.Done()
+	// 在创建上下文之前记录起点，否则截止时间早于 start+1s，耗时可能略小于 1s
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	go rm.StartContext(ctx)
+	waitFor(t, 900*time.Millisecond, "截止时间前占用内存和磁盘", func() bool {
+		return rm.AllocatedBytes() > 0 && rm.TempFileBytes() > 0
+	})
+
+	waitDone(t, done, 5*time.Second)
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("监控在截止时间前结束: %v", elapsed)
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("截止后仍保留 %d 字节内存", got)
+	}
+	if got := rm.TempFileBytes(); got != 0 {
+		t.Errorf("截止后仍记录 %d 字节临时文件", got)
+	}
+	if got := dirBytes(t, dir); got != 0 {
+		t.Errorf("截止后临时目录仍有 %d 字节", got)
+	}
+	if err := rm.Err(); err != nil {
+		t.Errorf("Err = %v, want nil", err)
+	}
+}
+
+func TestStartContextDeadlineRacesStop(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		rm, _ := newLifecycleTestMonitor(t)
+		done := rm.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+
+		go rm.StartContext(ctx)
+		time.Sleep(140 * time.Millisecond)
+		go rm.Stop()
+		rm.Stop()
+		waitDone(t, done, 5*time.Second)
+		cancel()
+		if got := rm.AllocatedBytes(); got != 0 {
+			t.Fatalf("停止后仍保留 %d 字节内存", got)
+		}
+	}
+}
+
+func TestStartStopStartStop(t *testing.T) {
+	rm, dir := newLifecycleTestMonitor(t)
+	before := runtime.NumGoroutine()
+
+	for cycle := 1; cycle <= 2; cycle++ {
+		go rm.Start()
+		waitFor(t, 3*time.Second, "占用内存和磁盘", func() bool {
+			return rm.Running() && rm.AllocatedBytes() > 0 && rm.TempFileBytes() > 0
+		})
+		done := rm.Done()
+
+		rm.Stop()
+		waitDone(t, done, 5*time.Second)
+		if rm.Running() {
+			t.Fatalf("第 %d 轮 Stop 后仍在运行", cycle)
+		}
+		if got := rm.AllocatedBytes(); got != 0 {
+			t.Errorf("第 %d 轮 Stop 后仍保留 %d 字节内存", cycle, got)
+		}
+		if got := dirBytes(t, dir); got != 0 {
+			t.Errorf("第 %d 轮 Stop 后临时目录仍有 %d 字节", cycle, got)
+		}
+		if err := rm.Err(); err != nil {
+			t.Errorf("第 %d 轮 Err = %v, want nil", cycle, err)
+		}
+		waitFor(t, time.Second, "后台协程退出", func() bool {
+			return runtime.NumGoroutine() <= before
+		})
+	}
+}
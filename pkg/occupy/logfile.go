@@ -0,0 +1,105 @@
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// DefaultLogBackups 日志文件轮转时保留的旧文件数
+const DefaultLogBackups = 3
+
+// RotatingFile 按大小轮转的日志文件：写入后将超过 MaxSize 时，
+// 将当前文件重命名为 PATH.1（已有的 PATH.1 依次后移，最多保留 DefaultLogBackups 个）并重新创建
+type RotatingFile struct {
+	path    string
+	maxSize uint64
+
+	mu   sync.Mutex
+	file *os.File
+	size uint64
+}
+
+// OpenRotatingFile 以追加方式打开日志文件，maxSize 为 0 表示不轮转
+func OpenRotatingFile(path string, maxSize uint64) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open 打开日志文件并获取当前大小
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("获取日志文件信息失败: %w", err)
+	}
+	f.file = file
+	f.size = uint64(info.Size())
+	return nil
+}
+
+// Write 写入日志，写入后将超过大小上限时先轮转。单次写入不会被拆分到两个文件中；
+// 轮转失败但原文件仍可写入时照常写入，并返回轮转的错误
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+uint64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += uint64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// rotate 关闭当前文件，依次后移旧文件后重新创建（调用方需持有 mu）。
+// 无法重命名当前文件时重新打开原文件继续写入，重新打开也失败时 file 为 nil
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("关闭日志文件失败: %w", err)
+	}
+	f.file = nil
+
+	for i := DefaultLogBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	if err := os.Rename(f.path, f.path+".1"); err != nil {
+		// 无法轮转时继续写入原文件
+		if openErr := f.open(); openErr != nil {
+			return openErr
+		}
+		return fmt.Errorf("轮转日志文件失败: %w", err)
+	}
+	return f.open()
+}
+
+// Close 关闭日志文件
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,116 @@
+package occupy
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	const maxSize = 1000
+	path := filepath.Join(t.TempDir(), "occupy.log")
+	file, err := OpenRotatingFile(path, maxSize)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	logger := log.New(file, "", 0)
+	// 每行 50 字节，共 2500 字节，轮转两次后三个文件都保留
+	const lines = 50
+	for i := 0; i < lines; i++ {
+		logger.Printf("第 %03d 行 %s", i, strings.Repeat("x", 37))
+	}
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("未创建轮转后的文件: %v", err)
+	}
+	total := 0
+	for _, name := range []string{path + ".2", path + ".1", path} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatalf("读取 %s: %v", name, err)
+		}
+		if len(data) > maxSize {
+			t.Errorf("%s 大小 %d 超过上限 %d", name, len(data), maxSize)
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			if want := fmt.Sprintf("第 %03d 行 ", total); !strings.HasPrefix(line, want) {
+				t.Fatalf("%s 中的日志行 %q, want 以 %q 开头且不被拆分", name, line, want)
+			}
+			total++
+		}
+	}
+	if total != lines {
+		t.Fatalf("轮转后共 %d 行, want %d", total, lines)
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("轮转次数超出预期，存在 %s.3", path)
+	}
+}
+
+func TestRotatingFileKeepsLimitedBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "occupy.log")
+	file, err := OpenRotatingFile(path, 10)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer file.Close()
+	for i := 0; i < DefaultLogBackups+3; i++ {
+		fmt.Fprintf(file, "line %d\n", i)
+	}
+
+	for i := 1; i <= DefaultLogBackups; i++ {
+		if _, err := os.Stat(fmt.Sprintf("%s.%d", path, i)); err != nil {
+			t.Errorf("缺少轮转文件 %s.%d: %v", path, i, err)
+		}
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.%d", path, DefaultLogBackups+1)); !os.IsNotExist(err) {
+		t.Errorf("保留的轮转文件超过 %d 个", DefaultLogBackups)
+	}
+}
+
+func TestRotatingFileKeepsWritingWhenRenameFails(t *testing.T) {
+	const maxSize = 100
+	path := filepath.Join(t.TempDir(), "occupy.log")
+	// 旧文件的位置都被非空目录占据，后移和重命名都会失败
+	for i := 1; i <= DefaultLogBackups; i++ {
+		dir := fmt.Sprintf("%s.%d", path, i)
+		if err := os.MkdirAll(filepath.Join(dir, "keep"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file, err := OpenRotatingFile(path, maxSize)
+	if err != nil {
+		t.Fatalf("OpenRotatingFile: %v", err)
+	}
+	defer file.Close()
+
+	first := strings.Repeat("a", 80) + "\n"
+	second := strings.Repeat("b", 80) + "\n"
+	if _, err := file.Write([]byte(first)); err != nil {
+		t.Fatalf("第一次写入: %v", err)
+	}
+	n, err := file.Write([]byte(second))
+	if err == nil {
+		t.Error("轮转失败时未返回错误")
+	}
+	if n != len(second) {
+		t.Errorf("轮转失败时写入 %d 字节, want %d", n, len(second))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), first+second; got != want {
+		t.Errorf("日志文件内容 = %q, want %q", got, want)
+	}
+	if file.size != uint64(len(data)) {
+		t.Errorf("记录的大小 = %d, want 实际大小 %d", file.size, len(data))
+	}
+}
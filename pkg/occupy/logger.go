@@ -0,0 +1,174 @@
+package occupy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogLevel 日志级别
+type LogLevel int32
+
+// 日志级别
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+// logLevelNames 日志级别名称
+var logLevelNames = map[LogLevel]string{
+	LogDebug: "debug",
+	LogInfo:  "info",
+	LogWarn:  "warn",
+	LogError: "error",
+}
+
+// String 返回日志级别名称
+func (l LogLevel) String() string {
+	if name, ok := logLevelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LogLevel(%d)", int32(l))
+}
+
+// ParseLogLevel 解析日志级别名称
+func ParseLogLevel(name string) (LogLevel, error) {
+	for level, levelName := range logLevelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+	return LogInfo, fmt.Errorf("未知的日志级别: %q (可选 debug, info, warn, error)", name)
+}
+
+// currentLogLevel 当前日志级别，默认 info
+var currentLogLevel = int32(LogInfo)
+
+// SetLogLevel 设置 occupy 包的日志级别
+func SetLogLevel(level LogLevel) {
+	atomic.StoreInt32(&currentLogLevel, int32(level))
+}
+
+// GetLogLevel 获取 occupy 包的日志级别
+func GetLogLevel() LogLevel {
+	return LogLevel(atomic.LoadInt32(&currentLogLevel))
+}
+
+// LogFormat 日志格式
+type LogFormat string
+
+// 日志格式
+const (
+	// LogFormatText 标准库 log 的文本格式
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON 每行一个JSON对象，包含 time、level、msg 和 labels 字段
+	LogFormatJSON LogFormat = "json"
+)
+
+// ParseLogFormat 解析日志格式名称
+func ParseLogFormat(name string) (LogFormat, error) {
+	switch format := LogFormat(strings.ToLower(name)); format {
+	case LogFormatText, LogFormatJSON:
+		return format, nil
+	}
+	return LogFormatText, fmt.Errorf("未知的日志格式: %q (可选 text, json)", name)
+}
+
+// jsonLogEntry 一行JSON日志
+type jsonLogEntry struct {
+	Time   time.Time         `json:"time"`
+	Level  string            `json:"level"`
+	Msg    string            `json:"msg"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// jsonLogWriter 以JSON行格式写入 out 的日志输出。安装为标准库 log 的输出后，
+// 其他代码通过 log.Printf 等输出的日志也以JSON格式写入（级别记为 info）
+type jsonLogWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+	// flags 切换到JSON格式前标准库 log 的选项，恢复文本格式时还原
+	flags int
+}
+
+// Write 将标准库 log 输出的一行作为 info 级别的日志写入
+func (w *jsonLogWriter) Write(p []byte) (int, error) {
+	if err := w.writeEntry(LogInfo, strings.TrimSuffix(string(p), "\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeEntry 写入一行指定级别的日志，附带 SetLogLabels 设置的标签
+func (w *jsonLogWriter) writeEntry(level LogLevel, msg string) error {
+	line, err := json.Marshal(jsonLogEntry{
+		Time:   time.Now(),
+		Level:  level.String(),
+		Msg:    msg,
+		Labels: currentLogLabels(),
+	})
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err = w.out.Write(append(line, '\n'))
+	return err
+}
+
+// SetLogFormat 设置日志格式。切换为JSON格式时将标准库 log 当前的输出包装为JSON行输出，
+// 之后再通过 log.SetOutput 更换输出会恢复为文本格式
+func SetLogFormat(format LogFormat) {
+	w, isJSON := log.Writer().(*jsonLogWriter)
+	switch {
+	case format == LogFormatJSON && !isJSON:
+		log.SetOutput(&jsonLogWriter{out: log.Writer(), flags: log.Flags()})
+		log.SetFlags(0)
+		log.SetPrefix("")
+	case format != LogFormatJSON && isJSON:
+		log.SetOutput(w.out)
+		log.SetFlags(w.flags)
+		SetLogLabels(currentLogLabels())
+	}
+}
+
+// logf 按级别输出日志
+func logf(level LogLevel, format string, args ...interface{}) {
+	if level < GetLogLevel() {
+		return
+	}
+	msg := fmt.Sprintf(format, args...)
+	if w, ok := log.Writer().(*jsonLogWriter); ok {
+		w.writeEntry(level, msg)
+		return
+	}
+	log.Output(3, msg)
+}
+
+// logDebugf 输出调试日志
+func logDebugf(format string, args ...interface{}) {
+	logf(LogDebug, format, args...)
+}
+
+// logInfof 输出普通日志
+func logInfof(format string, args ...interface{}) {
+	logf(LogInfo, format, args...)
+}
+
+// logWarnf 输出警告日志
+func logWarnf(format string, args ...interface{}) {
+	logf(LogWarn, format, args...)
+}
+
+// logErrorf 输出错误日志
+func logErrorf(format string, args ...interface{}) {
+	logf(LogError, format, args...)
+}
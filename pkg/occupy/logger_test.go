@@ -0,0 +1,44 @@
+package occupy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogLevelSuppressesTickUsage(t *testing.T) {
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	rm := NewResourceMonitor(ResourceConfig{})
+	t.Cleanup(rm.CleanupAllResources)
+
+	buf := captureLog(t, LogWarn)
+	rm.AllocateMemory(4 * 1024 * 1024)
+	rm.monitorAndAdjust()
+	if out := buf.String(); strings.Contains(out, "当前使用情况") || strings.Contains(out, "分配内存") {
+		t.Fatalf("warn 级别输出了每次调整的日志:\n%s", out)
+	}
+	for i := 0; i < backoffStartFailures; i++ {
+		rm.metricReadFailed("内存", errors.New("permission denied"))
+	}
+	if !strings.Contains(buf.String(), "进入退避模式") {
+		t.Fatalf("warn 级别未输出警告:\n%s", buf)
+	}
+	rm.metricReadSucceeded()
+
+	buf = captureLog(t, LogDebug)
+	rm.monitorAndAdjust()
+	if !strings.Contains(buf.String(), "当前使用情况") {
+		t.Fatalf("debug 级别未输出当前使用情况:\n%s", buf)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for name, want := range map[string]LogLevel{"debug": LogDebug, "INFO": LogInfo, "Warn": LogWarn, "error": LogError} {
+		if got, err := ParseLogLevel(name); err != nil || got != want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v, want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("ParseLogLevel(\"verbose\") = nil error, want error")
+	}
+}
@@ -0,0 +1,166 @@
+package occupy
+
+import (
+	"math/rand"
+	"time"
+)
+
+// 已分配内存的访问模式
+const (
+	// MemoryAccessNone 不访问已分配的内存（默认）
+	MemoryAccessNone = "none"
+	// MemoryAccessSequential 按缓存行顺序遍历
+	MemoryAccessSequential = "sequential"
+	// MemoryAccessRandom 随机访问
+	MemoryAccessRandom = "random"
+	// MemoryAccessStrided 以略大于一页的步长跨页遍历，每一遍的起点前移一个缓存行，多遍后覆盖所有缓存行
+	MemoryAccessStrided = "strided"
+)
+
+// DefaultMemoryAccessWorkers 默认内存访问工作线程数
+const DefaultMemoryAccessWorkers = 1
+
+const (
+	// memoryAccessBatch 每次持有内存锁期间的访问次数
+	memoryAccessBatch = 64 * 1024
+	// cacheLineSize 顺序访问的步长
+	cacheLineSize = 64
+	// memoryAccessStride 跨页访问的步长
+	memoryAccessStride = 4096 + cacheLineSize
+	// memoryAccessIdle 尚未分配内存时的等待时间
+	memoryAccessIdle = 100 * time.Millisecond
+)
+
+// memoryAccessEnabled 是否启用内存访问
+func (rm *ResourceMonitor) memoryAccessEnabled() bool {
+	pattern := rm.Config.MemoryAccessPattern
+	return pattern != "" && pattern != MemoryAccessNone
+}
+
+// startMemoryAccess 启动按 MemoryAccessPattern 遍历已分配内存的工作线程，产生内存带宽负载
+func (rm *ResourceMonitor) startMemoryAccess() {
+	if !rm.memoryAccessEnabled() || rm.memoryAccessStop != nil {
+		return
+	}
+
+	workers := rm.Config.MemoryAccessWorkers
+	if workers <= 0 {
+		workers = DefaultMemoryAccessWorkers
+	}
+	logInfof("启动内存访问工作线程: %d 个, 模式 %s", workers, rm.Config.MemoryAccessPattern)
+
+	rm.memoryAccessStop = make(chan struct{})
+	for i := 0; i < workers; i++ {
+		rm.memoryAccessWg.Add(1)
+		go rm.memoryAccessWorker(int64(i), rm.memoryAccessStop)
+	}
+}
+
+// stopMemoryAccess 停止内存访问工作线程并等待退出
+func (rm *ResourceMonitor) stopMemoryAccess() {
+	if rm.memoryAccessStop == nil {
+		return
+	}
+	close(rm.memoryAccessStop)
+	rm.memoryAccessWg.Wait()
+	rm.memoryAccessStop = nil
+	logInfof("内存访问工作线程已停止")
+}
+
+// memoryAccessWorker 持续遍历已分配的内存，直到 stop 关闭
+func (rm *ResourceMonitor) memoryAccessWorker(id int64, stop chan struct{}) {
+	defer rm.memoryAccessWg.Done()
+
+	rng := rand.New(rand.NewSource(rm.randomSeed() + id))
+	cursor := memoryCursor{}
+	var sum byte
+	for {
+		select {
+		case <-stop:
+			_ = sum
+			return
+		default:
+		}
+
+		value, ok := rm.walkMemory(rng, &cursor)
+		if !ok {
+			select {
+			case <-stop:
+				return
+			case <-time.After(memoryAccessIdle):
+			}
+			continue
+		}
+		sum += value
+	}
+}
+
+// memoryCursor 内存遍历的当前位置
+type memoryCursor struct {
+	chunk  int
+	offset int
+	// phase 每一遍在各块中的起始偏移，跨页模式下每遍历完所有块前移一个缓存行
+	phase int
+}
+
+// next 返回本次访问的块序号和偏移并前移游标，当前块遍历完毕、切换到下一块时 ok 为 false。
+// 跨页模式每一遍只访问 1/(memoryAccessStride/cacheLineSize) 的缓存行，
+// 起点逐遍前移，经过 memoryAccessStride/cacheLineSize 遍后覆盖所有缓存行
+func (c *memoryCursor) next(chunks [][]byte, step int) (chunk, offset int, ok bool) {
+	if c.chunk >= len(chunks) {
+		c.nextPass(step)
+	}
+	if c.offset >= len(chunks[c.chunk]) {
+		c.chunk++
+		c.offset = c.phase
+		if c.chunk >= len(chunks) {
+			c.nextPass(step)
+		}
+		return 0, 0, false
+	}
+	chunk, offset = c.chunk, c.offset
+	c.offset += step
+	return chunk, offset, true
+}
+
+// nextPass 回到第一块开始新的一遍，跨页模式下起点前移一个缓存行
+func (c *memoryCursor) nextPass(step int) {
+	c.chunk = 0
+	if step == memoryAccessStride {
+		c.phase = (c.phase + cacheLineSize) % memoryAccessStride
+	}
+	c.offset = c.phase
+}
+
+// walkMemory 持有内存锁访问一批内存，尚未分配内存时返回 false
+func (rm *ResourceMonitor) walkMemory(rng *rand.Rand, cursor *memoryCursor) (byte, bool) {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	chunks := rm.AllocatedMemory
+	if len(chunks) == 0 {
+		return 0, false
+	}
+
+	var sum byte
+	switch rm.Config.MemoryAccessPattern {
+	case MemoryAccessRandom:
+		for i := 0; i < memoryAccessBatch; i++ {
+			chunk := chunks[rng.Intn(len(chunks))]
+			if len(chunk) > 0 {
+				sum += chunk[rng.Intn(len(chunk))]
+			}
+		}
+	default:
+		step := cacheLineSize
+		if rm.Config.MemoryAccessPattern == MemoryAccessStrided {
+			step = memoryAccessStride
+		}
+		for i := 0; i < memoryAccessBatch; i++ {
+			if chunk, offset, ok := cursor.next(chunks, step); ok {
+				sum += chunks[chunk][offset]
+			}
+		}
+	}
+	return sum, true
+}
@@ -0,0 +1,102 @@
+package occupy
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestMemoryAccessWorkersStartAndStop(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	for _, pattern := range []string{MemoryAccessSequential, MemoryAccessRandom, MemoryAccessStrided} {
+		t.Run(pattern, func(t *testing.T) {
+			rm := NewResourceMonitorWithMetrics(ResourceConfig{
+				MemoryAccessPattern: pattern,
+				MemoryAccessWorkers: 2,
+				Interval:            100 * time.Millisecond,
+			}, newFakeMetrics(1024*mb, 1024*mb))
+			rm.AllocateMemory(8 * mb)
+
+			before := runtime.NumGoroutine()
+			rm.startMemoryAccess()
+			if rm.memoryAccessStop == nil {
+				t.Fatal("设置访问模式后未启动内存访问工作线程")
+			}
+			if got := runtime.NumGoroutine(); got < before+2 {
+				t.Fatalf("协程数 = %d, want 至少 %d", got, before+2)
+			}
+			// 工作线程持有内存锁遍历时，分配和读取仍应能正常进行
+			rm.AllocateMemory(4 * mb)
+			if got := rm.AllocatedBytes(); got != 12*mb {
+				t.Fatalf("AllocatedBytes = %d, want %d", got, 12*mb)
+			}
+
+			rm.CleanupAllResources()
+			if rm.memoryAccessStop != nil {
+				t.Fatal("清理后内存访问工作线程未停止")
+			}
+			waitFor(t, time.Second, "内存访问工作线程退出", func() bool {
+				return runtime.NumGoroutine() <= before
+			})
+		})
+	}
+}
+
+func TestMemoryAccessNoneStartsNoWorkers(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{MemoryAccessPattern: MemoryAccessNone, Interval: 100 * time.Millisecond})
+	rm.startMemoryAccess()
+	if rm.memoryAccessStop != nil {
+		rm.stopMemoryAccess()
+		t.Fatal("访问模式为 none 时启动了内存访问工作线程")
+	}
+}
+
+func TestMemoryCursorCoversAllCacheLines(t *testing.T) {
+	const passes = memoryAccessStride / cacheLineSize
+	// 两个块，第一块末尾不足一个步长，第二块不足一个步长
+	chunks := [][]byte{make([]byte, 2*memoryAccessStride+100), make([]byte, memoryAccessStride-cacheLineSize)}
+	for _, c := range []struct {
+		name string
+		step int
+		// passes 覆盖所有缓存行需要的遍数
+		passes int
+	}{
+		{MemoryAccessSequential, cacheLineSize, 1},
+		{MemoryAccessStrided, memoryAccessStride, passes},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			hits := make([]map[int]bool, len(chunks))
+			for i := range hits {
+				hits[i] = make(map[int]bool)
+			}
+			cursor := memoryCursor{}
+			for pass := 0; pass < c.passes; {
+				chunk, offset, ok := cursor.next(chunks, c.step)
+				if !ok {
+					if cursor.chunk == 0 {
+						pass++
+					}
+					continue
+				}
+				if offset%cacheLineSize != 0 {
+					t.Fatalf("块 %d 偏移 %d 未按缓存行对齐", chunk, offset)
+				}
+				hits[chunk][offset] = true
+			}
+
+			for i, chunk := range chunks {
+				lines := (len(chunk) + cacheLineSize - 1) / cacheLineSize
+				if len(hits[i]) != lines {
+					t.Errorf("%d 遍后块 %d 访问了 %d 个缓存行, want %d", c.passes, i, len(hits[i]), lines)
+				}
+			}
+			if c.step == memoryAccessStride {
+				// 一遍只访问部分缓存行，起点回到0时开始重复
+				if cursor.phase != 0 {
+					t.Errorf("%d 遍后起点 = %d, want 0", c.passes, cursor.phase)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ValidateMemoryCommit 验证只保留地址空间（MemoryNoCommit）的配置：仅支持 mmap 分配方式，
+// 且不能与需要读写内存内容的功能同时使用，否则页面会被访问而常驻
+func ValidateMemoryCommit(config ResourceConfig) error {
+	if !config.MemoryNoCommit {
+		return nil
+	}
+	if config.MemoryAllocator != MemoryAllocatorMmap {
+		return fmt.Errorf("只保留地址空间仅支持 %s 分配方式", MemoryAllocatorMmap)
+	}
+	if config.MemoryVerify {
+		return errors.New("只保留地址空间时不能校验内存内容")
+	}
+	if (&ResourceMonitor{Config: config}).memoryAccessEnabled() {
+		return errors.New("只保留地址空间时不能设置内存访问模式")
+	}
+	if config.MemoryOOM {
+		return errors.New("只保留地址空间时不能使用OOM测试模式")
+	}
+	if config.MemoryBasis == MemoryBasisAvailable {
+		return fmt.Errorf("只保留地址空间时内存基准不能为 %s", MemoryBasisAvailable)
+	}
+	return nil
+}
+
+// reservedMemoryPercent 只保留地址空间时，已保留的内存不计入测量到的使用率，
+// 调整时按测量值加上已保留的字节数计算，使保留量（而不是RSS）收敛到目标
+func (rm *ResourceMonitor) reservedMemoryPercent(currentPercent float64, total uint64) float64 {
+	if !rm.Config.MemoryNoCommit || total == 0 {
+		return currentPercent
+	}
+	rm.memoryMutex.Lock()
+	allocated := rm.getTotalAllocatedMemory()
+	rm.memoryMutex.Unlock()
+	return currentPercent + float64(allocated)/float64(total)*100
+}
@@ -0,0 +1,43 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// 内存块内容填充方式
+const (
+	// MemoryFillPattern 写入固定的循环字节序列（默认），可由 MemoryVerify 校验
+	MemoryFillPattern = "pattern"
+	// MemoryFillRandom 写入由 Seed 决定的随机数据，相同种子和相同的分配顺序生成相同的内容
+	MemoryFillRandom = "random"
+)
+
+// ValidateMemoryFill 验证内存填充方式
+func ValidateMemoryFill(config ResourceConfig) error {
+	switch config.MemoryFill {
+	case "", MemoryFillPattern:
+		return nil
+	case MemoryFillRandom:
+	default:
+		return fmt.Errorf("内存填充方式必须是 %s 或 %s", MemoryFillPattern, MemoryFillRandom)
+	}
+	if config.MemoryVerify {
+		return errors.New("随机填充的内存无法校验，不能同时设置内存校验")
+	}
+	if config.MemoryNoCommit {
+		return errors.New("只保留地址空间时不写入内存，不能设置随机填充")
+	}
+	return nil
+}
+
+// fillChunk 按 MemoryFill 写入内存块内容（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) fillChunk(chunk []byte) {
+	if rm.Config.MemoryFill == MemoryFillRandom {
+		rm.memoryRandom().Read(chunk)
+		return
+	}
+	for i := range chunk {
+		chunk[i] = memoryPattern(i)
+	}
+}
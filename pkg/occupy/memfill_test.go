@@ -0,0 +1,92 @@
+package occupy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// seededChunks 按种子分配随机内容、随机大小的内存块
+func seededChunks(t *testing.T, seed int64) [][]byte {
+	t.Helper()
+	rm := NewResourceMonitor(ResourceConfig{
+		Seed:              seed,
+		MemoryFill:        MemoryFillRandom,
+		MemoryFragment:    true,
+		MemoryFragmentMin: 64 * 1024,
+		MemoryFragmentMax: 1024 * 1024,
+	})
+	t.Cleanup(rm.cleanupMemory)
+
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+	if _, err := rm.allocateChunks(4 * 1024 * 1024); err != nil {
+		t.Fatalf("allocateChunks: %v", err)
+	}
+	return rm.AllocatedMemory
+}
+
+// sameChunks 两组内存块的大小和内容是否完全相同
+func sameChunks(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSeedReproducesMemoryChunks(t *testing.T) {
+	first := seededChunks(t, 42)
+	second := seededChunks(t, 42)
+	if !sameChunks(first, second) {
+		t.Fatal("相同种子分配的内存块大小或内容不同")
+	}
+	if other := seededChunks(t, 43); sameChunks(first, other) {
+		t.Fatal("不同种子分配的内存块完全相同")
+	}
+}
+
+// seededTempFile 按种子以随机内容写入临时文件并返回其内容
+func seededTempFile(t *testing.T, seed int64) []byte {
+	t.Helper()
+	rm := NewResourceMonitor(ResourceConfig{Seed: seed, DiskFillMode: DiskFillRandom})
+	path := filepath.Join(t.TempDir(), "seed.dat")
+	if err := rm.writeTempFile(path, 3*writeChunkSize/2, nil, nil); err != nil {
+		t.Fatalf("writeTempFile: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSeedReproducesDiskFill(t *testing.T) {
+	first := seededTempFile(t, 42)
+	if !bytes.Equal(first, seededTempFile(t, 42)) {
+		t.Fatal("相同种子写入的临时文件内容不同")
+	}
+	if bytes.Equal(first, seededTempFile(t, 43)) {
+		t.Fatal("不同种子写入的临时文件内容相同")
+	}
+}
+
+func TestValidateMemoryFill(t *testing.T) {
+	if err := ValidateMemoryFill(ResourceConfig{MemoryFill: MemoryFillRandom}); err != nil {
+		t.Errorf("random: %v", err)
+	}
+	for _, config := range []ResourceConfig{
+		{MemoryFill: "noise"},
+		{MemoryFill: MemoryFillRandom, MemoryVerify: true},
+		{MemoryFill: MemoryFillRandom, MemoryNoCommit: true},
+	} {
+		if err := ValidateMemoryFill(config); err == nil {
+			t.Errorf("ValidateMemoryFill(%+v) = nil, want error", config)
+		}
+	}
+}
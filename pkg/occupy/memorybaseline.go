@@ -0,0 +1,68 @@
+package occupy
+
+// allocateBaseline 设置 MemoryBaselineBytes 时分配固定的内存基线；已分配时不重复分配。
+// 分配失败时保留已分配的部分并输出日志，不影响之后的调整
+func (rm *ResourceMonitor) allocateBaseline() {
+	target := rm.Config.MemoryBaselineBytes
+	if target == 0 {
+		return
+	}
+
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	if len(rm.baselineMemory) > 0 {
+		return
+	}
+	// 基线计入进程内存资源限制，超出时堆分配会使Go运行时直接退出，因此限制在允许的分配量以内
+	if rm.rlimitSet && target > rm.rlimitBudget {
+		logWarnf("内存基线 %s 超出进程内存资源限制允许的分配量，减少为 %s", FormatBytes(target), FormatBytes(rm.rlimitBudget))
+		target = rm.rlimitBudget
+	}
+	var allocated uint64
+	for allocated < target {
+		size := target - allocated
+		if size > memoryChunkSize {
+			size = memoryChunkSize
+		}
+		chunk, err := rm.newChunk(size)
+		if err != nil {
+			logErrorf("分配内存基线失败，已分配 %s: %v", FormatBytes(allocated), err)
+			return
+		}
+		rm.baselineMemory = append(rm.baselineMemory, chunk)
+		allocated += uint64(len(chunk))
+	}
+	logInfof("已分配内存基线 %s，调整在其之上进行", FormatBytes(allocated))
+}
+
+// freeBaseline 释放内存基线（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) freeBaseline() {
+	if len(rm.baselineMemory) == 0 {
+		return
+	}
+	var total uint64
+	for _, chunk := range rm.baselineMemory {
+		total += uint64(len(chunk))
+		rm.freeChunk(chunk)
+	}
+	rm.baselineMemory = nil
+	logInfof("释放内存基线: %s", FormatBytes(total))
+}
+
+// BaselineBytes 获取当前持有的内存基线字节数，不包含在 AllocatedBytes 中（并发安全）
+func (rm *ResourceMonitor) BaselineBytes() uint64 {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	return rm.baselineBytes()
+}
+
+// baselineBytes 当前持有的内存基线字节数（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) baselineBytes() uint64 {
+	var total uint64
+	for _, chunk := range rm.baselineMemory {
+		total += uint64(len(chunk))
+	}
+	return total
+}
@@ -0,0 +1,80 @@
+package occupy
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestBaselineCountsAgainstRlimitBudget(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{MemoryBaselineBytes: 8 * 1024 * 1024})
+	t.Cleanup(rm.cleanupMemory)
+	// 模拟 applyMemoryRlimit 已设置限制，避免在测试进程中真正调用 setrlimit
+	rm.rlimitSet = true
+	rm.rlimitBudget = 12 * 1024 * 1024
+
+	rm.allocateBaseline()
+	if got := rm.BaselineBytes(); got != 8*1024*1024 {
+		t.Fatalf("BaselineBytes = %d, want %d", got, 8*1024*1024)
+	}
+	rm.allocateMemory(64 * 1024 * 1024)
+	if got := rm.AllocatedBytes(); got > 4*1024*1024 {
+		t.Fatalf("基线之上分配了 %d 字节，超过资源限制剩余的 %d 字节", got, 4*1024*1024)
+	}
+}
+
+func TestBaselineCappedByRlimitBudget(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{MemoryBaselineBytes: 16 * 1024 * 1024})
+	t.Cleanup(rm.cleanupMemory)
+	rm.rlimitSet = true
+	rm.rlimitBudget = 4 * 1024 * 1024
+
+	rm.allocateBaseline()
+	if got := rm.BaselineBytes(); got > 4*1024*1024 {
+		t.Fatalf("BaselineBytes = %d, 超过资源限制允许的 %d", got, 4*1024*1024)
+	}
+	rm.allocateMemory(64 * 1024 * 1024)
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Fatalf("基线用尽资源限制后仍分配了 %d 字节", got)
+	}
+}
+
+func TestBaselineSurvivesFullRelease(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:       10,
+		MemoryBaselineBytes: 8 * mb,
+		Interval:            MinInterval,
+	}, newFakeMetrics(100*mb, 1<<30))
+	t.Cleanup(rm.cleanupMemory)
+
+	rm.allocateBaseline()
+	rm.AllocateMemory(16 * mb)
+	if got := rm.AllocatedBytes(); got != 16*mb {
+		t.Fatalf("AllocatedBytes = %d, want %d（不包含基线）", got, 16*mb)
+	}
+
+	// 超出目标 90%，足以释放全部已分配的内存
+	rm.ReleaseMemory(100, &mem.VirtualMemoryStat{Total: 100 * mb, UsedPercent: 100})
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("释放后 AllocatedBytes = %d, want 0", got)
+	}
+	if got := rm.BaselineBytes(); got != 8*mb {
+		t.Fatalf("释放后 BaselineBytes = %d, want %d", got, 8*mb)
+	}
+	rm.memoryMutex.Lock()
+	for i, chunk := range rm.baselineMemory {
+		for offset := range chunk {
+			if chunk[offset] != memoryPattern(offset) {
+				rm.memoryMutex.Unlock()
+				t.Fatalf("基线第 %d 块偏移 %d 的内容被改变", i, offset)
+			}
+		}
+	}
+	rm.memoryMutex.Unlock()
+
+	rm.cleanupMemory()
+	if got := rm.BaselineBytes(); got != 0 {
+		t.Errorf("清理后 BaselineBytes = %d, want 0", got)
+	}
+}
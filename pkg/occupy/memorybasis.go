@@ -0,0 +1,43 @@
+package occupy
+
+import "github.com/shirou/gopsutil/v3/mem"
+
+// 内存目标的计算基准
+const (
+	// MemoryBasisTotal 目标为系统内存总量的百分比（默认）
+	MemoryBasisTotal = "total"
+	// MemoryBasisAvailable 目标为本进程可用内存（系统可用内存加上已分配的内存）的百分比，
+	// 随其他进程的内存使用动态调整
+	MemoryBasisAvailable = "available"
+)
+
+// availableMemoryTarget 在 available 基准下计算应分配的字节数和容忍的字节数
+func (rm *ResourceMonitor) availableMemoryTarget(memInfo *mem.VirtualMemoryStat, allocated uint64) (target, tolerance uint64) {
+	// 已分配的内存不计入 Available，需要加回才是本进程可以使用的内存
+	usable := float64(memInfo.Available + allocated)
+	target = uint64(rm.memoryTargetPercent() / 100.0 * usable)
+	tolerance = uint64(rm.memoryTolerance() / 100.0 * usable)
+	return target, tolerance
+}
+
+// adjustMemoryToAvailable 按 available 基准调整内存：使本进程分配的内存保持为可用内存的目标百分比
+func (rm *ResourceMonitor) adjustMemoryToAvailable(memInfo *mem.VirtualMemoryStat) {
+	allocated := rm.AllocatedBytes()
+	target, tolerance := rm.availableMemoryTarget(memInfo, allocated)
+
+	if allocated < target {
+		bytes := rm.freeMemoryLimit(rm.scaleByGain(target-allocated), memInfo)
+		logDebugf("决策 内存: 已分配 %s, 目标 %s → 分配 %s", FormatBytes(allocated), FormatBytes(target), FormatBytes(bytes))
+		rm.allocateMemory(bytes)
+	} else if allocated > target+tolerance {
+		rm.memoryMutex.Lock()
+		defer rm.memoryMutex.Unlock()
+
+		bytes := rm.scaleByGain(allocated - target)
+		logDebugf("决策 内存: 已分配 %s, 目标 %s → 释放 %s", FormatBytes(allocated), FormatBytes(target), FormatBytes(bytes))
+		rm.releaseBytes(bytes)
+	} else {
+		logDebugf("决策 内存: 已分配 %s, 目标 %s → 在容差 %s 内，保持不变",
+			FormatBytes(allocated), FormatBytes(target), FormatBytes(tolerance))
+	}
+}
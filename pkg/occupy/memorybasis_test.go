@@ -0,0 +1,49 @@
+package occupy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestMemoryBasisTargets(t *testing.T) {
+	const mb = 1024 * 1024
+	// 总量 100MB，已用 20MB，但其他进程的缓存等使可用内存只有 40MB
+	memInfo := func() *mem.VirtualMemoryStat {
+		return &mem.VirtualMemoryStat{Total: 100 * mb, Used: 20 * mb, Available: 40 * mb, UsedPercent: 20}
+	}
+	tests := []struct {
+		basis string
+		want  uint64
+	}{
+		{MemoryBasisTotal, 30 * mb},
+		{MemoryBasisAvailable, 20 * mb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.basis, func(t *testing.T) {
+			rm := NewResourceMonitorWithMetrics(ResourceConfig{
+				MemoryPercent: 50,
+				MemoryBasis:   tt.basis,
+				Interval:      100 * time.Millisecond,
+			}, newFakeMetrics(100*mb, 100*mb))
+			defer rm.CleanupAllResources()
+
+			info := memInfo()
+			rm.AdjustMemoryUsage(info.UsedPercent, info)
+			if got := rm.AllocatedBytes(); got != tt.want {
+				t.Fatalf("基准 %s 分配 = %d, want %d", tt.basis, got, tt.want)
+			}
+
+			// available 基准下已分配的内存加回可用内存，再次调整不应继续增长
+			if tt.basis == MemoryBasisAvailable {
+				info = memInfo()
+				info.Available -= tt.want
+				rm.AdjustMemoryUsage(info.UsedPercent, info)
+				if got := rm.AllocatedBytes(); got != tt.want {
+					t.Fatalf("可用内存随分配减少后 AllocatedBytes = %d, want %d", got, tt.want)
+				}
+			}
+		})
+	}
+}
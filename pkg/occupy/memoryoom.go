@@ -0,0 +1,81 @@
+package occupy
+
+import (
+	"time"
+)
+
+// DefaultMemoryOOMStep OOM模式下每次分配的默认字节数
+const DefaultMemoryOOMStep = 16 * 1024 * 1024
+
+// memoryOOMLogInterval OOM模式下输出分配进度的间隔
+const memoryOOMLogInterval = 5 * time.Second
+
+// memoryOOMStep 获取OOM模式下每次分配的字节数
+func (rm *ResourceMonitor) memoryOOMStep() uint64 {
+	if rm.Config.MemoryOOMStep > 0 {
+		return rm.Config.MemoryOOMStep
+	}
+	return DefaultMemoryOOMStep
+}
+
+// startMemoryOOM 启动OOM模式：忽略内存目标，持续分配内存直到无法继续分配或进程被杀死
+func (rm *ResourceMonitor) startMemoryOOM() {
+	if !rm.Config.MemoryOOM || rm.memoryOOMStop != nil {
+		return
+	}
+
+	logWarnf("OOM模式: 开始持续分配内存（每次 %s），直到无法分配或进程被 OOM killer 杀死",
+		FormatBytes(rm.memoryOOMStep()))
+	rm.memoryOOMStop = make(chan struct{})
+	rm.memoryOOMWg.Add(1)
+	go rm.runMemoryOOM(rm.memoryOOMStop)
+}
+
+// stopMemoryOOM 停止OOM模式的分配协程并等待退出
+func (rm *ResourceMonitor) stopMemoryOOM() {
+	if rm.memoryOOMStop == nil {
+		return
+	}
+	close(rm.memoryOOMStop)
+	rm.memoryOOMWg.Wait()
+	rm.memoryOOMStop = nil
+}
+
+// runMemoryOOM 按步长分配并写入内存，直到 stop 关闭、达到 MaxMemoryBytes 或分配失败。
+// 停止分配后已分配的内存保持不变，直到停止监控时清理
+func (rm *ResourceMonitor) runMemoryOOM(stop chan struct{}) {
+	defer rm.memoryOOMWg.Done()
+
+	lastLog := time.Now()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		rm.memoryMutex.Lock()
+		step := rm.capMemoryBytes(rm.memoryOOMStep())
+		var err error
+		if step > 0 {
+			if _, err = rm.allocateChunks(step); err != nil {
+				rm.rlimitAllocFailed()
+			}
+		}
+		allocated := rm.getTotalAllocatedMemory()
+		rm.memoryMutex.Unlock()
+
+		if step == 0 {
+			logWarnf("OOM模式: 已达到内存分配上限，停止分配 (已分配 %s)", FormatBytes(allocated))
+			return
+		}
+		if err != nil {
+			logErrorf("OOM模式: 无法继续分配内存，停止分配 (已分配 %s): %v", FormatBytes(allocated), err)
+			return
+		}
+		if time.Since(lastLog) >= memoryOOMLogInterval {
+			logInfof("OOM模式: 已分配 %s", FormatBytes(allocated))
+			lastLog = time.Now()
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package occupy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// limitedAllocator 在分配 allowed 次之后返回错误，模拟内存耗尽
+type limitedAllocator struct {
+	heapAllocator
+	allowed int
+}
+
+func (a *limitedAllocator) alloc(size uint64) ([]byte, error) {
+	if a.allowed == 0 {
+		return nil, errors.New("cannot allocate memory")
+	}
+	a.allowed--
+	return a.heapAllocator.alloc(size)
+}
+
+// waitMemoryOOM 等待OOM模式的分配协程自行退出
+func waitMemoryOOM(t *testing.T, rm *ResourceMonitor) {
+	t.Helper()
+	exited := make(chan struct{})
+	go func() {
+		rm.memoryOOMWg.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		t.Fatal("OOM模式的分配协程未停止")
+	}
+}
+
+func TestMemoryOOMStopsGracefully(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name      string
+		config    ResourceConfig
+		allocator memoryAllocator
+		want      uint64
+		wantLog   string
+	}{
+		{
+			name:    "达到上限",
+			config:  ResourceConfig{MaxMemoryBytes: 8 * mb},
+			want:    8 * mb,
+			wantLog: "已达到内存分配上限",
+		},
+		{
+			name:      "分配失败",
+			config:    ResourceConfig{},
+			allocator: &limitedAllocator{allowed: 3},
+			want:      3 * mb,
+			wantLog:   "无法继续分配内存",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLog(t, LogInfo)
+			config := tt.config
+			config.MemoryOOM = true
+			config.MemoryOOMStep = mb
+			config.Interval = MinInterval
+			rm := NewResourceMonitorWithMetrics(config, newFakeMetrics(1024*mb, 1024*mb))
+			if tt.allocator != nil {
+				rm.allocator = tt.allocator
+			}
+
+			rm.startMemoryOOM()
+			waitMemoryOOM(t, rm)
+			if got := rm.AllocatedBytes(); got != tt.want {
+				t.Errorf("停止分配后 AllocatedBytes = %d, want %d", got, tt.want)
+			}
+			if !strings.Contains(buf.String(), tt.wantLog) {
+				t.Errorf("日志中没有 %q:\n%s", tt.wantLog, buf)
+			}
+
+			rm.CleanupAllResources()
+			if rm.memoryOOMStop != nil {
+				t.Error("清理后OOM模式未停止")
+			}
+			if got := rm.AllocatedBytes(); got != 0 {
+				t.Errorf("清理后 AllocatedBytes = %d, want 0", got)
+			}
+		})
+	}
+}
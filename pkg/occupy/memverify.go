@@ -0,0 +1,133 @@
+package occupy
+
+import (
+	"time"
+)
+
+// DefaultMemoryVerifyInterval 默认两轮内存校验之间的间隔
+const DefaultMemoryVerifyInterval = 10 * time.Second
+
+const (
+	// memoryVerifyBatch 每次持有内存锁期间校验的字节数
+	memoryVerifyBatch = 1024 * 1024
+	// memoryVerifyLogLimit 每轮最多输出的不一致记录数
+	memoryVerifyLogLimit = 10
+)
+
+// memoryPattern 内存块中偏移 offset 处写入的字节
+func memoryPattern(offset int) byte {
+	return byte(offset % 256)
+}
+
+// memoryVerifyInterval 获取两轮内存校验之间的间隔
+func (rm *ResourceMonitor) memoryVerifyInterval() time.Duration {
+	if rm.Config.MemoryVerifyInterval > 0 {
+		return rm.Config.MemoryVerifyInterval
+	}
+	return DefaultMemoryVerifyInterval
+}
+
+// MemoryVerifyErrors 获取各轮内存校验累计发现的不一致字节数，同一字节在每轮中都会计入
+func (rm *ResourceMonitor) MemoryVerifyErrors() uint64 {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	return rm.memoryVerifyErrors
+}
+
+// startMemoryVerify 启动内存校验协程
+func (rm *ResourceMonitor) startMemoryVerify() {
+	if !rm.Config.MemoryVerify || rm.memoryVerifyStop != nil {
+		return
+	}
+
+	logInfof("启动内存校验，间隔 %v", rm.memoryVerifyInterval())
+	rm.memoryVerifyStop = make(chan struct{})
+	rm.memoryVerifyWg.Add(1)
+	go rm.runMemoryVerify(rm.memoryVerifyStop)
+}
+
+// stopMemoryVerify 停止内存校验协程并等待退出
+func (rm *ResourceMonitor) stopMemoryVerify() {
+	if rm.memoryVerifyStop == nil {
+		return
+	}
+	close(rm.memoryVerifyStop)
+	rm.memoryVerifyWg.Wait()
+	rm.memoryVerifyStop = nil
+}
+
+// runMemoryVerify 每隔 MemoryVerifyInterval 校验一轮已分配的内存，直到 stop 关闭
+func (rm *ResourceMonitor) runMemoryVerify(stop chan struct{}) {
+	defer rm.memoryVerifyWg.Done()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(rm.memoryVerifyInterval()):
+		}
+
+		if !rm.verifyMemoryPass(stop) {
+			return
+		}
+	}
+}
+
+// verifyMemoryPass 校验一轮已分配的内存，每批持有一次内存锁，stop 关闭时返回 false
+func (rm *ResourceMonitor) verifyMemoryPass(stop chan struct{}) bool {
+	var checked, mismatches uint64
+	logged := 0
+	cursor := memoryCursor{}
+
+	for {
+		select {
+		case <-stop:
+			return false
+		default:
+		}
+
+		rm.memoryMutex.Lock()
+		if cursor.chunk >= len(rm.AllocatedMemory) {
+			rm.memoryMutex.Unlock()
+			break
+		}
+		chunk := rm.AllocatedMemory[cursor.chunk]
+		if start := rm.chunkDataOffset(chunk); cursor.offset < start {
+			cursor.offset = start
+		}
+		end := cursor.offset + memoryVerifyBatch
+		if end > len(chunk) {
+			end = len(chunk)
+		}
+		var batchMismatches uint64
+		for offset := cursor.offset; offset < end; offset++ {
+			expected := memoryPattern(offset)
+			if chunk[offset] == expected {
+				continue
+			}
+			batchMismatches++
+			if logged < memoryVerifyLogLimit {
+				logErrorf("内存校验发现不一致: 第 %d 块偏移 %d (0x%x), 期望 0x%02x, 实际 0x%02x",
+					cursor.chunk, offset, offset, expected, chunk[offset])
+				logged++
+			}
+		}
+		checked += uint64(end - cursor.offset)
+		mismatches += batchMismatches
+		rm.memoryVerifyErrors += batchMismatches
+		cursor.offset = end
+		if cursor.offset >= len(chunk) {
+			cursor.chunk++
+			cursor.offset = 0
+		}
+		rm.memoryMutex.Unlock()
+	}
+
+	if mismatches > 0 {
+		logErrorf("内存校验完成: 已校验 %s, 发现 %d 个不一致字节", FormatBytes(checked), mismatches)
+	} else {
+		logDebugf("内存校验完成: 已校验 %s, 未发现不一致", FormatBytes(checked))
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package occupy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryVerifyDetectsCorruption(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryVerify:         true,
+		MemoryVerifyInterval: 20 * time.Millisecond,
+		Interval:             MinInterval,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+	defer rm.CleanupAllResources()
+	rm.AllocateMemory(4 * mb)
+
+	stop := make(chan struct{})
+	rm.verifyMemoryPass(stop)
+	if got := rm.MemoryVerifyErrors(); got != 0 {
+		t.Fatalf("未破坏时 MemoryVerifyErrors = %d, want 0", got)
+	}
+
+	rm.memoryMutex.Lock()
+	chunk := rm.AllocatedMemory[len(rm.AllocatedMemory)-1]
+	offset := len(chunk) / 2
+	chunk[offset] ^= 0xff
+	rm.memoryMutex.Unlock()
+
+	rm.startMemoryVerify()
+	waitFor(t, 2*time.Second, "发现被破坏的字节", func() bool {
+		return rm.MemoryVerifyErrors() > 0
+	})
+	rm.stopMemoryVerify()
+
+	want := fmt.Sprintf("第 %d 块偏移 %d", len(rm.AllocatedMemory)-1, offset)
+	if !strings.Contains(buf.String(), want) {
+		t.Fatalf("日志未报告被破坏的位置 %q:\n%s", want, buf)
+	}
+}
@@ -0,0 +1,128 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// MetricsProvider 系统资源指标来源，测试中可以替换为返回受控数据的实现
+type MetricsProvider interface {
+	// Memory 获取内存使用情况
+	Memory() (*mem.VirtualMemoryStat, error)
+	// CPU 获取自上次调用以来的CPU使用率
+	CPU() (float64, error)
+	// Disk 获取路径所在磁盘的使用情况
+	Disk(path string) (*disk.UsageStat, error)
+}
+
+// SystemMetrics 基于 gopsutil 读取真实系统指标的 MetricsProvider
+type SystemMetrics struct{}
+
+// Memory 获取内存使用情况
+func (SystemMetrics) Memory() (*mem.VirtualMemoryStat, error) {
+	return mem.VirtualMemory()
+}
+
+// CPU 获取自上次调用以来的CPU使用率
+func (SystemMetrics) CPU() (float64, error) {
+	percent, err := cpu.Percent(0, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(percent) == 0 {
+		return 0, fmt.Errorf("无数据")
+	}
+	return percent[0], nil
+}
+
+// Disk 获取路径所在磁盘的使用情况
+func (SystemMetrics) Disk(path string) (*disk.UsageStat, error) {
+	return disk.Usage(path)
+}
+
+// DefaultMetricTimeout 默认的单次指标读取超时时间
+const DefaultMetricTimeout = 2 * time.Second
+
+// ErrMetricTimeout 指标读取超时
+var ErrMetricTimeout = errors.New("读取指标超时")
+
+// timeoutMetrics 为每次指标读取设置超时的 MetricsProvider。
+// 超时后立即返回 ErrMetricTimeout，阻塞的读取在后台继续直到返回
+type timeoutMetrics struct {
+	provider MetricsProvider
+	timeout  time.Duration
+}
+
+// Memory 获取内存使用情况
+func (m timeoutMetrics) Memory() (*mem.VirtualMemoryStat, error) {
+	return withTimeout(m.timeout, m.provider.Memory)
+}
+
+// CPU 获取自上次调用以来的CPU使用率
+func (m timeoutMetrics) CPU() (float64, error) {
+	return withTimeout(m.timeout, m.provider.CPU)
+}
+
+// Disk 获取路径所在磁盘的使用情况
+func (m timeoutMetrics) Disk(path string) (*disk.UsageStat, error) {
+	return withTimeout(m.timeout, func() (*disk.UsageStat, error) {
+		return m.provider.Disk(path)
+	})
+}
+
+// withTimeout 在独立的协程中执行 read，超过 timeout 未返回时返回 ErrMetricTimeout
+func withTimeout[T any](timeout time.Duration, read func() (T, error)) (T, error) {
+	type result struct {
+		value T
+		err   error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		value, err := read()
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, fmt.Errorf("%w (%v)", ErrMetricTimeout, timeout)
+	}
+}
+
+// metricTimeout 获取单次指标读取的超时时间，小于0表示不限制
+func (rm *ResourceMonitor) metricTimeout() time.Duration {
+	if rm.Config.MetricTimeout == 0 {
+		return DefaultMetricTimeout
+	}
+	return rm.Config.MetricTimeout
+}
+
+// metricsProvider 获取指标来源，未设置时使用 SystemMetrics；每次读取按 MetricTimeout 限制时间
+func (rm *ResourceMonitor) metricsProvider() MetricsProvider {
+	if rm.metrics == nil {
+		rm.metrics = SystemMetrics{}
+	}
+	if timeout := rm.metricTimeout(); timeout > 0 {
+		return timeoutMetrics{provider: rm.metrics, timeout: timeout}
+	}
+	return rm.metrics
+}
+
+// metricTimedOut 指标读取是否超时，超时时输出日志，调用方在本次调整中跳过该资源
+func metricTimedOut(resource string, err error) bool {
+	if !errors.Is(err, ErrMetricTimeout) {
+		return false
+	}
+	logWarnf("获取%s信息超时，本次跳过: %v", resource, err)
+	return true
+}
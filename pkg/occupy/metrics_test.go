@@ -0,0 +1,163 @@
+package occupy
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// fakeMetrics 返回受控数据的 MetricsProvider，各项数值可在测试中随时修改
+type fakeMetrics struct {
+	mu        sync.Mutex
+	memTotal  uint64
+	memUsed   uint64
+	cpu       float64
+	diskTotal uint64
+	diskUsed  uint64
+}
+
+// newFakeMetrics 创建内存总量 memTotal、磁盘总量 diskTotal 且均未使用的 fakeMetrics
+func newFakeMetrics(memTotal, diskTotal uint64) *fakeMetrics {
+	return &fakeMetrics{memTotal: memTotal, diskTotal: diskTotal}
+}
+
+func (m *fakeMetrics) Memory() (*mem.VirtualMemoryStat, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &mem.VirtualMemoryStat{
+		Total:       m.memTotal,
+		Used:        m.memUsed,
+		Available:   m.memTotal - m.memUsed,
+		UsedPercent: float64(m.memUsed) / float64(m.memTotal) * 100,
+	}, nil
+}
+
+func (m *fakeMetrics) CPU() (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.cpu, nil
+}
+
+func (m *fakeMetrics) Disk(path string) (*disk.UsageStat, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &disk.UsageStat{
+		Path:        path,
+		Total:       m.diskTotal,
+		Used:        m.diskUsed,
+		Free:        m.diskTotal - m.diskUsed,
+		UsedPercent: float64(m.diskUsed) / float64(m.diskTotal) * 100,
+	}, nil
+}
+
+// setMemoryUsed 修改内存已用量
+func (m *fakeMetrics) setMemoryUsed(used uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.memUsed = used
+}
+
+// setDiskUsed 修改磁盘已用量
+func (m *fakeMetrics) setDiskUsed(used uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.diskUsed = used
+}
+
+// setCPU 修改CPU使用率
+func (m *fakeMetrics) setCPU(percent float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cpu = percent
+}
+
+func TestMonitorLoopWithFakeMetrics(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	metrics := newFakeMetrics(100*mb, 100*mb)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 20,
+		CPUPercent:    50,
+		DiskTargets:   []DiskTarget{{Path: dir, Percent: 5}},
+	}, metrics)
+	t.Cleanup(rm.CleanupAllResources)
+
+	// 第一次调整：全部低于目标，分别补足差额
+	metrics.setMemoryUsed(10 * mb)
+	metrics.setCPU(0)
+	rm.MonitorAndAdjust()
+	if got := rm.AllocatedBytes(); got != 10*mb {
+		t.Errorf("第一次调整后 AllocatedBytes = %d, want %d", got, 10*mb)
+	}
+	if got := rm.TempFileBytes(); got != 5*mb {
+		t.Errorf("第一次调整后 TempFileBytes = %d, want %d", got, 5*mb)
+	}
+	wantWorkers := runtime.NumCPU() / 2
+	if wantWorkers < 1 {
+		wantWorkers = 1
+	}
+	if workers := cpuWorkers(rm); workers != wantWorkers {
+		t.Errorf("第一次调整后CPU工作线程 = %d, want %d", workers, wantWorkers)
+	}
+
+	// 第二次调整：内存和CPU都超出容差，释放内存并停止CPU负载
+	metrics.setMemoryUsed(30 * mb)
+	metrics.setCPU(90)
+	rm.MonitorAndAdjust()
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Errorf("第二次调整后 AllocatedBytes = %d, want 0", got)
+	}
+	if workers := cpuWorkers(rm); workers != 0 {
+		t.Errorf("第二次调整后CPU工作线程 = %d, want 0", workers)
+	}
+	if m := rm.LastMeasurement(); m.MemoryPercent != 30 || m.CPUPercent != 90 {
+		t.Errorf("最近测量 = 内存 %.1f%%, CPU %.1f%%, want 30%%, 90%%", m.MemoryPercent, m.CPUPercent)
+	}
+}
+
+// slowDiskMetrics 读取磁盘信息时阻塞到 release 关闭
+type slowDiskMetrics struct {
+	*fakeMetrics
+	release chan struct{}
+}
+
+func (m *slowDiskMetrics) Disk(path string) (*disk.UsageStat, error) {
+	<-m.release
+	return m.fakeMetrics.Disk(path)
+}
+
+func TestSlowMetricTimesOutAndSkipsResource(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	buf := captureLog(t, LogInfo)
+	metrics := &slowDiskMetrics{fakeMetrics: newFakeMetrics(100*mb, 100*mb), release: make(chan struct{})}
+	defer close(metrics.release)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 20,
+		DiskPercent:   5,
+		MetricTimeout: 100 * time.Millisecond,
+		Interval:      MinInterval,
+	}, metrics)
+	defer rm.CleanupAllResources()
+
+	start := time.Now()
+	rm.MonitorAndAdjust()
+	// 一次调整包含约 500ms 的等待，加上一次磁盘读取超时
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("磁盘读取阻塞时本次调整耗时 %v", elapsed)
+	}
+	if !strings.Contains(buf.String(), "获取磁盘信息超时，本次跳过") {
+		t.Fatalf("未输出磁盘读取超时日志:\n%s", buf)
+	}
+	if rm.AllocatedBytes() == 0 {
+		t.Error("磁盘读取超时影响了内存调整")
+	}
+	if got := rm.TempFileBytes(); got != 0 {
+		t.Errorf("磁盘读取超时后仍写入了 %d 字节临时文件", got)
+	}
+}
@@ -0,0 +1,88 @@
+package occupy
+
+import (
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// DefaultMirrorFactor 默认镜像倍数
+const DefaultMirrorFactor = 1.0
+
+// mirroredProcess 被镜像进程的使用情况查询，由 *process.Process 实现
+type mirroredProcess interface {
+	IsRunning() (bool, error)
+	Percent(interval time.Duration) (float64, error)
+	MemoryPercent() (float32, error)
+}
+
+// openMirrorProcess 打开被镜像的进程（测试中可替换）
+var openMirrorProcess = func(pid int32) (mirroredProcess, error) {
+	return process.NewProcess(pid)
+}
+
+// mirrorTargets 读取被镜像进程的CPU/内存使用情况，按 MirrorFactor 倍数计算动态目标。
+// 进程不存在时释放所有占用资源并返回 false
+func (rm *ResourceMonitor) mirrorTargets(base Targets) (Targets, bool) {
+	if rm.mirrorLost {
+		return base, false
+	}
+
+	pid := rm.Config.MirrorPID
+	if rm.mirrorProcess == nil {
+		proc, err := openMirrorProcess(pid)
+		if err != nil {
+			rm.mirrorProcessGone(err)
+			return base, false
+		}
+		rm.mirrorProcess = proc
+	}
+
+	running, err := rm.mirrorProcess.IsRunning()
+	if err != nil || !running {
+		rm.mirrorProcessGone(err)
+		return base, false
+	}
+
+	// Percent 返回的是相对单核的百分比，换算为相对全部核心
+	cpuPercent, err := rm.mirrorProcess.Percent(0)
+	if err != nil {
+		rm.mirrorProcessGone(err)
+		return base, false
+	}
+	memPercent, err := rm.mirrorProcess.MemoryPercent()
+	if err != nil {
+		rm.mirrorProcessGone(err)
+		return base, false
+	}
+
+	factor := rm.mirrorFactor()
+	targets := base
+	targets.CPUPercent = math.Min(factor*cpuPercent/float64(runtime.NumCPU()), 100.0)
+	targets.MemoryPercent = math.Min(factor*float64(memPercent), 100.0)
+
+	logDebugf("镜像进程 %d: CPU %.1f%%, 内存 %.1f%% -> 目标 CPU %.1f%%, 内存 %.1f%%",
+		pid, cpuPercent, memPercent, targets.CPUPercent, targets.MemoryPercent)
+	return targets, true
+}
+
+// mirrorFactor 获取镜像倍数
+func (rm *ResourceMonitor) mirrorFactor() float64 {
+	if rm.Config.MirrorFactor > 0 {
+		return rm.Config.MirrorFactor
+	}
+	return DefaultMirrorFactor
+}
+
+// mirrorProcessGone 被镜像进程消失时停止占用
+func (rm *ResourceMonitor) mirrorProcessGone(err error) {
+	rm.mirrorLost = true
+	if err != nil {
+		logWarnf("镜像进程 %d 已不存在，停止占用: %v", rm.Config.MirrorPID, err)
+	} else {
+		logWarnf("镜像进程 %d 已退出，停止占用", rm.Config.MirrorPID)
+	}
+	rm.emergencyRelease()
+}
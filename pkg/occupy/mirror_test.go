@@ -0,0 +1,111 @@
+package occupy
+
+import (
+	"math"
+	"os"
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fixedProcess 返回固定使用率的被镜像进程
+type fixedProcess struct {
+	cpuPercent float64
+	memPercent float32
+}
+
+func (p fixedProcess) IsRunning() (bool, error)               { return true, nil }
+func (p fixedProcess) Percent(time.Duration) (float64, error) { return p.cpuPercent, nil }
+func (p fixedProcess) MemoryPercent() (float32, error)        { return p.memPercent, nil }
+
+// stubMirrorProcess 让 openMirrorProcess 返回 proc，测试结束后恢复
+func stubMirrorProcess(t *testing.T, proc mirroredProcess) {
+	t.Helper()
+	orig := openMirrorProcess
+	openMirrorProcess = func(int32) (mirroredProcess, error) { return proc, nil }
+	t.Cleanup(func() { openMirrorProcess = orig })
+}
+
+func TestMirrorTargetsScaleByFactor(t *testing.T) {
+	cores := float64(runtime.NumCPU())
+	stubMirrorProcess(t, fixedProcess{cpuPercent: 10 * cores, memPercent: 12})
+
+	base := Targets{MemoryPercent: 1, CPUPercent: 1, DiskPercents: []float64{7}}
+	for _, tc := range []struct {
+		factor float64
+		cpu    float64
+		memory float64
+	}{
+		{factor: 1, cpu: 10, memory: 12},
+		{factor: 2, cpu: 20, memory: 24},
+		{factor: 10, cpu: 100, memory: 100},
+	} {
+		rm := NewResourceMonitor(ResourceConfig{
+			MirrorPID:    1,
+			MirrorFactor: tc.factor,
+		})
+		targets, ok := rm.mirrorTargets(base)
+		if !ok {
+			t.Fatalf("factor %.0f: 镜像失败", tc.factor)
+		}
+		if math.Abs(targets.CPUPercent-tc.cpu) > 1e-6 || math.Abs(targets.MemoryPercent-tc.memory) > 1e-4 {
+			t.Errorf("factor %.0f: 目标 CPU %.2f%%, 内存 %.2f%%, want %.2f%%, %.2f%%",
+				tc.factor, targets.CPUPercent, targets.MemoryPercent, tc.cpu, tc.memory)
+		}
+		if len(targets.DiskPercents) != 1 || targets.DiskPercents[0] != 7 {
+			t.Errorf("factor %.0f: 磁盘目标 = %v, want 不变 [7]", tc.factor, targets.DiskPercents)
+		}
+	}
+}
+
+func TestMirrorTargetsFromCurrentProcess(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		MirrorPID: int32(os.Getpid()),
+	})
+	base := Targets{MemoryPercent: 1, CPUPercent: 1}
+	// 首次读取只记录进程的CPU时间，之后按两次读取之间的变化计算使用率
+	if _, ok := rm.mirrorTargets(base); !ok {
+		t.Fatal("镜像当前进程失败")
+	}
+	burnCPU(50 * time.Millisecond)
+	targets, ok := rm.mirrorTargets(base)
+	if !ok {
+		t.Fatal("镜像当前进程失败")
+	}
+	if targets.CPUPercent <= 0 || targets.CPUPercent > 100 {
+		t.Errorf("CPU目标 = %.2f, want (0, 100]", targets.CPUPercent)
+	}
+	if targets.MemoryPercent <= 0 || targets.MemoryPercent > 100 {
+		t.Errorf("内存目标 = %.2f, want (0, 100]", targets.MemoryPercent)
+	}
+}
+
+// burnCPU 在当前协程忙循环 d
+func burnCPU(d time.Duration) {
+	for start := time.Now(); time.Since(start) < d; {
+	}
+}
+
+func TestMirrorProcessGoneStopsOccupying(t *testing.T) {
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("无法运行子进程: %v", err)
+	}
+	rm := NewResourceMonitor(ResourceConfig{
+		MirrorPID: int32(cmd.Process.Pid),
+	})
+	rm.AllocateMemory(4 * 1024 * 1024)
+
+	base := Targets{MemoryPercent: 30}
+	targets, ok := rm.mirrorTargets(base)
+	if ok || targets.MemoryPercent != base.MemoryPercent {
+		t.Fatalf("镜像已退出的进程 = %+v, %v, want 原目标, false", targets, ok)
+	}
+	if !rm.mirrorLost {
+		t.Fatal("进程退出后未记录")
+	}
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Fatalf("进程退出后仍保留 %d 字节内存", got)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build linux
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// mpolBind mbind 的 MPOL_BIND 策略：只从指定节点分配内存
+const mpolBind = 2
+
+// bindNUMANode 使用 mbind 将映射绑定到指定NUMA节点，需在首次访问内存之前调用
+func bindNUMANode(mapping []byte, node int) error {
+	if _, err := os.Stat(fmt.Sprintf("/sys/devices/system/node/node%d", node)); err != nil {
+		return fmt.Errorf("NUMA节点 %d 不存在", node)
+	}
+
+	mask := make([]uint64, node/64+1)
+	mask[node/64] |= 1 << (uint(node) % 64)
+
+	full := mapping[:cap(mapping)]
+	_, _, errno := syscall.Syscall6(syscall.SYS_MBIND,
+		uintptr(unsafe.Pointer(&full[0])), uintptr(len(full)), mpolBind,
+		uintptr(unsafe.Pointer(&mask[0])), uintptr(len(mask)*64+1), 0)
+	if errno != 0 {
+		return fmt.Errorf("mbind 失败: %v", errno)
+	}
+	return nil
+}
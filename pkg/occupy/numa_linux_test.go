@@ -0,0 +1,75 @@
+//go:build linux
+
+package occupy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// numaPolicyAt 从 /proc/self/numa_maps 读取从 addr 开始的映射的内存策略
+func numaPolicyAt(t *testing.T, addr uintptr) string {
+	t.Helper()
+	file, err := os.Open("/proc/self/numa_maps")
+	if err != nil {
+		t.Skipf("无法读取 /proc/self/numa_maps: %v", err)
+	}
+	defer file.Close()
+
+	prefix := fmt.Sprintf("%x ", addr)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, prefix) {
+			return strings.Fields(line)[1]
+		}
+	}
+	t.Fatalf("numa_maps 中没有地址 %x 的映射", addr)
+	return ""
+}
+
+func TestNUMABindNodeZeroOrReportsUnavailable(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	allocator := &mmapAllocator{numaNode: 0}
+	chunk, err := allocator.alloc(4 * 1024 * 1024)
+	if err != nil {
+		t.Fatalf("alloc: %v", err)
+	}
+	defer allocator.free(chunk)
+	for i := range chunk {
+		chunk[i] = byte(i)
+	}
+
+	if allocator.numaUnavailable {
+		if !strings.Contains(buf.String(), "NUMA绑定不可用") {
+			t.Fatalf("NUMA不可用时未输出日志:\n%s", buf)
+		}
+		t.Log("NUMA不可用，已按默认策略分配")
+		return
+	}
+	if policy := numaPolicyAt(t, uintptr(unsafe.Pointer(&chunk[0]))); policy != "bind:0" {
+		t.Fatalf("映射的内存策略 = %q, want bind:0", policy)
+	}
+}
+
+func TestNUMAMissingNodeFallsBack(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	allocator := &mmapAllocator{numaNode: 4095}
+	for i := 0; i < 2; i++ {
+		chunk, err := allocator.alloc(1024 * 1024)
+		if err != nil {
+			t.Fatalf("alloc: %v", err)
+		}
+		chunk[0] = 1
+		allocator.free(chunk)
+	}
+	if !allocator.numaUnavailable {
+		t.Fatal("绑定不存在的节点后 numaUnavailable = false, want true")
+	}
+	if n := strings.Count(buf.String(), "NUMA绑定不可用"); n != 1 {
+		t.Fatalf("回退日志输出 %d 次, want 1:\n%s", n, buf)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build unix && !linux
+
+package occupy
+
+import (
+	"errors"
+)
+
+// bindNUMANode 当前平台不支持NUMA绑定
+func bindNUMANode(mapping []byte, node int) error {
+	return errors.New("当前平台不支持NUMA绑定")
+}
@@ -1,33 +1,302 @@
 package occupy
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"math/rand"
+	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/process"
 )
 
 // ResourceConfig 资源配置
 type ResourceConfig struct {
 	MemoryPercent float64
 	CPUPercent    float64
+	// CPUCoreLoad 需要保持忙碌的核心数（如 4.5 表示4个满载工作线程加1个50%占空比的工作线程），
+	// 大于0时覆盖 CPUPercent
+	CPUCoreLoad float64
+	// CPUSmoothing 后台CPU采样的指数加权移动平均平滑系数 (0-1]，越大越接近最新采样值，
+	// 为 0 时使用 DefaultCPUSmoothing
+	CPUSmoothing float64
+	// ControlGain 比例控制增益 Kp (0-1]，每次调整只补齐目标与当前值差距的该比例，
+	// 经过多次调整逐步收敛以避免过冲；为 0 或 1 时直接补齐全部差值
+	ControlGain float64
+	// CPUControl CPU负载的控制方式: duty（默认，满载加占空比工作线程）或
+	// tokens（每个核心一个工作线程，从共享令牌桶获取CPU时间，按测量误差调整令牌补充速率）
+	CPUControl string
+	// CPUCount CPU工作线程计算所依据的核心数，通常与设置的 GOMAXPROCS 一致，
+	// 为 0 时使用 runtime.NumCPU()
+	CPUCount int
+	// StartupOrder 分阶段启动的顺序，各资源在对应阶段启用后才开始调整，为空时同时调整
+	StartupOrder []StartupStage
+	// RespectCgroups 为 true 且未设置 CPUCount 时，若 cgroup CPU 配额小于 NumCPU，
+	// 以配额对应的核心数作为CPU工作线程计算的依据
+	RespectCgroups bool
+	// CgroupPath 启动时将本进程移入的 cgroup 目录（绝对路径，或相对于 /sys/fs/cgroup 的路径），
+	// 使CPU负载和之后分配的内存计入该 cgroup 而不是本进程原来的 cgroup；为空表示不移动，仅Linux
+	CgroupPath string
+	// PprofAddr 运行期间提供 net/http/pprof 接口的监听地址（如 localhost:6060），用于分析本工具自身的性能；
+	// 为空表示不启用
+	PprofAddr string
+	// CPUWorkloadType CPU负载的计算类型: float（默认）、int 或 memory
+	CPUWorkloadType string
+	// CPUMix CPU混合负载，按权重将工作线程分配给不同的计算类型，设置后覆盖 CPUWorkloadType
+	CPUMix []CPUWorkloadWeight
+	// CPUExclude 不施加CPU负载的核心编号，工作线程轮流绑定到其余核心（仅Linux）；
+	// 排除后能达到的CPU使用率上限相应降低
+	CPUExclude []int
+	// CPUCooldown 停止CPU负载后至少经过该时间才允许重新启动，0 表示不限制
+	CPUCooldown time.Duration
 	DiskPercent   float64
 	Interval      time.Duration
+	// ReportInterval 输出当前使用情况的间隔，大于0时按该间隔以 info 级别输出，
+	// 否则每次调整时以 debug 级别输出
+	ReportInterval time.Duration
+	// DiskPath 统计磁盘使用率的路径，为空时统计临时文件写入目录所在的文件系统；
+	// 与写入目录不在同一文件系统时调整无法收敛（ValidateConfig 会给出警告）
+	DiskPath string
+	// DiskTargets 多个磁盘占用目标，设置后忽略 DiskPercent/DiskPath
+	DiskTargets []DiskTarget
+	// MemoryAllocator 内存分配方式: heap（默认）、mmap、shm 或 file
+	MemoryAllocator string
+	// MemoryFileDir file 分配方式下映射文件所在的目录，为空时使用第一个磁盘目标的写入目录
+	MemoryFileDir string
+	// UseHugePages 使用mmap分配时尝试使用大页（仅Linux），不可用时回退到普通页
+	UseHugePages bool
+	// MemoryNoCommit 只保留地址空间而不写入内存块（仅 mmap 分配方式），虚拟内存大而常驻内存很小，
+	// 用于测试混淆VSZ与RSS的监控；此时按已保留的字节数向目标调整
+	MemoryNoCommit bool
+	// NUMABind 使用mmap分配时将内存绑定到 NUMANode 节点（仅Linux），不可用时按默认策略分配
+	NUMABind bool
+	NUMANode int
+	// WarmupDuration 开始占用前采样CPU基线的预热时间，0 表示不预热
+	WarmupDuration time.Duration
+	// DiskFilesPerDir 每个子目录最多写入的临时文件数，大于0时在临时目录下创建子目录分散存放，
+	// 用于测试目录项/inode压力；0 表示所有文件直接写入临时目录
+	DiskFilesPerDir int
+	// DiskFillMode 临时文件内容: sequential（默认，循环字节序列）、random（不可压缩的随机数据）或 zero（全零）
+	DiskFillMode string
+	// MemoryFill 内存块内容: pattern（默认，固定的循环字节序列）或 random（由 Seed 决定的随机数据）
+	MemoryFill string
+	// Seed 所有随机选择使用的随机数种子：磁盘和内存的随机填充、碎片模式的块大小、随机释放顺序、
+	// 随机内存访问和汇总统计的抽样，相同的种子生成相同的内容和块大小；0 表示使用基于时间的种子
+	Seed int64
+	// MetricTimeout 单次读取内存、CPU、磁盘指标的超时时间，超时的资源在本次调整中跳过；
+	// 为 0 时使用 DefaultMetricTimeout，小于0表示不限制
+	MetricTimeout time.Duration
+	// DiskDirectIO 以直接I/O方式写入临时文件（仅Linux，O_DIRECT），避免写入的数据占用页缓存
+	// 而影响内存使用率的测量；文件系统不支持时回退到普通写入
+	DiskDirectIO bool
+	// DiskFsync 每个临时文件写入完成后调用 fsync，确保空间已在存储上实际分配，
+	// 而不是只存在于页缓存中；会显著降低写入速度
+	DiskFsync bool
+	// DiskWriteMBps 创建临时文件时的写入速率上限 (MB/s)，0 表示不限速
+	DiskWriteMBps float64
+	// DiskWriteRetries 单个临时文件写入失败后的重试次数，只读文件系统、权限不足等永久错误不重试；
+	// 为 0 时使用 DefaultDiskWriteRetries，小于0表示不重试
+	DiskWriteRetries int
+	// DiskRetryDelay 首次重试前的等待时间，之后每次加倍；为 0 时使用 DefaultDiskRetryDelay
+	DiskRetryDelay time.Duration
+	// Tolerance 所有资源的容忍度（百分点），超出目标该范围才进行反向调整，
+	// 为 0 时使用 DefaultTolerance
+	Tolerance float64
+	// MemoryTolerance/CPUTolerance/DiskTolerance 单项资源的容忍度，为 0 时使用 Tolerance
+	MemoryTolerance float64
+	CPUTolerance    float64
+	DiskTolerance   float64
+	// MemoryBasis 内存目标的计算基准: total（默认，系统内存总量）或 available（本进程可用的内存）
+	MemoryBasis string
+	// MemoryReleasePolicy 释放内存时选择内存块的顺序: tail（默认，从最后分配的块开始）、
+	// head（从最早分配的块开始）或 random（随机顺序，由 Seed 决定）
+	MemoryReleasePolicy string
+	// MemoryWave 内存目标波形: flat（默认，固定目标）、sawtooth、sine 或 square，
+	// 以 MemoryPercent 为中心、MemoryWaveAmplitude 为振幅（百分点）、MemoryWavePeriod 为周期变化
+	MemoryWave          string
+	MemoryWaveAmplitude float64
+	MemoryWavePeriod    time.Duration
+	// BurstInterval/BurstDuration 每隔 BurstInterval 将目标提升到突发目标并保持 BurstDuration，
+	// 用于模拟短时峰值；BurstInterval 为 0 表示不启用
+	BurstInterval time.Duration
+	BurstDuration time.Duration
+	// BurstMemoryPercent/BurstCPUPercent/BurstDiskPercent 突发窗口内的目标，为 0 时保持基础目标
+	BurstMemoryPercent float64
+	BurstCPUPercent    float64
+	BurstDiskPercent   float64
+	// MemoryOOM 忽略内存目标，每次分配 MemoryOOMStep（为 0 时使用 DefaultMemoryOOMStep）
+	// 并写入，直到分配失败、达到 MaxMemoryBytes 或进程被 OOM killer 杀死，用于测试OOM处理
+	MemoryOOM     bool
+	MemoryOOMStep uint64
+	// LeakMode 模拟内存泄漏：只向目标增长、超出目标也从不释放，
+	// 每次调整最多增长 LeakRateBytes（0 表示不限制），可配合 MaxMemoryBytes 限制上限
+	LeakMode      bool
+	LeakRateBytes uint64
+	// MemoryAccessPattern 已分配内存的访问模式: none（默认）、sequential、random 或 strided，
+	// 启用时由 MemoryAccessWorkers 个工作线程持续遍历已分配的内存，产生内存带宽负载
+	MemoryAccessPattern string
+	MemoryAccessWorkers int
+	// Labels 附加到运行汇总和运行结果中的标签，集中汇总多个实例的数据时用于区分来源；
+	// 同时作为 /metrics 中每个指标的标签维度，命令行程序还通过 SetLogLabels 将其添加到每行日志
+	Labels map[string]string
+	// ReportSelf 输出使用情况时同时输出本进程自身的RSS和CPU占用，便于从系统使用率中扣除
+	ReportSelf bool
+	// ConvergeDeadline 预热结束后在该时间内仍有目标大于0的资源未达到容忍范围时，以错误停止监控，
+	// 0 表示不检查
+	ConvergeDeadline time.Duration
+	// MemoryVerify 是否定期校验已分配内存的内容，发现与写入的模式不一致时记录块号和偏移，
+	// 用于检测内存硬件故障；MemoryVerifyInterval 为两轮校验之间的间隔，为0时使用 DefaultMemoryVerifyInterval
+	MemoryVerify         bool
+	MemoryVerifyInterval time.Duration
+	// TagAllocations 是否在每个内存块开头写入 AllocationMagic 和块编号，并在输出状态快照时输出内存块清单，
+	// 便于外部堆分析和内存泄漏工具确认这些内存是有意占用的
+	TagAllocations bool
+	// MemoryBaselineBytes 开始占用前一次性分配的固定内存，不受调整影响，释放内存时也不会释放，
+	// 只在停止清理或紧急释放时释放（紧急释放后资源恢复时重新分配）；计入测量到的使用率，
+	// 调整在其之上进行，不计入 MaxMemoryBytes。0 表示不分配
+	MemoryBaselineBytes uint64
+	// MaxMemoryBytes 本进程最多分配的内存字节数，无论百分比目标计算出多少都不会超过，0 表示不限制
+	MaxMemoryBytes uint64
+	// MemoryFragment 碎片模式：每个内存块的大小在 MemoryFragmentMin 到 MemoryFragmentMax 之间随机选取，
+	// 而不是固定的100MB，用于测试分配器和GC在堆碎片化时的表现；随机序列由 Seed 决定
+	MemoryFragment bool
+	// MemoryFragmentMin/MemoryFragmentMax 碎片模式下内存块大小的范围，为 0 时使用
+	// DefaultMemoryFragmentMin/DefaultMemoryFragmentMax
+	MemoryFragmentMin uint64
+	MemoryFragmentMax uint64
+	// RlimitMemoryBytes 启动时通过 setrlimit 为本进程设置的内存硬限制（Linux 为 RLIMIT_DATA，其他Unix为 RLIMIT_AS），
+	// 作为 MaxMemoryBytes 之外的兜底：主动分配保持在限制以内，超出时分配失败并停止增长；0 表示不设置，仅Unix
+	RlimitMemoryBytes uint64
+	// MemoryFloorBytes 可用内存下限，低于该值时紧急释放所有资源并暂停，0 表示不检查
+	MemoryFloorBytes uint64
+	// MinFreeMemoryBytes 至少保留的可用内存（如留给页缓存），每次调整时分配不超过
+	// Available - MinFreeMemoryBytes，可用内存不足时释放差额；0 表示不保留
+	MinFreeMemoryBytes uint64
+	// DiskMeasureOnly 只测量并输出磁盘使用率，从不创建或删除临时文件（如只读的根文件系统），
+	// 此时磁盘目标不参与是否达到目标的判断
+	DiskMeasureOnly bool
+	// MaxDiskBytes 临时文件最多占用的字节数（所有磁盘目标合计），0 表示不限制
+	MaxDiskBytes uint64
+	// GPUMemoryPercent 目标GPU显存使用百分比（整个设备），0 表示不占用；
+	// 需在 Linux 上使用 -tags gpu 编译，没有GPU或NVML/CUDA不可用时输出日志并跳过
+	GPUMemoryPercent float64
+	// GPUDevice 占用显存的GPU设备编号（CUDA 编号）
+	GPUDevice int
+	// DiskFloorBytes 磁盘剩余空间下限，低于该值时紧急释放所有资源并暂停，0 表示不检查
+	DiskFloorBytes uint64
+	// MirrorPID 被镜像的进程ID，大于0时内存/CPU目标由该进程的使用情况乘以 MirrorFactor 得出
+	MirrorPID int32
+	// MirrorFactor 镜像倍数，为 0 时使用 DefaultMirrorFactor
+	MirrorFactor float64
+	// RampDown 正常停止时在该时长内逐步释放内存、减少CPU负载后再清理，0 表示立即清理；
+	// 受 Stop 的清理超时限制
+	RampDown time.Duration
+	// ProgressOutput 每次调整后输出当前使用率与目标的对比，为 nil 时不输出；
+	// 为终端时在同一行刷新，否则每次输出一行
+	ProgressOutput io.Writer
+	// NoCleanupOnError 监控因错误退出（而非正常停止）时保留已分配的内存和临时文件，便于事后排查
+	NoCleanupOnError bool
+	// AllowTmpfsDisk 允许在 tmpfs/ramfs 上进行磁盘占用
+	AllowTmpfsDisk bool
+	// DisableForcedGC 释放内存后不主动调用 runtime.GC/debug.FreeOSMemory，避免在同一进程中
+	// 引入额外的STW停顿；内存将由正常的GC回收，归还操作系统会更慢
+	DisableForcedGC bool
+	// GCPercentOverride 运行期间将GC目标百分比（同 GOGC）设置为 GCPercent，结束后恢复原值。
+	// 占用大量堆内存时调高该值可减少GC带来的CPU开销；小于0关闭自动GC，此时程序自身产生的垃圾
+	// 只在释放内存后的主动GC时回收，与 DisableForcedGC 同时使用时堆内存会持续增长
+	GCPercentOverride bool
+	GCPercent         int
+	// FreeOSMemoryThreshold 累计释放超过该字节数后将内存归还操作系统，
+	// 为 0 时使用 DefaultFreeOSMemoryThreshold
+	FreeOSMemoryThreshold uint64
+	// FilePrefix 临时文件名前缀，为空时使用 DefaultFilePrefix
+	FilePrefix string
+}
+
+// DefaultFilePrefix 默认临时文件名前缀
+const DefaultFilePrefix = "go_occupy_temp_"
+
+// DefaultDiskPath 查看资源使用情况时默认的磁盘路径
+const DefaultDiskPath = "/"
+
+// DefaultWarmupDuration 默认预热时间
+const DefaultWarmupDuration = time.Second
+
+// 临时文件内容填充方式
+const (
+	DiskFillSequential = "sequential"
+	DiskFillRandom     = "random"
+	DiskFillZero       = "zero"
+)
+
+// DefaultTolerance 默认容忍度（百分点）
+const DefaultTolerance = 5.0
+
+// DefaultFreeOSMemoryThreshold 默认归还操作系统内存的阈值
+const DefaultFreeOSMemoryThreshold = 64 * 1024 * 1024
+
+// DiskTarget 磁盘占用目标
+type DiskTarget struct {
+	// Path 临时文件写入目录，同时用于统计该磁盘的使用率；
+	// 为空表示写入临时目录，并统计 DiskPath（未设置时为临时目录）的使用率
+	Path    string
+	Percent float64
+}
+
+// ParseDiskTarget 解析 PATH=PERCENT 格式的磁盘占用目标
+func ParseDiskTarget(value string) (DiskTarget, error) {
+	index := strings.LastIndex(value, "=")
+	if index <= 0 || index == len(value)-1 {
+		return DiskTarget{}, fmt.Errorf("磁盘目标格式错误: %q (应为 PATH=PERCENT)", value)
+	}
+
+	percent, err := strconv.ParseFloat(value[index+1:], 64)
+	if err != nil {
+		return DiskTarget{}, fmt.Errorf("磁盘目标百分比错误: %q", value)
+	}
+	if percent < 0 || percent > 100 {
+		return DiskTarget{}, fmt.Errorf("磁盘百分比必须在 0-100 之间: %q", value)
+	}
+
+	return DiskTarget{Path: value[:index], Percent: percent}, nil
 }
 
 // ResourceMonitor 资源监控器
 type ResourceMonitor struct {
 	Config ResourceConfig
+	// 运行状态，stop/stopOnce/cleanupDone 在再次 Start 时重新创建
+	lifecycleMutex sync.Mutex
+	running bool
+	runs    int
 	stop   chan bool
+	stopOnce sync.Once
 	cleanupDone chan bool
+	metrics MetricsProvider
 	
+	// OnStarted 每次运行中预热结束且首次完成调整后调用一次，可为 nil
+	OnStarted func()
+	// OnStopped 停止监控并清理完所有资源后调用，可为 nil
+	OnStopped func()
+	// OnError 调整过程中发生错误（如 *DiskError）时调用，为 nil 时记录日志
+	OnError func(error)
+
+	// 导致监控退出的错误
+	errMutex sync.Mutex
+	err      error
+	started   bool
+
 	// CPU负载控制
 	cpuLoadMutex sync.Mutex
 	cpuLoadStop  chan bool
@@ -35,73 +304,320 @@ type ResourceMonitor struct {
 	ActiveCPULoad bool
 	targetCPUWorkers int
 	currentCPUWorkers int
+	targetCPULoad float64 // 目标负载（核心数），小数部分由占空比工作线程承担
+	currentCPULoad float64
+	cpuStoppedAt time.Time // 上次因超出目标而停止CPU负载的时间
+	cpuSuspended bool // 已通过 SuspendCPU 暂停，期间不调整CPU负载
+	suspendedCPULoad float64 // 暂停前的目标负载，恢复时重新启动
+	cpuSampler cpuSampler // 后台CPU采样
+	cpuSamplerWg sync.WaitGroup
+	cpuTokens cpuTokenBucket // 令牌桶控制方式下工作线程共享的令牌桶
+	cgroupCPUs float64 // 启动时检测到的 cgroup CPU 配额（核心数），0 表示未限制或未检测
 	
 	// 内存管理
 	memoryMutex sync.Mutex
 	AllocatedMemory [][]byte
+	baselineMemory [][]byte // MemoryBaselineBytes 对应的固定内存，不参与调整和释放
+	allocator memoryAllocator
+	memoryCapped bool // 是否已达到 MaxMemoryBytes，用于避免重复输出日志
+	nextChunkID uint64 // 启用 TagAllocations 时最近分配的内存块编号
+	memoryRand *rand.Rand // 随机填充内存块和碎片模式选取块大小使用的随机数生成器
+	rlimitSet bool // 是否已按 RlimitMemoryBytes 设置进程内存资源限制
+	rlimitBudget uint64 // 设置资源限制后最多分配的字节数，分配失败时降到当时已分配的字节数
+	memoryAccessStop chan struct{} // 内存访问工作线程的停止通道，未启动时为 nil
+	memoryAccessWg   sync.WaitGroup
+	memoryVerifyStop chan struct{} // 内存校验协程的停止通道，未启动时为 nil
+	memoryVerifyWg   sync.WaitGroup
+	memoryVerifyErrors uint64 // 内存校验累计发现的不一致字节数
+	memoryOOMStop chan struct{} // OOM模式分配协程的停止通道，未启动时为 nil
+	memoryOOMWg   sync.WaitGroup
+	releasedSinceFree uint64 // 上次归还操作系统后累计释放的字节数
 	
-	// 磁盘文件管理
+	// GPU显存管理
+	gpuMutex sync.Mutex
+	gpu gpuDevice // 已打开的GPU设备，未设置 GPUMemoryPercent 或GPU不可用时为 nil
+	gpuChunks []gpuChunk
+
+	// 磁盘文件管理：diskWriteMutex 串行化写入和整体清理，写入期间可能持有很久；
+	// diskMutex 只在读写下面的记录时短暂持有，两者都需要时先获取 diskWriteMutex
+	diskWriteMutex sync.Mutex
 	diskMutex sync.Mutex
+	tempFiles map[string][]string // 按写入目录记录已创建的临时文件
+	tempFileSizes map[string]uint64 // 已创建临时文件的大小
+	tempSubdirs map[string][]string // 按写入目录记录已创建的子目录
+	dirFileCounts map[string]int // 各目录中已创建的临时文件数
+	tempFileBytes uint64 // 当前临时文件的总字节数
+	diskCapped bool // 是否已达到 MaxDiskBytes，用于避免重复输出日志
+	diskRand  *rand.Rand          // 随机填充使用的随机数生成器，由 diskWriteMutex 保护
+	directIOUnavailable bool // 直接I/O不可用，已回退到普通写入，由 diskWriteMutex 保护
+	cleanupErrors int // 清理所有临时文件时删除失败的次数
+
+	// 最近一次测量结果
+	measurementMutex sync.Mutex
+	lastMeasurement  Measurement
+	lastTargets      *Targets
+	levelsStart      time.Time
+	levelsEnd        time.Time
+	memoryLevels     levelReservoir // 各次测量的使用率分布，用于 Summary
+	cpuLevels        levelReservoir
+	diskLevels       []*levelReservoir
+	runStart         time.Time // 本次运行开始、完成清理和首次达到目标的时间，用于 Result
+	runEnd           time.Time
+	convergedAt      time.Time
+
+	// pprof 服务，设置 PprofAddr 时在运行期间启动
+	pprofMutex      sync.Mutex
+	pprofServer     *http.Server
+	pprofListenAddr string
+
+	// 运行前的GC目标百分比，设置 GCPercentOverride 时在结束后恢复
+	previousGCPercent int
+	gcPercentApplied  bool
+
+	// 本进程信息，用于 SelfUsage
+	selfMutex   sync.Mutex
+	selfProcess *process.Process
+
+	// 运行中修改的目标及暂停状态
+	retargetMutex  sync.Mutex
+	pendingTargets *Targets // Retarget 设置、尚未生效的目标
+	paused         bool
+
+	// 指标读取失败退避（仅由监控协程访问）
+	metricFailures int
+	skipTicks      int
+
+	// 安全看门狗（仅由监控协程访问）
+	watchdogPaused bool
+	watchdogTrips  int
+
+	// 临时文件目录写入失败计数及已暂停的目录（仅由监控协程访问）
+	diskFailures map[string]int
+	diskDisabled map[string]bool
+
+	// 分阶段启动的进度（仅由监控协程访问）
+	startupBegan  time.Time
+	startupStages int
+
+	// 动态目标（仅由监控协程访问），为 nil 时使用配置中的目标
+	activeTargets *Targets
+	waveStart     time.Time
+	progressShown  bool
+	progressLogger *log.Logger
+	burstStart    time.Time
+	bursting      bool
+	mirrorProcess mirroredProcess
+	mirrorLost    bool
 }
 
-// NewResourceMonitor 创建新的资源监控器
+// NewResourceMonitor 创建新的资源监控器，使用 SystemMetrics 读取系统指标
 func NewResourceMonitor(config ResourceConfig) *ResourceMonitor {
+	return NewResourceMonitorWithMetrics(config, SystemMetrics{})
+}
+
+// NewResourceMonitorWithMetrics 创建使用指定指标来源的资源监控器
+func NewResourceMonitorWithMetrics(config ResourceConfig, metrics MetricsProvider) *ResourceMonitor {
 	return &ResourceMonitor{
 		Config: config,
 		stop:   make(chan bool),
 		cleanupDone: make(chan bool),
+		metrics: metrics,
 		AllocatedMemory: make([][]byte, 0),
+		allocator: newMemoryAllocator(config),
+		tempFiles: make(map[string][]string),
+		tempFileSizes: make(map[string]uint64),
+		tempSubdirs: make(map[string][]string),
+		dirFileCounts: make(map[string]int),
 	}
 }
 
 // Start 开始监控资源使用情况
 func (rm *ResourceMonitor) Start() {
-	log.Printf("开始监控资源使用情况...")
-	log.Printf("目标配置: 内存 %.1f%%, CPU %.1f%%, 磁盘 %.1f%%", 
-		rm.Config.MemoryPercent, rm.Config.CPUPercent, rm.Config.DiskPercent)
+	rm.StartContext(context.Background())
+}
+
+// StartContext 开始监控资源使用情况，ctx 结束时与 Stop 一样执行清理。
+// 监控间隔无效时不启动，错误可通过 Err 获取。已在运行时直接返回；
+// 上一次运行结束后可再次调用以重新开始
+func (rm *ResourceMonitor) StartContext(ctx context.Context) {
+	if !rm.beginRun() {
+		logWarnf("监控已在运行，忽略重复的启动")
+		return
+	}
+	rm.run(ctx)
+}
+
+// run 执行一次运行直到停止并完成清理（调用方需已通过 beginRun 开始运行）
+func (rm *ResourceMonitor) run(ctx context.Context) {
+	if err := ValidateInterval(rm.Config.Interval); err != nil {
+		logErrorf("无法启动监控: %v", err)
+		rm.Abort(err)
+		rm.endRun()
+		return
+	}
+
+	rm.markRunStart()
+	logInfof("开始监控资源使用情况...")
+	logInfof("目标配置: 内存 %.1f%%, CPU %.1f%%, 磁盘 %s",
+		rm.Config.MemoryPercent, rm.Config.CPUPercent, rm.formatDiskTargets())
+
+	if err := rm.enterCgroup(); err != nil {
+		logErrorf("无法启动监控: %v", err)
+		rm.Abort(err)
+		rm.endRun()
+		return
+	}
+	rm.startPprof()
+	rm.applyGCPercent()
+	rm.detectCPUQuota()
+	rm.applyMemoryRlimit()
+	rm.allocateBaseline()
+	rm.warmup(ctx)
+	rm.cpuSamplerWg.Add(1)
+	go rm.runCPUSampler(ctx)
+	rm.startMemoryAccess()
+	rm.startMemoryVerify()
+	rm.startMemoryOOM()
+	rm.startGPU()
 
 	ticker := time.NewTicker(rm.Config.Interval)
 	defer ticker.Stop()
 
+	// 设置 ReportInterval 时按独立的间隔输出使用情况
+	var reportC <-chan time.Time
+	if rm.Config.ReportInterval > 0 {
+		reportTicker := time.NewTicker(rm.Config.ReportInterval)
+		defer reportTicker.Stop()
+		reportC = reportTicker.C
+	}
+
+	// 设置 ConvergeDeadline 时到期检查是否已达到目标
+	var deadlineC <-chan time.Time
+	if rm.Config.ConvergeDeadline > 0 {
+		deadlineTimer := time.NewTimer(rm.Config.ConvergeDeadline)
+		defer deadlineTimer.Stop()
+		deadlineC = deadlineTimer.C
+	}
+
 	for {
 		select {
 		case <-ticker.C:
-			rm.monitorAndAdjust()
+			rm.safeMonitorAndAdjust()
+		case <-reportC:
+			rm.logUsage()
+		case <-deadlineC:
+			rm.checkConvergence()
+		case <-ctx.Done():
+			logInfof("上下文已结束: %v", ctx.Err())
+			// 以 context.WithCancelCause 附带原因取消时按错误退出；
+			// 否则关闭停止通道，使进行中的调整和后续的 Stop 调用都能感知
+			if cause := context.Cause(ctx); cause != nil && cause != ctx.Err() {
+				rm.Abort(cause)
+			} else {
+				rm.closeStop()
+			}
+			rm.shutdown()
+			return
 		case <-rm.stop:
-			log.Println("停止监控")
-			rm.cleanupAllResources()
-			close(rm.cleanupDone)
+			rm.shutdown()
 			return
 		}
 	}
 }
 
-// Stop 停止监控
-func (rm *ResourceMonitor) Stop() {
+// shutdown 停止监控并结束本次运行：因错误退出时按 NoCleanupOnError 决定是否保留资源，
+// 正常停止时逐步释放后清理。上下文结束和 Stop/Abort 两种退出方式共用
+func (rm *ResourceMonitor) shutdown() {
+	rm.endProgress()
+	logInfof("停止监控")
+	if err := rm.Err(); err != nil {
+		rm.cleanupOnError(err)
+	} else {
+		rm.rampDown()
+		rm.cleanupAllResources()
+	}
+	rm.stopPprof()
+	rm.notifyStopped()
+	rm.endRun()
+}
+
+// warmup 预热阶段：在 WarmupDuration 内采样CPU以建立基线，期间不做任何调整。
+// CPU使用率首次读取返回的是瞬时值，直接用于调整会导致第一次启动过多的CPU负载
+func (rm *ResourceMonitor) warmup(ctx context.Context) {
+	if rm.Config.WarmupDuration <= 0 {
+		return
+	}
+
+	logInfof("预热中，采样CPU基线 %v...", rm.Config.WarmupDuration)
+	if _, err := rm.metricsProvider().CPU(); err != nil {
+		logErrorf("获取CPU信息失败: %v", err)
+	}
+
 	select {
+	case <-time.After(rm.Config.WarmupDuration):
 	case <-rm.stop:
 		return
-	default:
-		close(rm.stop)
+	case <-ctx.Done():
+		return
+	}
+
+	baseline, err := rm.metricsProvider().CPU()
+	if err != nil {
+		logErrorf("获取CPU基线失败: %v", err)
+		return
 	}
+	logInfof("预热完成，CPU基线 %.1f%%", baseline)
+}
+
+// closeStop 关闭停止通道，可安全地重复调用
+func (rm *ResourceMonitor) closeStop() {
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
+	rm.stopOnce.Do(func() {
+		select {
+		case <-rm.stop:
+			// 已通过 GetStopChannel 在外部关闭
+		default:
+			close(rm.stop)
+		}
+	})
+}
+
+// Stop 停止监控
+func (rm *ResourceMonitor) Stop() {
+	rm.closeStop()
 	
 	// 等待清理完成
 	select {
-	case <-rm.cleanupDone:
-		log.Println("资源清理已完成")
-	case <-time.After(60 * time.Second):
-		log.Println("清理超时，强制退出")
+	case <-rm.Done():
+		logInfof("资源清理已完成")
+	case <-time.After(cleanupTimeout):
+		logErrorf("清理超时，强制退出")
 	}
 }
 
 // GetStopChannel 获取停止通道（用于测试）
 func (rm *ResourceMonitor) GetStopChannel() chan bool {
+	rm.lifecycleMutex.Lock()
+	defer rm.lifecycleMutex.Unlock()
+
 	return rm.stop
 }
 
-// AdjustDiskUsage 调整磁盘使用（导出用于测试）
-func (rm *ResourceMonitor) AdjustDiskUsage(currentPercent float64, diskInfo *disk.UsageStat) {
-	rm.adjustDiskUsage(currentPercent, diskInfo)
+// MonitorAndAdjust 执行一次监控和调整（导出用于测试，可配合 NewResourceMonitorWithMetrics 使用）
+func (rm *ResourceMonitor) MonitorAndAdjust() {
+	rm.monitorAndAdjust()
+}
+
+// AdjustDiskUsage 调整磁盘使用（导出用于测试，作用于第一个磁盘目标）
+func (rm *ResourceMonitor) AdjustDiskUsage(currentPercent float64, diskInfo *disk.UsageStat) error {
+	return rm.adjustDiskUsage(rm.diskTargets()[0], currentPercent, diskInfo)
+}
+
+// AdjustDiskTarget 调整指定磁盘目标的使用（导出用于测试）
+func (rm *ResourceMonitor) AdjustDiskTarget(target DiskTarget, currentPercent float64, diskInfo *disk.UsageStat) error {
+	return rm.adjustDiskUsage(target, currentPercent, diskInfo)
 }
 
 // AdjustCPUUsage 调整CPU使用（导出用于测试）
@@ -130,15 +646,48 @@ func (rm *ResourceMonitor) stopCPULoad() {
 	defer rm.cpuLoadMutex.Unlock()
 	
 	if rm.ActiveCPULoad {
-		log.Println("正在停止CPU负载...")
+		logInfof("正在停止CPU负载...")
 		rm.stopCPULoadInternal()
-		log.Println("CPU负载已停止")
+		logInfof("CPU负载已停止")
 	}
 }
 
 // startCPULoad 开始CPU负载（保持兼容性）
 func (rm *ResourceMonitor) startCPULoad() {
-	rm.adjustCPUWorkers(runtime.NumCPU())
+	rm.adjustCPUWorkers(rm.cpuCount())
+}
+
+// cpuCores CPU目标换算所依据的核心数，cgroup 配额可能不是整数
+func (rm *ResourceMonitor) cpuCores() float64 {
+	if rm.Config.CPUCount > 0 {
+		return float64(rm.Config.CPUCount)
+	}
+	if rm.cgroupCPUs > 0 && rm.cgroupCPUs < float64(runtime.NumCPU()) {
+		return rm.cgroupCPUs
+	}
+	return float64(runtime.NumCPU())
+}
+
+// cpuCount CPU工作线程计算所依据的核心数，按 cpuCores 向上取整
+func (rm *ResourceMonitor) cpuCount() int {
+	return int(math.Ceil(rm.cpuCores()))
+}
+
+// detectCPUQuota 启用 RespectCgroups 时读取 cgroup CPU 配额
+func (rm *ResourceMonitor) detectCPUQuota() {
+	if !rm.Config.RespectCgroups || rm.Config.CPUCount > 0 {
+		return
+	}
+
+	quota, err := cgroupCPUQuota(rm.cgroupDir())
+	if err != nil {
+		logWarnf("读取 cgroup CPU 配额失败: %v", err)
+		return
+	}
+	rm.cgroupCPUs = quota
+	if quota > 0 && quota < float64(runtime.NumCPU()) {
+		logInfof("检测到 cgroup CPU 配额 %.2f 核 (NumCPU: %d)，按配额计算CPU负载", quota, runtime.NumCPU())
+	}
 }
 
 // monitorAndAdjust 监控并调整资源使用
@@ -149,9 +698,17 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 	
-	memInfo, err := mem.VirtualMemory()
-	if err != nil {
-		log.Printf("获取内存信息失败: %v", err)
+	// 指标连续读取失败时跳过若干次调整
+	if rm.skipTicks > 0 {
+		rm.skipTicks--
+		return
+	}
+
+	// 读取超时的资源沿用上一次的测量值，并在本次调整中跳过
+	last := rm.LastMeasurement()
+	memInfo, err := rm.metricsProvider().Memory()
+	if err != nil && !metricTimedOut("内存", err) {
+		rm.metricReadFailed("内存", err)
 		return
 	}
 
@@ -161,10 +718,15 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 
-	cpuPercent, err := cpu.Percent(0, false)
+	cpuAvailable := true
+	currentCPUPercent, err := rm.readCPUPercent()
 	if err != nil {
-		log.Printf("获取CPU信息失败: %v", err)
-		return
+		if !metricTimedOut("CPU", err) {
+			rm.metricReadFailed("CPU", err)
+			return
+		}
+		cpuAvailable = false
+		currentCPUPercent = last.CPUPercent
 	}
 
 	select {
@@ -173,26 +735,95 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 
-	diskInfo, err := disk.Usage("/")
-	if err != nil {
-		log.Printf("获取磁盘信息失败: %v", err)
+	targets := rm.diskTargets()
+	diskInfos := make([]*disk.UsageStat, len(targets))
+	diskPercents := make([]string, len(targets))
+	for i, target := range targets {
+		diskInfo, err := rm.metricsProvider().Disk(rm.measurePath(target))
+		if err != nil && !metricTimedOut("磁盘", err) {
+			rm.metricReadFailed("磁盘", err)
+			return
+		}
+		diskInfos[i] = diskInfo
+		diskPercents[i] = "超时"
+		if diskInfo != nil {
+			diskPercents[i] = fmt.Sprintf("%.1f%%", diskInfo.UsedPercent)
+		}
+		if len(targets) > 1 {
+			diskPercents[i] = target.Path + " " + diskPercents[i]
+		}
+
+		select {
+		case <-rm.stop:
+			return
+		default:
+		}
+	}
+
+	rm.metricReadSucceeded()
+
+	currentMemPercent := last.MemoryPercent
+	if memInfo != nil {
+		currentMemPercent = memInfo.UsedPercent
+	}
+
+	diskUsed := make([]float64, len(diskInfos))
+	for i, diskInfo := range diskInfos {
+		if diskInfo != nil {
+			diskUsed[i] = diskInfo.UsedPercent
+		} else if i < len(last.DiskPercents) {
+			diskUsed[i] = last.DiskPercents[i]
+		}
+	}
+	rm.recordMeasurement(Measurement{
+		Time:          time.Now(),
+		MemoryPercent: currentMemPercent,
+		CPUPercent:    currentCPUPercent,
+		DiskPercents:  diskUsed,
+	})
+
+	if rm.Config.ReportInterval <= 0 {
+		logDebugf("当前使用情况: 内存 %.1f%%, CPU %.1f%%, 磁盘 %s",
+			currentMemPercent, currentCPUPercent, strings.Join(diskPercents, ", "))
+		rm.logSelfUsage(logDebugf)
+	}
+
+	if rm.watchdog(memInfo, diskInfos) {
 		return
 	}
 
-	select {
-	case <-rm.stop:
+	tickTargets, ok := rm.computeTargets()
+	if !ok {
 		return
-	default:
 	}
+	rm.activeTargets = &tickTargets
+	rm.recordTargets(tickTargets)
+	rm.recordConvergence()
+	rm.reportProgress(rm.LastMeasurement(), tickTargets)
 
-	currentMemPercent := memInfo.UsedPercent
-	currentCPUPercent := cpuPercent[0]
-	currentDiskPercent := diskInfo.UsedPercent
+	if rm.Paused() {
+		return
+	}
+	rm.advanceStartup()
 
-	log.Printf("当前使用情况: 内存 %.1f%%, CPU %.1f%%, 磁盘 %.1f%%", 
-		currentMemPercent, currentCPUPercent, currentDiskPercent)
+	for i, target := range targets {
+		if !rm.stageEnabled(StartupDisk) {
+			break
+		}
+		if diskInfos[i] == nil {
+			continue
+		}
+		target.Percent = tickTargets.DiskPercents[i]
+		if err := rm.adjustDiskUsage(target, diskInfos[i].UsedPercent, diskInfos[i]); err != nil {
+			rm.reportError(err)
+		}
 
-	rm.adjustDiskUsage(currentDiskPercent, diskInfo)
+		select {
+		case <-rm.stop:
+			return
+		default:
+		}
+	}
 	
 	select {
 	case <-rm.stop:
@@ -208,9 +839,9 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 	
-	memInfoAfterDisk, err := mem.VirtualMemory()
-	if err != nil {
-		log.Printf("重新获取内存信息失败: %v", err)
+	memInfoAfterDisk, err := rm.metricsProvider().Memory()
+	if err != nil && !metricTimedOut("内存", err) {
+		rm.metricReadFailed("内存", err)
 		return
 	}
 	
@@ -220,7 +851,9 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 	
-	rm.adjustMemoryUsage(memInfoAfterDisk.UsedPercent, memInfoAfterDisk)
+	if memInfoAfterDisk != nil && rm.stageEnabled(StartupMemory) && !rm.Config.MemoryOOM {
+		rm.adjustMemoryUsage(memInfoAfterDisk.UsedPercent, memInfoAfterDisk)
+	}
 	
 	select {
 	case <-rm.stop:
@@ -228,17 +861,164 @@ func (rm *ResourceMonitor) monitorAndAdjust() {
 	default:
 	}
 	
-	rm.adjustCPUUsage(currentCPUPercent)
+	if cpuAvailable && rm.stageEnabled(StartupCPU) {
+		rm.adjustCPUUsage(currentCPUPercent)
+	}
+	rm.adjustGPUUsage()
+	rm.notifyStarted()
+}
+
+// 指标读取失败退避参数
+const (
+	// backoffStartFailures 连续失败达到该次数后进入退避
+	backoffStartFailures = 2
+	// maxBackoffTicks 退避时最多跳过的调整次数
+	maxBackoffTicks = 32
+)
+
+// metricReadFailed 记录指标读取失败，连续失败时按指数增长跳过后续调整
+func (rm *ResourceMonitor) metricReadFailed(resource string, err error) {
+	rm.metricFailures++
+	if rm.metricFailures < backoffStartFailures {
+		logErrorf("获取%s信息失败: %v", resource, err)
+		return
+	}
+
+	skip := maxBackoffTicks
+	if exponent := rm.metricFailures - backoffStartFailures; exponent < 5 {
+		skip = 1 << uint(exponent)
+	}
+	rm.skipTicks = skip
+
+	// 只在进入退避时输出一次日志，避免每次调整都刷屏
+	if rm.metricFailures == backoffStartFailures {
+		logWarnf("获取%s信息连续失败 %d 次，进入退避模式（最多跳过 %d 次调整）: %v",
+			resource, rm.metricFailures, maxBackoffTicks, err)
+	}
+}
+
+// metricReadSucceeded 指标读取成功，退出退避模式
+func (rm *ResourceMonitor) metricReadSucceeded() {
+	if rm.metricFailures >= backoffStartFailures {
+		logInfof("指标读取已恢复（此前连续失败 %d 次）", rm.metricFailures)
+	}
+	rm.metricFailures = 0
+	rm.skipTicks = 0
+}
+
+// tolerance 获取全局容忍度
+func (rm *ResourceMonitor) tolerance() float64 {
+	if rm.Config.Tolerance > 0 {
+		return rm.Config.Tolerance
+	}
+	return DefaultTolerance
+}
+
+// memoryTolerance 获取内存容忍度
+func (rm *ResourceMonitor) memoryTolerance() float64 {
+	if rm.Config.MemoryTolerance > 0 {
+		return rm.Config.MemoryTolerance
+	}
+	return rm.tolerance()
+}
+
+// cpuTolerance 获取CPU容忍度
+func (rm *ResourceMonitor) cpuTolerance() float64 {
+	if rm.Config.CPUTolerance > 0 {
+		return rm.Config.CPUTolerance
+	}
+	return rm.tolerance()
+}
+
+// diskTolerance 获取磁盘容忍度
+func (rm *ResourceMonitor) diskTolerance() float64 {
+	if rm.Config.DiskTolerance > 0 {
+		return rm.Config.DiskTolerance
+	}
+	return rm.tolerance()
 }
 
 // adjustMemoryUsage 调整内存使用
 func (rm *ResourceMonitor) adjustMemoryUsage(currentPercent float64, memInfo *mem.VirtualMemoryStat) {
-	if currentPercent < rm.Config.MemoryPercent {
-		targetBytes := uint64((rm.Config.MemoryPercent - currentPercent) / 100.0 * float64(memInfo.Total))
-		rm.allocateMemory(targetBytes)
-	} else if currentPercent > rm.Config.MemoryPercent+5 {
+	// 总量为0时无法换算字节数，跳过本次调整而不是静默地什么都不做
+	if memInfo.Total == 0 {
+		logWarnf("内存总量为0，内存信息无效，跳过本次内存调整")
+		return
+	}
+	if rm.keepFreeMemory(memInfo) {
+		return
+	}
+
+	if rm.Config.MemoryBasis == MemoryBasisAvailable && !rm.Config.LeakMode {
+		rm.adjustMemoryToAvailable(memInfo)
+		return
+	}
+
+	currentPercent = rm.reservedMemoryPercent(currentPercent, memInfo.Total)
+	targetPercent := rm.memoryTargetPercent()
+	if currentPercent < targetPercent {
+		targetBytes := uint64((targetPercent - currentPercent) / 100.0 * float64(memInfo.Total))
+		bytes := rm.freeMemoryLimit(rm.leakLimit(rm.scaleByGain(targetBytes)), memInfo)
+		logDecision("内存", currentPercent, targetPercent, "分配 %s", FormatBytes(bytes))
+		rm.allocateMemory(bytes)
+	} else if rm.Config.LeakMode {
+		// 泄漏模式下从不释放内存
+		logDecision("内存", currentPercent, targetPercent, "泄漏模式，不释放")
+		return
+	} else if currentPercent > targetPercent+rm.memoryTolerance() {
 		rm.releaseMemory(currentPercent, memInfo)
+	} else {
+		logDecision("内存", currentPercent, targetPercent, "在容差 ±%.1f%% 内，保持不变", rm.memoryTolerance())
+	}
+}
+
+// keepFreeMemory 设置 MinFreeMemoryBytes 时，可用内存不足则释放差额（泄漏模式下只停止增长）。
+// 返回 true 表示本次不应继续调整内存
+func (rm *ResourceMonitor) keepFreeMemory(memInfo *mem.VirtualMemoryStat) bool {
+	minFree := rm.Config.MinFreeMemoryBytes
+	if minFree == 0 || memInfo.Available >= minFree {
+		return false
+	}
+
+	deficit := minFree - memInfo.Available
+	logDebugf("可用内存 %s 低于保留量 %s", FormatBytes(memInfo.Available), FormatBytes(minFree))
+	if rm.Config.LeakMode {
+		return true
+	}
+
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	if allocated := rm.getTotalAllocatedMemory(); deficit > allocated {
+		deficit = allocated
+	}
+	if deficit > 0 {
+		rm.releaseBytes(deficit)
+	}
+	return true
+}
+
+// freeMemoryLimit 按 MinFreeMemoryBytes 限制本次分配的字节数，使分配后仍保留足够的可用内存
+func (rm *ResourceMonitor) freeMemoryLimit(bytes uint64, memInfo *mem.VirtualMemoryStat) uint64 {
+	minFree := rm.Config.MinFreeMemoryBytes
+	if minFree == 0 {
+		return bytes
+	}
+	if memInfo.Available <= minFree {
+		return 0
+	}
+	if headroom := memInfo.Available - minFree; bytes > headroom {
+		return headroom
+	}
+	return bytes
+}
+
+// leakLimit 泄漏模式下按 LeakRateBytes 限制每次调整的增长量
+func (rm *ResourceMonitor) leakLimit(bytes uint64) uint64 {
+	if rm.Config.LeakMode && rm.Config.LeakRateBytes > 0 && bytes > rm.Config.LeakRateBytes {
+		return rm.Config.LeakRateBytes
 	}
+	return bytes
 }
 
 // releaseMemory 释放内存
@@ -251,85 +1031,218 @@ func (rm *ResourceMonitor) releaseMemory(currentPercent float64, memInfo *mem.Vi
 	}
 	
 	// 计算需要释放的内存
-	targetReleaseBytes := uint64((currentPercent - rm.Config.MemoryPercent) / 100.0 * float64(memInfo.Total))
+	targetReleaseBytes := rm.scaleByGain(uint64((currentPercent - rm.memoryTargetPercent()) / 100.0 * float64(memInfo.Total)))
 	currentAllocated := rm.getTotalAllocatedMemory()
 	
 	if targetReleaseBytes > currentAllocated {
 		targetReleaseBytes = currentAllocated
 	}
 	
-	// 释放内存
+	logDecision("内存", currentPercent, rm.memoryTargetPercent(), "释放 %s (已分配 %s)",
+		FormatBytes(targetReleaseBytes), FormatBytes(currentAllocated))
+	rm.releaseBytes(targetReleaseBytes)
+}
+
+// releaseBytes 按 MemoryReleasePolicy 的顺序释放 targetReleaseBytes 字节（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) releaseBytes(targetReleaseBytes uint64) {
 	releasedBytes := uint64(0)
-	for i := len(rm.AllocatedMemory) - 1; i >= 0 && releasedBytes < targetReleaseBytes; i-- {
+	freed := make(map[int]bool)
+	for _, i := range rm.releaseOrder() {
+		if releasedBytes >= targetReleaseBytes {
+			break
+		}
 		chunkSize := uint64(len(rm.AllocatedMemory[i]))
 		if releasedBytes+chunkSize <= targetReleaseBytes {
-			rm.AllocatedMemory = rm.AllocatedMemory[:i]
+			rm.freeChunk(rm.AllocatedMemory[i])
+			freed[i] = true
 			releasedBytes += chunkSize
 		} else {
-			// 部分释放
+			// 部分释放，保留的大小会向上对齐到页大小
 			remainingBytes := targetReleaseBytes - releasedBytes
-			rm.AllocatedMemory[i] = rm.AllocatedMemory[i][:remainingBytes]
-			releasedBytes += remainingBytes
+			rm.shrinkChunk(i, chunkSize-remainingBytes)
+			releasedBytes += chunkSize - uint64(len(rm.AllocatedMemory[i]))
+			break
 		}
 	}
+	rm.removeChunks(freed)
 	
-	log.Printf("释放内存: %d bytes", releasedBytes)
+	logInfof("释放内存: %d bytes, 剩余已分配 %d bytes (%d 块)",
+		releasedBytes, rm.getTotalAllocatedMemory(), len(rm.AllocatedMemory))
 	
+	if !rm.forcedGCEnabled() {
+		return
+	}
+
+	// 累计释放量达到阈值后将内存归还操作系统，否则 UsedPercent 不会及时下降，
+	// 导致下一轮继续释放而过冲
+	rm.releasedSinceFree += releasedBytes
+	if rm.releasedSinceFree >= rm.freeOSMemoryThreshold() {
+		freeOSMemory()
+		rm.releasedSinceFree = 0
+		return
+	}
+
 	// 强制垃圾回收
-	runtime.GC()
+	forceGC()
+}
+
+// freeOSMemoryThreshold 获取归还操作系统内存的阈值
+func (rm *ResourceMonitor) freeOSMemoryThreshold() uint64 {
+	if rm.Config.FreeOSMemoryThreshold > 0 {
+		return rm.Config.FreeOSMemoryThreshold
+	}
+	return DefaultFreeOSMemoryThreshold
+}
+
+// memoryAllocator 获取内存分配器
+func (rm *ResourceMonitor) memoryAllocator() memoryAllocator {
+	if rm.allocator == nil {
+		rm.allocator = newMemoryAllocator(rm.Config)
+	}
+	return rm.allocator
+}
+
+// freeChunk 释放单个内存块
+func (rm *ResourceMonitor) freeChunk(chunk []byte) {
+	if err := rm.memoryAllocator().free(chunk); err != nil {
+		logErrorf("释放内存块失败: %v", err)
+	}
+}
+
+// shrinkChunk 将第 i 个内存块缩小到 keepBytes（向上对齐到页大小）。
+// 重新分配较小的块并复制内容后释放原块：仅对堆内存重新切片时底层数组仍被引用，
+// 释放的部分无法被GC回收
+func (rm *ResourceMonitor) shrinkChunk(i int, keepBytes uint64) {
+	keepBytes = pageAlign(keepBytes)
+	if keepBytes >= uint64(len(rm.AllocatedMemory[i])) {
+		return
+	}
+
+	smaller, err := rm.memoryAllocator().alloc(keepBytes)
+	if err != nil {
+		logErrorf("重新分配内存块失败: %v", err)
+		return
+	}
+	copy(smaller, rm.AllocatedMemory[i])
+	rm.freeChunk(rm.AllocatedMemory[i])
+	rm.AllocatedMemory[i] = smaller
 }
 
 // adjustCPUUsage 调整CPU使用
 func (rm *ResourceMonitor) adjustCPUUsage(currentPercent float64) {
+	if rm.CPUSuspended() {
+		return
+	}
+
 	// 计算目标工作线程数量
 	targetWorkers := 0
-	tolerance := 5.0 // 容忍度，避免频繁调整
+	tolerance := rm.cpuTolerance() // 容忍度，避免频繁调整
 	
-	if currentPercent < rm.Config.CPUPercent - tolerance {
+	targetPercent := rm.cpuTargetPercent()
+
+	if rm.tokenControl() {
+		rm.adjustCPUTokens(currentPercent, targetPercent)
+		return
+	}
+
+	if currentPercent < targetPercent - tolerance {
 		// CPU使用率低于目标，需要增加负载
+		if rm.inCPUCooldown() {
+			logDecision("CPU", currentPercent, targetPercent, "处于冷却期，暂不启动负载")
+			return
+		}
+		if rm.Config.CPUCoreLoad > 0 {
+			rm.decideCPULoad(currentPercent, targetPercent, targetPercent / 100.0 * rm.cpuCores())
+			return
+		}
 		// 根据目标CPU使用率计算工作线程数
-		targetWorkers = int(rm.Config.CPUPercent / 100.0 * float64(runtime.NumCPU()))
+		load := targetPercent / 100.0 * rm.cpuCores()
+		targetWorkers = int(load)
 		if targetWorkers < 1 {
-			targetWorkers = 1
+			// 目标不足一个核心（如单核上的低百分比）时使用一个占空比工作线程，
+			// 而不是让一个核心满载
+			rm.decideCPULoad(currentPercent, targetPercent, load)
+			return
 		}
-		if targetWorkers > runtime.NumCPU() {
-			targetWorkers = runtime.NumCPU()
+		if targetWorkers > rm.cpuCount() {
+			targetWorkers = rm.cpuCount()
 		}
-	} else if currentPercent > rm.Config.CPUPercent + tolerance {
+	} else if currentPercent > targetPercent + tolerance {
 		// CPU使用率高于目标，减少或停止负载
 		targetWorkers = 0
 	} else {
 		// 在目标范围内，保持当前状态
+		logDecision("CPU", currentPercent, targetPercent, "在容差 ±%.1f%% 内，保持负载 %.2f 核", tolerance, rm.cpuLoad())
 		return
 	}
 	
-	rm.adjustCPUWorkers(targetWorkers)
+	rm.decideCPULoad(currentPercent, targetPercent, float64(targetWorkers))
+}
+
+// inCPUCooldown 是否处于停止CPU负载后的冷却期
+func (rm *ResourceMonitor) inCPUCooldown() bool {
+	if rm.Config.CPUCooldown <= 0 {
+		return false
+	}
+
+	rm.cpuLoadMutex.Lock()
+	defer rm.cpuLoadMutex.Unlock()
+
+	if rm.ActiveCPULoad || rm.cpuStoppedAt.IsZero() {
+		return false
+	}
+	return time.Since(rm.cpuStoppedAt) < rm.Config.CPUCooldown
 }
 
 // adjustCPUWorkers 调整CPU工作线程数量
 func (rm *ResourceMonitor) adjustCPUWorkers(targetWorkers int) {
+	rm.adjustCPULoad(float64(targetWorkers))
+}
+
+// adjustCPULoad 调整CPU负载，load 为需要保持忙碌的核心数，
+// 整数部分各由一个满载工作线程承担，小数部分由一个按占空比运行的工作线程承担
+func (rm *ResourceMonitor) adjustCPULoad(load float64) {
 	rm.cpuLoadMutex.Lock()
 	defer rm.cpuLoadMutex.Unlock()
 	
-	if rm.targetCPUWorkers == targetWorkers {
-		return // 目标数量没有变化
+	if load < 0 {
+		load = 0
+	}
+	if rm.targetCPULoad == load {
+		return // 目标负载没有变化
 	}
 	
+	if rm.tokenControl() && load > 0 {
+		// 令牌桶控制下工作线程数固定，只调整令牌补充速率
+		rm.targetCPULoad = load
+		rm.targetCPUWorkers = rm.cpuCount()
+		rm.cpuTokens.setRate(load)
+		if !rm.ActiveCPULoad {
+			logInfof("启动CPU负载 (令牌桶, 工作线程: %d, 负载: %.2f 核)", rm.targetCPUWorkers, load)
+			rm.startCPULoadInternal()
+		}
+		rm.currentCPULoad = load
+		return
+	}
+
+	targetWorkers := int(math.Ceil(load))
+	rm.targetCPULoad = load
 	rm.targetCPUWorkers = targetWorkers
 	
 	if targetWorkers == 0 {
 		// 停止所有CPU负载
 		if rm.ActiveCPULoad {
-			log.Printf("停止CPU负载 (当前工作线程: %d)", rm.currentCPUWorkers)
+			logInfof("停止CPU负载 (当前工作线程: %d)", rm.currentCPUWorkers)
 			rm.stopCPULoadInternal()
+			rm.cpuStoppedAt = time.Now()
 		}
 	} else {
 		// 启动或调整CPU负载
 		if !rm.ActiveCPULoad {
-			log.Printf("启动CPU负载 (目标工作线程: %d)", targetWorkers)
+			logInfof("启动CPU负载 (目标工作线程: %d, 负载: %.2f 核)", targetWorkers, load)
 			rm.startCPULoadInternal()
-		} else if rm.currentCPUWorkers != targetWorkers {
-			log.Printf("调整CPU负载 (当前: %d -> 目标: %d)", rm.currentCPUWorkers, targetWorkers)
+		} else if rm.currentCPULoad != load {
+			logInfof("调整CPU负载 (当前: %d -> 目标: %d, 负载: %.2f 核)", rm.currentCPUWorkers, targetWorkers, load)
 			// 重启CPU负载以调整线程数
 			rm.stopCPULoadInternal()
 			rm.startCPULoadInternal()
@@ -346,12 +1259,41 @@ func (rm *ResourceMonitor) startCPULoadInternal() {
 	rm.ActiveCPULoad = true
 	rm.cpuLoadStop = make(chan bool)
 	rm.currentCPUWorkers = rm.targetCPUWorkers
+	rm.currentCPULoad = rm.targetCPULoad
 	
-	// 启动指定数量的CPU worker
-	for i := 0; i < rm.targetCPUWorkers; i++ {
+	if rm.tokenControl() {
+		types := rm.cpuWorkloadTypes(rm.targetCPUWorkers)
+		rm.logWorkloadTypes(types)
+		cpus := rm.workerCPUs()
+		for i, kind := range types {
+			rm.cpuLoadWg.Add(1)
+			go rm.cpuTokenWorker(kind, workerCPU(cpus, i), &rm.cpuTokens, rm.cpuLoadStop)
+		}
+		return
+	}
+
+	// 启动指定数量的CPU worker，最后一个承担负载的小数部分
+	duties := cpuWorkerDuties(rm.targetCPULoad)
+	types := rm.cpuWorkloadTypes(len(duties))
+	rm.logWorkloadTypes(types)
+	cpus := rm.workerCPUs()
+	for i, duty := range duties {
 		rm.cpuLoadWg.Add(1)
-		go rm.cpuWorker(i)
+		go rm.cpuWorker(i, types[i], workerCPU(cpus, i), duty, rm.cpuLoadStop)
+	}
+}
+
+// cpuWorkerDuties 将负载（核心数）拆分为各工作线程的占空比
+func cpuWorkerDuties(load float64) []float64 {
+	workers := int(math.Ceil(load))
+	duties := make([]float64, workers)
+	for i := range duties {
+		duties[i] = 1.0
+	}
+	if fraction := load - math.Floor(load); fraction > 0 && workers > 0 {
+		duties[workers-1] = fraction
 	}
+	return duties
 }
 
 // stopCPULoadInternal 内部停止CPU负载方法
@@ -373,40 +1315,62 @@ func (rm *ResourceMonitor) stopCPULoadInternal() {
 	select {
 	case <-done:
 	case <-time.After(3 * time.Second):
-		log.Println("CPU负载停止超时，强制停止")
+		logErrorf("CPU负载停止超时，强制停止")
 	}
 	
 	rm.ActiveCPULoad = false
 	rm.currentCPUWorkers = 0
+	rm.currentCPULoad = 0
 	rm.cpuLoadStop = make(chan bool)
 	rm.cpuLoadWg = sync.WaitGroup{}
 }
 
-// cpuWorker CPU工作协程
-func (rm *ResourceMonitor) cpuWorker(id int) {
+// dutyCyclePeriod 占空比工作线程的周期
+const dutyCyclePeriod = 100 * time.Millisecond
+
+// cpuWorker CPU工作协程，按 kind 类型计算，duty 小于1时按占空比交替计算和休眠；cpu 不为 -1 时绑定到该核心
+func (rm *ResourceMonitor) cpuWorker(id int, kind string, cpu int, duty float64, stop chan bool) {
 	defer rm.cpuLoadWg.Done()
+	pinCPUWorker(cpu)
 	
+	work := newCPUWorkload(kind)
+	if duty < 1 {
+		dutyCycleWorker(duty, work, stop)
+		return
+	}
+
 	for {
 		select {
-		case <-rm.cpuLoadStop:
+		case <-stop:
 			return
 		default:
-			// 持续执行CPU密集型计算
-			sum := 0.0
-			for i := 0; i < 1000000; i++ {
-				sum += float64(i) * 3.14159
-				sum = sum * 1.001
-				
-				// 每1000次迭代检查一次停止信号
-				if i%1000 == 0 {
-					select {
-					case <-rm.cpuLoadStop:
-						return
-					default:
-					}
-				}
+			// 持续执行CPU密集型计算，每批之间检查一次停止信号
+			work()
+		}
+	}
+}
+
+// dutyCycleWorker 在每个周期内忙碌 duty 比例的时间，其余时间休眠
+func dutyCycleWorker(duty float64, work func(), stop chan bool) {
+	busy := time.Duration(duty * float64(dutyCyclePeriod))
+	idle := dutyCyclePeriod - busy
+
+	for {
+		start := time.Now()
+		for time.Since(start) < busy {
+			work()
+
+			select {
+			case <-stop:
+				return
+			default:
 			}
-			_ = sum
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(idle):
 		}
 	}
 }
@@ -421,14 +1385,48 @@ func (rm *ResourceMonitor) doCPUWork() {
 	_ = sum
 }
 
-// adjustDiskUsage 调整磁盘使用
-func (rm *ResourceMonitor) adjustDiskUsage(currentPercent float64, diskInfo *disk.UsageStat) {
-	if currentPercent < rm.Config.DiskPercent {
-		targetBytes := uint64((rm.Config.DiskPercent - currentPercent) / 100.0 * float64(diskInfo.Total))
-		rm.createTempFiles(targetBytes)
-	} else if currentPercent > rm.Config.DiskPercent+5 {
-		rm.cleanupTempFiles()
+// adjustDiskUsage 调整磁盘使用，创建临时文件失败时返回 *DiskError
+func (rm *ResourceMonitor) adjustDiskUsage(target DiskTarget, currentPercent float64, diskInfo *disk.UsageStat) error {
+	if rm.Config.DiskMeasureOnly {
+		return nil
+	}
+
+	dir := rm.writeDir(target)
+	if !rm.diskWriteEnabled(dir) {
+		return nil
+	}
+	// 总量为0时无法换算字节数，跳过本次调整而不是静默地什么都不做
+	if diskInfo.Total == 0 {
+		logWarnf("磁盘 %s 总量为0，磁盘信息无效，跳过本次磁盘调整", rm.measurePath(target))
+		return nil
+	}
+
+	resource := "磁盘 " + dir
+	if currentPercent < target.Percent {
+		targetBytes := uint64((target.Percent - currentPercent) / 100.0 * float64(diskInfo.Total))
+		logDecision(resource, currentPercent, target.Percent, "写入 %s", FormatBytes(rm.scaleByGain(targetBytes)))
+		if err := rm.createTempFiles(dir, rm.scaleByGain(targetBytes)); err != nil {
+			err = &DiskError{Dir: dir, Err: err}
+			rm.diskWriteFailed(dir, err)
+			return err
+		}
+		rm.diskWriteSucceeded(dir)
+	} else if currentPercent > target.Percent+rm.diskTolerance() {
+		// 只删除超出部分对应的文件，避免全部删除后下一次调整又重新写入
+		excessBytes := uint64((currentPercent - target.Percent) / 100.0 * float64(diskInfo.Total))
+		rm.diskMutex.Lock()
+		tracked := len(rm.tempFiles[dir])
+		rm.diskMutex.Unlock()
+		if tracked == 0 {
+			logDecision(resource, currentPercent, target.Percent, "没有可释放的临时文件")
+			return nil
+		}
+		logDecision(resource, currentPercent, target.Percent, "释放 %s", FormatBytes(excessBytes))
+		rm.releaseTempFiles(dir, excessBytes)
+	} else {
+		logDecision(resource, currentPercent, target.Percent, "在容差 ±%.1f%% 内，保持不变", rm.diskTolerance())
 	}
+	return nil
 }
 
 // allocateMemory 分配内存
@@ -436,45 +1434,161 @@ func (rm *ResourceMonitor) allocateMemory(bytes uint64) {
 	rm.memoryMutex.Lock()
 	defer rm.memoryMutex.Unlock()
 	
-	chunkSize := uint64(100 * 1024 * 1024) // 100MB per chunk
+	if bytes = rm.capMemoryBytes(bytes); bytes == 0 {
+		return
+	}
+	if _, err := rm.allocateChunks(bytes); err != nil {
+		logErrorf("分配内存失败: %v", err)
+		rm.rlimitAllocFailed()
+	}
+}
+
+// capMemoryBytes 根据 MaxMemoryBytes 和进程内存资源限制限制本次分配的字节数（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) capMemoryBytes(bytes uint64) uint64 {
+	maxBytes, limited := rm.maxMemoryBytes()
+	if !limited {
+		return bytes
+	}
+
+	allocated := rm.getTotalAllocatedMemory()
+	if allocated+bytes <= maxBytes {
+		rm.memoryCapped = false
+		return bytes
+	}
+
+	// 仅在首次达到上限时输出日志，避免每次调整都重复
+	if !rm.memoryCapped {
+		rm.memoryCapped = true
+		logWarnf("已达到内存分配上限 %s，不再继续分配", FormatBytes(maxBytes))
+	}
+	if allocated >= maxBytes {
+		return 0
+	}
+	return maxBytes - allocated
+}
+
+// memoryChunkSize 均匀分配时每个内存块的大小
+const memoryChunkSize = 100 * 1024 * 1024
+
+// allocateChunks 按块分配内存（块大小见 nextChunkSize），返回实际分配的字节数（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) allocateChunks(bytes uint64) (uint64, error) {
 	remainingBytes := bytes
 	
 	for remainingBytes > 0 {
-		currentChunk := chunkSize
-		if remainingBytes < chunkSize {
-			currentChunk = remainingBytes
-		}
+		currentChunk := rm.nextChunkSize(remainingBytes)
 		
-		memory := make([]byte, currentChunk)
-		for i := range memory {
-			memory[i] = byte(i % 256)
+		memory, err := rm.newChunk(currentChunk)
+		if err != nil {
+			return bytes - remainingBytes, err
 		}
 		
 		rm.AllocatedMemory = append(rm.AllocatedMemory, memory)
 		remainingBytes -= currentChunk
 		
-		log.Printf("分配内存: %d bytes", currentChunk)
+		logDebugf("分配内存: %d bytes", len(memory))
 	}
+	return bytes, nil
 }
 
-// createTempFiles 创建临时文件
-func (rm *ResourceMonitor) createTempFiles(targetBytes uint64) {
-	rm.diskMutex.Lock()
-	defer rm.diskMutex.Unlock()
-	
-	tempDir := os.TempDir()
+// newChunk 分配一个内存块并按 MemoryFill 写入内容（MemoryNoCommit 时不写入），按需写入块头。
+// 内存块大小对齐到页大小，最多多分配不足一页
+func (rm *ResourceMonitor) newChunk(size uint64) ([]byte, error) {
+	memory, err := rm.memoryAllocator().alloc(pageAlign(size))
+	if err != nil {
+		return nil, err
+	}
+	if !rm.Config.MemoryNoCommit {
+		rm.fillChunk(memory)
+	}
+	rm.tagChunk(memory)
+	return memory, nil
+}
+
+// diskTargets 获取磁盘占用目标，未配置 DiskTargets 时使用 DiskPercent/DiskPath
+func (rm *ResourceMonitor) diskTargets() []DiskTarget {
+	if len(rm.Config.DiskTargets) > 0 {
+		return rm.Config.DiskTargets
+	}
+	return []DiskTarget{{Percent: rm.Config.DiskPercent}}
+}
+
+// formatDiskTargets 格式化磁盘占用目标用于日志输出
+func (rm *ResourceMonitor) formatDiskTargets() string {
+	if len(rm.Config.DiskTargets) == 0 {
+		return fmt.Sprintf("%.1f%%", rm.Config.DiskPercent)
+	}
+	parts := make([]string, 0, len(rm.Config.DiskTargets))
+	for _, target := range rm.Config.DiskTargets {
+		parts = append(parts, fmt.Sprintf("%s %.1f%%", target.Path, target.Percent))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// measurePath 获取磁盘目标用于统计使用率的路径
+func (rm *ResourceMonitor) measurePath(target DiskTarget) string {
+	if target.Path != "" {
+		return target.Path
+	}
+	if rm.Config.DiskPath != "" {
+		return rm.Config.DiskPath
+	}
+	// 统计写入目录所在的文件系统，保证测量和写入的是同一个设备
+	return rm.writeDir(target)
+}
+
+// writeDir 获取磁盘目标写入临时文件的目录
+func (rm *ResourceMonitor) writeDir(target DiskTarget) string {
+	if target.Path != "" {
+		return target.Path
+	}
+	return defaultTempDir()
+}
+
+// defaultTempDir 获取默认临时目录
+func defaultTempDir() string {
 	if testTempDir := os.Getenv("GO_OCCUPY_TEMP_DIR"); testTempDir != "" {
-		tempDir = testTempDir
+		return testTempDir
+	}
+	return os.TempDir()
+}
+
+// filePrefix 获取临时文件名前缀
+func (rm *ResourceMonitor) filePrefix() string {
+	if rm.Config.FilePrefix != "" {
+		return rm.Config.FilePrefix
 	}
+	return DefaultFilePrefix
+}
+
+// tempFileSize 每个临时文件的最大大小
+const tempFileSize = 5 * 1024 * 1024 * 1024
+
+// createTempFiles 在指定目录创建临时文件，写入失败时返回错误。
+// 写入期间只持有 diskWriteMutex，diskMutex 仅在更新记录时获取，使查询不必等待写入完成
+func (rm *ResourceMonitor) createTempFiles(tempDir string, targetBytes uint64) error {
+	rm.diskWriteMutex.Lock()
+	defer rm.diskWriteMutex.Unlock()
 	
+	// 停止通道在再次 Start 时会被替换，开始写入时取一次，之后的写入都以它判断是否停止
+	rm.lifecycleMutex.Lock()
+	stop := rm.stop
+	rm.lifecycleMutex.Unlock()
+
 	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		log.Printf("创建临时目录失败: %v", err)
-		return
+		return fmt.Errorf("创建临时目录失败: %w", err)
 	}
 	
-	fileSize := uint64(5 * 1024 * 1024 * 1024) // 5G per file
+	rm.diskMutex.Lock()
+	targetBytes = rm.capDiskBytes(targetBytes)
+	rm.diskMutex.Unlock()
+	if targetBytes == 0 {
+		return nil
+	}
+
+	fileSize := uint64(tempFileSize)
 	remainingBytes := targetBytes
 	fileIndex := 0
+	limiter := newRateLimiter(rm.Config.DiskWriteMBps)
 	
 	for remainingBytes > 0 {
 		currentFileSize := fileSize
@@ -482,63 +1596,256 @@ func (rm *ResourceMonitor) createTempFiles(targetBytes uint64) {
 			currentFileSize = remainingBytes
 		}
 		
-		fileName := fmt.Sprintf("go_occupy_temp_%d_%d.dat", time.Now().Unix(), fileIndex)
-		filePath := filepath.Join(tempDir, fileName)
-		
-		file, err := os.Create(filePath)
-		if err != nil {
-			log.Printf("创建临时文件失败: %v", err)
-			return
+		// 写入前先计入目录的文件数，避免其他清理在写入期间删除该子目录
+		rm.diskMutex.Lock()
+		fileDir, err := rm.fileDir(tempDir)
+		if err == nil {
+			rm.dirFileCounts[fileDir]++
 		}
-		
-		data := make([]byte, currentFileSize)
-		for i := range data {
-			data[i] = byte(i % 256)
+		rm.diskMutex.Unlock()
+		if err != nil {
+			return err
 		}
+		fileName := fmt.Sprintf("%s%d_%d.dat", rm.filePrefix(), time.Now().UnixNano(), fileIndex)
+		filePath := filepath.Join(fileDir, fileName)
 		
-		if _, err := file.Write(data); err != nil {
-			log.Printf("写入临时文件失败: %v", err)
-			file.Close()
-			os.Remove(filePath)
-			return
+		if err := rm.writeTempFileRetry(filePath, currentFileSize, limiter, stop); err != nil {
+			rm.diskMutex.Lock()
+			rm.dirFileCounts[fileDir]--
+			rm.diskMutex.Unlock()
+			if errors.Is(err, errWriteStopped) {
+				logDebugf("%v: %s", err, fileName)
+				return nil
+			}
+			return err
 		}
 		
-		file.Close()
-		log.Printf("创建临时文件: %s (%d bytes)", fileName, currentFileSize)
+		rm.diskMutex.Lock()
+		rm.tempFiles[tempDir] = append(rm.tempFiles[tempDir], filePath)
+		rm.tempFileSizes[filePath] = currentFileSize
+		rm.tempFileBytes += currentFileSize
+		rm.diskMutex.Unlock()
+		logDebugf("创建临时文件: %s (%d bytes)", fileName, currentFileSize)
 		
 		remainingBytes -= currentFileSize
 		fileIndex++
 	}
+	return nil
+}
+
+// capDiskBytes 根据 MaxDiskBytes 限制本次写入的字节数（调用方需持有 diskMutex）
+func (rm *ResourceMonitor) capDiskBytes(bytes uint64) uint64 {
+	maxBytes := rm.Config.MaxDiskBytes
+	if maxBytes == 0 {
+		return bytes
+	}
+
+	if rm.tempFileBytes+bytes <= maxBytes {
+		rm.diskCapped = false
+		return bytes
+	}
+
+	// 仅在首次达到上限时输出日志，避免每次调整都重复
+	if !rm.diskCapped {
+		rm.diskCapped = true
+		logWarnf("已达到临时文件占用上限 %s，不再继续写入", FormatBytes(maxBytes))
+	}
+	if rm.tempFileBytes >= maxBytes {
+		return 0
+	}
+	return maxBytes - rm.tempFileBytes
 }
 
-// cleanupTempFiles 清理临时文件
-func (rm *ResourceMonitor) cleanupTempFiles() {
+// forgetTempFile 移除对临时文件大小的记录（调用方需持有 diskMutex）
+func (rm *ResourceMonitor) forgetTempFile(filePath string) {
+	rm.tempFileBytes -= rm.tempFileSizes[filePath]
+	delete(rm.tempFileSizes, filePath)
+	if dir := filepath.Dir(filePath); rm.dirFileCounts[dir] > 0 {
+		rm.dirFileCounts[dir]--
+	}
+}
+
+// TempFileBytes 获取当前临时文件占用的总字节数
+func (rm *ResourceMonitor) TempFileBytes() uint64 {
 	rm.diskMutex.Lock()
 	defer rm.diskMutex.Unlock()
-	
-	tempDir := os.TempDir()
-	if testTempDir := os.Getenv("GO_OCCUPY_TEMP_DIR"); testTempDir != "" {
-		tempDir = testTempDir
+
+	return rm.tempFileBytes
+}
+
+// writeChunkSize 写入临时文件时每次写入的块大小
+const writeChunkSize = 4 * 1024 * 1024
+
+// writeTempFile 分块写入指定大小的临时文件，失败或 stop 关闭时删除不完整的文件
+func (rm *ResourceMonitor) writeTempFile(filePath string, size uint64, limiter *rateLimiter, stop <-chan bool) error {
+	file, direct, err := rm.createTempFile(filePath)
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+
+	chunk := size
+	if chunk > writeChunkSize {
+		chunk = writeChunkSize
+	}
+	var data []byte
+	if direct {
+		// 直接I/O要求缓冲区地址和写入长度对齐
+		chunk = alignUp(chunk, directIOAlignment)
+		data = alignedBuffer(int(chunk), directIOAlignment)
+	} else {
+		data = make([]byte, chunk)
+	}
+	random := rm.Config.DiskFillMode == DiskFillRandom
+	switch rm.Config.DiskFillMode {
+	case DiskFillZero, DiskFillRandom:
+	default:
+		for i := range data {
+			data[i] = byte(i % 256)
+		}
+	}
+
+	written := uint64(0)
+	for written < size {
+		// 每写一块检查一次停止信号，使停止时的清理不必等待整个大文件写完
+		if stopRequested(stop) {
+			file.Close()
+			os.Remove(filePath)
+			return errWriteStopped
+		}
+		n := size - written
+		if n > chunk {
+			n = chunk
+		}
+		writeLen := n
+		if direct {
+			// 最后一块不足对齐长度时补齐写入，结束后再截断到实际大小
+			writeLen = alignUp(n, directIOAlignment)
+		}
+		// 随机模式每块重新生成数据，避免被压缩或去重
+		if random {
+			rm.diskRandom().Read(data[:writeLen])
+		}
+		if _, err := file.Write(data[:writeLen]); err != nil {
+			file.Close()
+			os.Remove(filePath)
+			return fmt.Errorf("写入临时文件失败: %w", err)
+		}
+		written += n
+		if !limiter.wait(n, stop) {
+			file.Close()
+			os.Remove(filePath)
+			return errWriteStopped
+		}
+	}
+
+	if direct && written%directIOAlignment != 0 {
+		if err := file.Truncate(int64(size)); err != nil {
+			file.Close()
+			os.Remove(filePath)
+			return fmt.Errorf("截断临时文件失败: %w", err)
+		}
+	}
+
+	if rm.Config.DiskFsync {
+		if err := file.Sync(); err != nil {
+			file.Close()
+			os.Remove(filePath)
+			return fmt.Errorf("同步临时文件失败: %w", err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("关闭临时文件失败: %w", err)
+	}
+	return nil
+}
+
+// diskRandom 获取随机填充使用的随机数生成器（调用方需持有 diskWriteMutex）
+func (rm *ResourceMonitor) diskRandom() *rand.Rand {
+	if rm.diskRand == nil {
+		rm.diskRand = rand.New(rand.NewSource(rm.randomSeed()))
+	}
+	return rm.diskRand
+}
+
+// randomSeed 获取随机数种子，未设置 Seed 时使用当前时间
+func (rm *ResourceMonitor) randomSeed() int64 {
+	if rm.Config.Seed != 0 {
+		return rm.Config.Seed
 	}
+	return time.Now().UnixNano()
+}
+
+// cleanupTempFiles 清理指定目录中已创建的临时文件，正在写入时等待写入结束
+func (rm *ResourceMonitor) cleanupTempFiles(tempDir string) {
+	rm.diskWriteMutex.Lock()
+	defer rm.diskWriteMutex.Unlock()
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
 	
-	pattern := filepath.Join(tempDir, "go_occupy_temp_*.dat")
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		log.Printf("查找临时文件失败: %v", err)
+	files := rm.tempFiles[tempDir]
+	if len(files) == 0 {
 		return
 	}
 	
 	deletedCount := 0
-	for _, file := range matches {
-		if err := os.Remove(file); err != nil {
-			log.Printf("删除临时文件失败: %s, %v", file, err)
+	remaining := make([]string, 0)
+	for _, file := range files {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			logErrorf("删除临时文件失败: %s, %v", file, err)
+			remaining = append(remaining, file)
 		} else {
+			rm.forgetTempFile(file)
 			deletedCount++
 		}
 	}
 	
+	if len(remaining) > 0 {
+		rm.tempFiles[tempDir] = remaining
+	} else {
+		delete(rm.tempFiles, tempDir)
+	}
+	rm.removeEmptySubdirs(tempDir)
+
+	if deletedCount > 0 {
+		logInfof("清理临时文件: %s %d 个", tempDir, deletedCount)
+	}
+}
+
+// releaseTempFiles 从最后创建的文件开始删除指定目录中的临时文件，直到释放至少 bytes 字节或没有可删除的文件
+func (rm *ResourceMonitor) releaseTempFiles(tempDir string, bytes uint64) {
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
+
+	files := rm.tempFiles[tempDir]
+	var released uint64
+	deletedCount := 0
+	failed := make([]string, 0)
+	for len(files) > 0 && released < bytes {
+		file := files[len(files)-1]
+		files = files[:len(files)-1]
+		size := rm.tempFileSizes[file]
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			logErrorf("删除临时文件失败: %s, %v", file, err)
+			failed = append(failed, file)
+			continue
+		}
+		rm.forgetTempFile(file)
+		released += size
+		deletedCount++
+	}
+
+	files = append(files, failed...)
+	if len(files) > 0 {
+		rm.tempFiles[tempDir] = files
+	} else {
+		delete(rm.tempFiles, tempDir)
+	}
+	rm.removeEmptySubdirs(tempDir)
+
 	if deletedCount > 0 {
-		log.Printf("清理临时文件: %d 个", deletedCount)
+		logInfof("释放临时文件: %s %d 个 (%s)，剩余 %d 个", tempDir, deletedCount, FormatBytes(released), len(files))
 	}
 }
 
@@ -547,18 +1854,40 @@ func (rm *ResourceMonitor) cleanupMemory() {
 	rm.memoryMutex.Lock()
 	defer rm.memoryMutex.Unlock()
 	
-	if len(rm.AllocatedMemory) == 0 {
+	if len(rm.AllocatedMemory) == 0 && len(rm.baselineMemory) == 0 {
 		return
 	}
+	rm.freeBaseline()
 	
 	totalBytes := rm.getTotalAllocatedMemory()
+	for _, chunk := range rm.AllocatedMemory {
+		rm.freeChunk(chunk)
+	}
 	rm.AllocatedMemory = make([][]byte, 0)
 	
-	log.Printf("清理内存: %d bytes", totalBytes)
-	runtime.GC()
+	logInfof("清理内存: %d bytes", totalBytes)
+	if rm.forcedGCEnabled() {
+		forceGC()
+	}
+}
+
+// AllocatedBytes 获取当前已分配的内存字节数（并发安全）
+func (rm *ResourceMonitor) AllocatedBytes() uint64 {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	return rm.getTotalAllocatedMemory()
+}
+
+// AllocatedChunks 获取当前已分配的内存块数量（并发安全）
+func (rm *ResourceMonitor) AllocatedChunks() int {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	return len(rm.AllocatedMemory)
 }
 
-// getTotalAllocatedMemory 获取总分配内存
+// getTotalAllocatedMemory 获取总分配内存（调用方需持有 memoryMutex）
 func (rm *ResourceMonitor) getTotalAllocatedMemory() uint64 {
 	total := uint64(0)
 	for _, memory := range rm.AllocatedMemory {
@@ -567,55 +1896,72 @@ func (rm *ResourceMonitor) getTotalAllocatedMemory() uint64 {
 	return total
 }
 
-// cleanupAllTempFiles 清理所有临时文件
+// cleanupAllTempFiles 清理所有磁盘目标目录中的临时文件，正在写入时等待写入结束
 func (rm *ResourceMonitor) cleanupAllTempFiles() {
-	tempDir := os.TempDir()
-	if testTempDir := os.Getenv("GO_OCCUPY_TEMP_DIR"); testTempDir != "" {
-		tempDir = testTempDir
-	}
+	rm.diskWriteMutex.Lock()
+	defer rm.diskWriteMutex.Unlock()
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
 	
-	pattern := filepath.Join(tempDir, "go_occupy_temp_*.dat")
-	matches, err := filepath.Glob(pattern)
-	if err != nil {
-		log.Printf("查找临时文件失败: %v", err)
-		return
+	dirs := make(map[string]bool)
+	for _, target := range rm.diskTargets() {
+		dirs[rm.writeDir(target)] = true
+	}
+	for dir := range rm.tempFiles {
+		dirs[dir] = true
 	}
 	
 	deletedCount := 0
-	for _, file := range matches {
-		if err := os.Remove(file); err != nil {
-			log.Printf("删除临时文件失败: %s, %v", file, err)
-		} else {
-			deletedCount++
+	for tempDir := range dirs {
+		removed, failed, _, err := removeTempFiles(tempDir, rm.filePrefix(), nil)
+		if err != nil {
+			logErrorf("%v", err)
+			rm.cleanupErrors++
+			continue
 		}
+		deletedCount += removed
+		rm.cleanupErrors += failed
 	}
+	rm.tempFiles = make(map[string][]string)
+	rm.tempFileSizes = make(map[string]uint64)
+	rm.tempSubdirs = make(map[string][]string)
+	rm.dirFileCounts = make(map[string]int)
+	rm.tempFileBytes = 0
 	
 	if deletedCount > 0 {
-		log.Printf("清理所有临时文件: %d 个", deletedCount)
+		logInfof("清理所有临时文件: %d 个", deletedCount)
 	}
 }
 
 // cleanupAllResources 清理所有资源
 func (rm *ResourceMonitor) cleanupAllResources() {
-	log.Println("开始清理所有资源...")
+	logInfof("开始清理所有资源...")
 	
 	// 停止CPU负载
-	log.Println("正在停止CPU负载...")
+	logInfof("正在停止CPU负载...")
 	rm.stopCPULoad()
+	rm.stopMemoryOOM()
+	rm.stopMemoryAccess()
+	rm.stopMemoryVerify()
 	
 	// 清理内存
-	log.Println("正在清理内存...")
+	logInfof("正在清理内存...")
 	rm.cleanupMemory()
 	
 	// 清理临时文件
-	log.Println("正在清理临时文件...")
+	logInfof("正在清理临时文件...")
 	rm.cleanupAllTempFiles()
 	
+	// 清理GPU显存
+	rm.cleanupGPU()
+
 	// 强制垃圾回收
-	log.Println("执行垃圾回收...")
-	runtime.GC()
+	if !rm.Config.DisableForcedGC {
+		logInfof("执行垃圾回收...")
+		forceGC()
+	}
 	
-	log.Println("资源清理完成")
+	logInfof("资源清理完成")
 }
 
 // CleanupAllResources 清理所有资源（导出用于测试）
@@ -0,0 +1,518 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestWarmupDelaysCPULoad(t *testing.T) {
+	const warmup = 500 * time.Millisecond
+	rm := NewResourceMonitor(ResourceConfig{WarmupDuration: warmup})
+
+	start := time.Now()
+	rm.warmup(context.Background())
+	if elapsed := time.Since(start); elapsed < warmup {
+		t.Fatalf("预热提前结束: %v", elapsed)
+	}
+
+	if workers := cpuWorkers(rm); workers != 0 {
+		t.Fatalf("预热期间启动了 %d 个CPU工作线程", workers)
+	}
+}
+
+func TestWarmupReturnsOnStop(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{WarmupDuration: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		rm.warmup(ctx)
+		close(done)
+	}()
+	rm.closeStop()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("停止后预热未返回")
+	}
+}
+
+func TestReleaseMemoryDropsSlices(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent:         10,
+		FreeOSMemoryThreshold: 16 * mb,
+	})
+	defer rm.cleanupMemory()
+	for i := 0; i < 4; i++ {
+		rm.AllocateMemory(8 * mb)
+	}
+	if chunks := len(rm.AllocatedMemory); chunks != 4 {
+		t.Fatalf("已分配 %d 块, want 4", chunks)
+	}
+
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb}
+	rm.ReleaseMemory(30, memInfo)
+	if got := rm.getTotalAllocatedMemory(); got != 12*mb {
+		t.Fatalf("释放 20MB 后剩余 %d 字节, want %d", got, 12*mb)
+	}
+	if chunks := len(rm.AllocatedMemory); chunks != 2 {
+		t.Fatalf("释放后剩余 %d 块, want 2", chunks)
+	}
+	for i, chunk := range rm.AllocatedMemory {
+		if chunk == nil {
+			t.Fatalf("第 %d 块为 nil，释放的块应从切片中移除", i)
+		}
+	}
+	if rm.releasedSinceFree != 0 {
+		t.Fatalf("释放量超过阈值后应归还操作系统并清零累计值, got %d", rm.releasedSinceFree)
+	}
+
+	rm.ReleaseMemory(12, memInfo)
+	if got := rm.getTotalAllocatedMemory(); got != 10*mb {
+		t.Fatalf("再释放 2MB 后剩余 %d 字节, want %d", got, 10*mb)
+	}
+	if rm.releasedSinceFree != 2*mb {
+		t.Fatalf("释放量低于阈值时应累计, got %d, want %d", rm.releasedSinceFree, 2*mb)
+	}
+}
+
+func TestMetricFailuresBackOff(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{})
+	buf := captureLog(t, LogInfo)
+
+	// 第2次失败后跳过1次，之后每次失败跳过次数加倍，最多 maxBackoffTicks 次
+	for i, want := range []int{0, 1, 2, 4, 8, 16, 32, 32} {
+		rm.metricReadFailed("内存", errors.New("permission denied"))
+		if rm.skipTicks != want {
+			t.Fatalf("第 %d 次失败后跳过 %d 次, want %d", i+1, rm.skipTicks, want)
+		}
+	}
+	if n := strings.Count(buf.String(), "进入退避模式"); n != 1 {
+		t.Fatalf("进入退避的日志输出 %d 次, want 1:\n%s", n, buf)
+	}
+	if n := strings.Count(buf.String(), "获取内存信息失败"); n != 1 {
+		t.Fatalf("退避前的失败日志输出 %d 次, want 1:\n%s", n, buf)
+	}
+
+	// 退避期间的调整直接返回，不读取指标
+	rm.monitorAndAdjust()
+	if rm.skipTicks != 31 {
+		t.Fatalf("退避期间调整后剩余跳过 %d 次, want 31", rm.skipTicks)
+	}
+	if m := rm.LastMeasurement(); !m.Time.IsZero() {
+		t.Fatalf("退避期间进行了测量: %+v", m)
+	}
+
+	rm.metricReadSucceeded()
+	if rm.metricFailures != 0 || rm.skipTicks != 0 {
+		t.Fatalf("恢复后 metricFailures = %d, skipTicks = %d, want 0, 0", rm.metricFailures, rm.skipTicks)
+	}
+	if !strings.Contains(buf.String(), "指标读取已恢复") {
+		t.Fatalf("恢复后未输出日志:\n%s", buf)
+	}
+}
+
+// toleranceConfigs 全局容忍度与单项覆盖两种方式设置的容忍度，set 将容忍度写入对应的字段
+var toleranceConfigs = []struct {
+	name string
+	set  func(config *ResourceConfig, tolerance float64)
+}{
+	{"Tolerance", func(config *ResourceConfig, tolerance float64) { config.Tolerance = tolerance }},
+	{"单项覆盖", func(config *ResourceConfig, tolerance float64) {
+		config.Tolerance = 10
+		config.MemoryTolerance = tolerance
+		config.CPUTolerance = tolerance
+		config.DiskTolerance = tolerance
+	}},
+}
+
+// cpuWorkers 获取当前CPU工作线程数
+func cpuWorkers(rm *ResourceMonitor) int {
+	rm.cpuLoadMutex.Lock()
+	defer rm.cpuLoadMutex.Unlock()
+	return rm.currentCPUWorkers
+}
+
+func TestMemoryToleranceDeadBand(t *testing.T) {
+	const mb = 1024 * 1024
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Available: 100 * mb}
+	for _, tc := range toleranceConfigs {
+		for _, c := range []struct {
+			tolerance float64
+			release   bool
+		}{{5, false}, {3, true}} {
+			config := ResourceConfig{MemoryPercent: 20}
+			tc.set(&config, c.tolerance)
+			rm := NewResourceMonitor(config)
+			rm.AllocateMemory(10 * mb)
+
+			rm.AdjustMemoryUsage(24, memInfo)
+			if released := rm.getTotalAllocatedMemory() < 10*mb; released != c.release {
+				t.Errorf("%s 容忍度 %.0f: 高于目标 4%% 时释放 = %v, want %v", tc.name, c.tolerance, released, c.release)
+			}
+			rm.CleanupAllResources()
+		}
+	}
+}
+
+func TestCPUToleranceDeadBand(t *testing.T) {
+	for _, tc := range toleranceConfigs {
+		for _, c := range []struct {
+			tolerance float64
+			adjust    bool
+		}{{5, false}, {3, true}} {
+			config := ResourceConfig{CPUPercent: 50}
+			tc.set(&config, c.tolerance)
+			rm := NewResourceMonitor(config)
+
+			rm.AdjustCPUUsage(46)
+			if started := cpuWorkers(rm) > 0; started != c.adjust {
+				t.Errorf("%s 容忍度 %.0f: 低于目标 4%% 时启动负载 = %v, want %v", tc.name, c.tolerance, started, c.adjust)
+			}
+
+			rm.adjustCPUWorkers(1)
+			rm.AdjustCPUUsage(54)
+			if stopped := cpuWorkers(rm) == 0; stopped != c.adjust {
+				t.Errorf("%s 容忍度 %.0f: 高于目标 4%% 时停止负载 = %v, want %v", tc.name, c.tolerance, stopped, c.adjust)
+			}
+			rm.CleanupAllResources()
+		}
+	}
+}
+
+func TestDiskToleranceDeadBand(t *testing.T) {
+	const mb = 1024 * 1024
+	for _, tc := range toleranceConfigs {
+		for _, c := range []struct {
+			tolerance float64
+			release   bool
+		}{{5, false}, {3, true}} {
+			dir := t.TempDir()
+			target := DiskTarget{Path: dir, Percent: 10}
+			config := ResourceConfig{DiskTargets: []DiskTarget{target}}
+			tc.set(&config, c.tolerance)
+			rm := NewResourceMonitor(config)
+			for i := 0; i < 4; i++ {
+				rm.createTempFiles(dir, mb)
+			}
+
+			rm.AdjustDiskTarget(target, 14, &disk.UsageStat{Total: 100 * mb})
+			if released := dirBytes(t, dir) < 4*mb; released != c.release {
+				t.Errorf("%s 容忍度 %.0f: 高于目标 4%% 时删除文件 = %v, want %v", tc.name, c.tolerance, released, c.release)
+			}
+			rm.CleanupAllResources()
+		}
+	}
+}
+
+func TestCPUCoreLoadWorkers(t *testing.T) {
+	for _, c := range []struct {
+		coreLoad float64
+		duties   []float64
+	}{
+		{2.0, []float64{1, 1}},
+		{2.5, []float64{1, 1, 0.5}},
+	} {
+		duties := cpuWorkerDuties(c.coreLoad)
+		if len(duties) != len(c.duties) {
+			t.Fatalf("cpuWorkerDuties(%.1f) = %v, want %v", c.coreLoad, duties, c.duties)
+		}
+		for i := range duties {
+			if duties[i] != c.duties[i] {
+				t.Fatalf("cpuWorkerDuties(%.1f) = %v, want %v", c.coreLoad, duties, c.duties)
+			}
+		}
+
+		rm := NewResourceMonitor(ResourceConfig{
+			CPUPercent:  10,
+			CPUCoreLoad: c.coreLoad,
+		})
+		rm.AdjustCPUUsage(0)
+		rm.cpuLoadMutex.Lock()
+		workers, load := rm.currentCPUWorkers, rm.currentCPULoad
+		rm.cpuLoadMutex.Unlock()
+		rm.CleanupAllResources()
+		// 负载不超过核心数
+		wantLoad := math.Min(c.coreLoad, float64(runtime.NumCPU()))
+		if wantWorkers := int(math.Ceil(wantLoad)); workers != wantWorkers || load != wantLoad {
+			t.Errorf("CPUCoreLoad %.1f: 工作线程 %d, 负载 %.2f 核, want %d, %.2f",
+				c.coreLoad, workers, load, wantWorkers, wantLoad)
+		}
+	}
+}
+
+func TestConcurrentStatsWhileAllocating(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitor(ResourceConfig{MemoryPercent: 10})
+	t.Cleanup(rm.CleanupAllResources)
+	captureLog(t, LogWarn)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				rm.AllocatedBytes()
+				rm.AllocatedChunks()
+				rm.LogStatus()
+			}
+		}()
+	}
+
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb}
+	for i := 0; i < 50; i++ {
+		rm.AllocateMemory(mb)
+		if i%5 == 4 {
+			rm.ReleaseMemory(12, memInfo)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	// 共分配50次1MB，其间释放10次，每次2MB
+	if got := rm.AllocatedBytes(); got != 30*mb {
+		t.Fatalf("AllocatedBytes = %d, want %d", got, 30*mb)
+	}
+}
+
+func TestCPUCooldownPreventsRestart(t *testing.T) {
+	const cooldown = 300 * time.Millisecond
+	rm := NewResourceMonitor(ResourceConfig{
+		CPUPercent:  50,
+		CPUCooldown: cooldown,
+	})
+	t.Cleanup(rm.CleanupAllResources)
+
+	rm.AdjustCPUUsage(0)
+	if cpuWorkers(rm) == 0 {
+		t.Fatal("低于目标时未启动CPU负载")
+	}
+	rm.AdjustCPUUsage(90)
+	if workers := cpuWorkers(rm); workers != 0 {
+		t.Fatalf("高于目标时仍有 %d 个工作线程", workers)
+	}
+
+	rm.AdjustCPUUsage(0)
+	if workers := cpuWorkers(rm); workers != 0 {
+		t.Fatalf("冷却期内重新启动了 %d 个工作线程", workers)
+	}
+
+	time.Sleep(cooldown)
+	rm.AdjustCPUUsage(0)
+	if cpuWorkers(rm) == 0 {
+		t.Fatal("冷却期结束后未重新启动CPU负载")
+	}
+}
+
+func TestMaxMemoryBytesCapsAllocation(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent:  50,
+		MaxMemoryBytes: 6 * mb,
+	})
+	t.Cleanup(rm.CleanupAllResources)
+	buf := captureLog(t, LogInfo)
+
+	// 测量值不随分配变化，每次调整都希望再分配 50MB
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Available: 100 * mb}
+	for i := 0; i < 3; i++ {
+		rm.AdjustMemoryUsage(0, memInfo)
+		if got := rm.AllocatedBytes(); got != 6*mb {
+			t.Fatalf("第 %d 次调整后 AllocatedBytes = %d, want %d", i+1, got, 6*mb)
+		}
+	}
+	if n := strings.Count(buf.String(), "已达到内存分配上限"); n != 1 {
+		t.Fatalf("达到上限的日志输出 %d 次, want 1:\n%s", n, buf)
+	}
+}
+
+func TestLowCPUTargetOnSingleCoreStartsWorker(t *testing.T) {
+	// 目标折算为 0.1 个核心，相当于单核上的 10%
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		CPUPercent: 10 / float64(runtime.NumCPU()),
+	}, newFakeMetrics(1<<30, 1<<40))
+	t.Cleanup(rm.CleanupAllResources)
+
+	rm.AdjustCPUUsage(0)
+	rm.cpuLoadMutex.Lock()
+	workers, load := rm.currentCPUWorkers, rm.currentCPULoad
+	rm.cpuLoadMutex.Unlock()
+	if workers != 1 {
+		t.Fatalf("0.1 核目标启动 %d 个工作线程, want 1", workers)
+	}
+	if math.Abs(load-0.1) > 1e-9 {
+		t.Fatalf("0.1 核目标负载 = %.2f 核, want 0.10（占空比工作线程）", load)
+	}
+}
+
+func TestChunkSizesArePageMultiples(t *testing.T) {
+	page := uint64(os.Getpagesize())
+	for _, allocator := range []string{MemoryAllocatorHeap, MemoryAllocatorMmap} {
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			MemoryAllocator: allocator,
+		}, newFakeMetrics(1<<30, 1<<40))
+		for _, size := range []uint64{1, page - 1, page + 1, 3*page + 17, 100*1024*1024 + 5} {
+			rm.AllocateMemory(size)
+		}
+		for i, chunk := range rm.AllocatedMemory {
+			if uint64(len(chunk))%page != 0 {
+				t.Errorf("%s: 第 %d 块大小 %d 不是页大小 %d 的整数倍", allocator, i, len(chunk), page)
+			}
+			if !rm.memoryAllocator().managedByGC() && uintptr(unsafe.Pointer(&chunk[0]))%uintptr(page) != 0 {
+				t.Errorf("%s: 第 %d 块起始地址未按页对齐", allocator, i)
+			}
+		}
+		rm.CleanupAllResources()
+	}
+}
+
+func TestLeakModeNeverReleases(t *testing.T) {
+	const mb = 1024 * 1024
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Used: 90 * mb, Available: 10 * mb, UsedPercent: 90}
+	tests := []struct {
+		name string
+		leak bool
+		want uint64
+	}{
+		{"leak", true, 20 * mb},
+		{"normal", false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rm := NewResourceMonitorWithMetrics(ResourceConfig{
+				MemoryPercent: 10,
+				LeakMode:      tt.leak,
+				Interval:      100 * time.Millisecond,
+			}, newFakeMetrics(100*mb, 100*mb))
+			defer rm.CleanupAllResources()
+			rm.AllocateMemory(20 * mb)
+
+			// 使用率 90% 远高于目标 10%
+			rm.AdjustMemoryUsage(memInfo.UsedPercent, memInfo)
+			if got := rm.AllocatedBytes(); got != tt.want {
+				t.Fatalf("超出目标后 AllocatedBytes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeakRateLimitsGrowth(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 50,
+		LeakMode:      true,
+		LeakRateBytes: 4 * mb,
+		Interval:      100 * time.Millisecond,
+	}, newFakeMetrics(100*mb, 100*mb))
+	defer rm.CleanupAllResources()
+
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Used: 10 * mb, Available: 90 * mb, UsedPercent: 10}
+	for i := 1; i <= 3; i++ {
+		rm.AdjustMemoryUsage(memInfo.UsedPercent, memInfo)
+		if got := rm.AllocatedBytes(); got != uint64(i)*4*mb {
+			t.Fatalf("第 %d 次调整后 AllocatedBytes = %d, want %d", i, got, uint64(i)*4*mb)
+		}
+	}
+}
+
+func TestPartialReleaseLetsBackingArrayBeCollected(t *testing.T) {
+	const mb = 1024 * 1024
+	stubGC(t)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Interval: MinInterval}, newFakeMetrics(1024*mb, 1024*mb))
+	defer rm.CleanupAllResources()
+	rm.AllocateMemory(64 * mb)
+
+	heapAlloc := func() uint64 {
+		runtime.GC()
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		return stats.HeapAlloc
+	}
+	before := heapAlloc()
+
+	rm.memoryMutex.Lock()
+	if len(rm.AllocatedMemory) != 1 {
+		rm.memoryMutex.Unlock()
+		t.Fatalf("内存块数 = %d, want 1", len(rm.AllocatedMemory))
+	}
+	rm.releaseBytes(48 * mb)
+	remaining := rm.getTotalAllocatedMemory()
+	rm.memoryMutex.Unlock()
+	if remaining != 16*mb {
+		t.Fatalf("部分释放后剩余 %d 字节, want %d", remaining, 16*mb)
+	}
+
+	// 保留部分复制到新的内存块后，原来 64MB 的底层数组应可被回收
+	after := heapAlloc()
+	if after > before || before-after < 40*mb {
+		t.Fatalf("部分释放后堆内存 %s → %s, want 至少减少 40 MiB", FormatBytes(before), FormatBytes(after))
+	}
+}
+
+func TestCPUCountFromGOMAXPROCSDrivesWorkerMath(t *testing.T) {
+	for _, procs := range []int{2, 4, 8} {
+		prev := runtime.GOMAXPROCS(procs)
+		// 与 --gomaxprocs 一样以固定的 GOMAXPROCS 作为核心数，容器配额不再参与计算
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			CPUPercent: 50,
+			CPUCount:   runtime.GOMAXPROCS(0),
+			Interval:   MinInterval,
+		}, newFakeMetrics(1<<30, 1<<40))
+		rm.cgroupCPUs = 1
+		rm.AdjustCPUUsage(0)
+		rm.cpuLoadMutex.Lock()
+		workers, load := rm.currentCPUWorkers, rm.currentCPULoad
+		rm.cpuLoadMutex.Unlock()
+		rm.CleanupAllResources()
+		runtime.GOMAXPROCS(prev)
+
+		if want := procs / 2; workers != want || load != float64(want) {
+			t.Errorf("GOMAXPROCS %d: 工作线程 %d, 负载 %.2f 核, want %d, %d",
+				procs, workers, load, want, want)
+		}
+	}
+}
+
+func TestMinFreeMemoryCapsAllocation(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:      80,
+		MinFreeMemoryBytes: 30 * mb,
+		Interval:           MinInterval,
+	}, newFakeMetrics(100*mb, 100*mb))
+	defer rm.CleanupAllResources()
+
+	// 目标需要再分配 60MB，但可用内存只有 40MB，只能分配到保留 30MB 空闲为止
+	memInfo := &mem.VirtualMemoryStat{Total: 100 * mb, Used: 20 * mb, Available: 40 * mb, UsedPercent: 20}
+	rm.AdjustMemoryUsage(memInfo.UsedPercent, memInfo)
+	if got := rm.AllocatedBytes(); got != 10*mb {
+		t.Fatalf("AllocatedBytes = %d, want %d", got, 10*mb)
+	}
+
+	// 其他进程占用内存使可用内存降到保留量以下时释放差额
+	memInfo = &mem.VirtualMemoryStat{Total: 100 * mb, Used: 75 * mb, Available: 24 * mb, UsedPercent: 75}
+	rm.AdjustMemoryUsage(memInfo.UsedPercent, memInfo)
+	if got := rm.AllocatedBytes(); got != 4*mb {
+		t.Fatalf("可用内存不足时 AllocatedBytes = %d, want %d", got, 4*mb)
+	}
+}
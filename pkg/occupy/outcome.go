@@ -0,0 +1,67 @@
+package occupy
+
+import (
+	"errors"
+)
+
+// 进程退出码
+const (
+	// ExitOK 正常运行并完成清理
+	ExitOK = 0
+	// ExitError 因其他错误退出
+	ExitError = 1
+	// ExitCleanupFailed 清理时有临时文件无法删除
+	ExitCleanupFailed = 2
+	// ExitConvergeMissed 未能在 ConvergeDeadline 内达到目标
+	ExitConvergeMissed = 3
+	// ExitWatchdogTripped 运行期间触发过安全看门狗的紧急释放
+	ExitWatchdogTripped = 4
+)
+
+// ErrConvergeDeadline 未能在 ConvergeDeadline 内达到目标
+var ErrConvergeDeadline = errors.New("未能在截止时间内达到目标")
+
+// Outcome 监控运行的结果，应在 Done 关闭后获取
+type Outcome struct {
+	// Err 导致监控退出的错误，正常停止时为 nil
+	Err error
+	// WatchdogTrips 安全看门狗触发紧急释放的次数
+	WatchdogTrips int
+	// CleanupErrors 清理临时文件时删除失败的次数
+	CleanupErrors int
+}
+
+// ConvergeMissed 是否因未能在截止时间内达到目标而退出
+func (o Outcome) ConvergeMissed() bool {
+	return errors.Is(o.Err, ErrConvergeDeadline)
+}
+
+// ExitCode 将运行结果映射为进程退出码，同时满足多个条件时依次取
+// 未达到目标（3）、其他错误（1）、看门狗触发（4）、清理失败（2）
+func (o Outcome) ExitCode() int {
+	switch {
+	case o.ConvergeMissed():
+		return ExitConvergeMissed
+	case o.Err != nil:
+		return ExitError
+	case o.WatchdogTrips > 0:
+		return ExitWatchdogTripped
+	case o.CleanupErrors > 0:
+		return ExitCleanupFailed
+	default:
+		return ExitOK
+	}
+}
+
+// Outcome 获取监控运行的结果，应在 Done 关闭后调用
+func (rm *ResourceMonitor) Outcome() Outcome {
+	rm.diskMutex.Lock()
+	cleanupErrors := rm.cleanupErrors
+	rm.diskMutex.Unlock()
+
+	return Outcome{
+		Err:           rm.Err(),
+		WatchdogTrips: rm.watchdogTrips,
+		CleanupErrors: cleanupErrors,
+	}
+}
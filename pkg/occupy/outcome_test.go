@@ -0,0 +1,31 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOutcomeExitCode(t *testing.T) {
+	missed := fmt.Errorf("%w: 内存 (当前 10.0%%, 目标 99.0%%)", ErrConvergeDeadline)
+	other := errors.New("启动失败")
+	tests := []struct {
+		name    string
+		outcome Outcome
+		want    int
+	}{
+		{"正常", Outcome{}, ExitOK},
+		{"清理失败", Outcome{CleanupErrors: 2}, ExitCleanupFailed},
+		{"未达到目标", Outcome{Err: missed}, ExitConvergeMissed},
+		{"看门狗触发", Outcome{WatchdogTrips: 1}, ExitWatchdogTripped},
+		{"其他错误", Outcome{Err: other}, ExitError},
+		{"未达到目标优先", Outcome{Err: missed, WatchdogTrips: 1, CleanupErrors: 1}, ExitConvergeMissed},
+		{"其他错误优先于看门狗", Outcome{Err: other, WatchdogTrips: 1}, ExitError},
+		{"看门狗优先于清理失败", Outcome{WatchdogTrips: 1, CleanupErrors: 1}, ExitWatchdogTripped},
+	}
+	for _, tt := range tests {
+		if got := tt.outcome.ExitCode(); got != tt.want {
+			t.Errorf("%s: ExitCode = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
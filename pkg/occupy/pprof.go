@@ -0,0 +1,73 @@
+package occupy
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// pprofHandler 仅包含 net/http/pprof 接口的处理器，不使用 http.DefaultServeMux。
+// pprof 服务不经过令牌认证，不提供会暴露命令行参数（可能包含 --api-token）的 /debug/pprof/cmdline
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", http.NotFound)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// startPprof 设置了 PprofAddr 时启动 pprof 服务；监听失败时输出日志，不影响资源占用
+func (rm *ResourceMonitor) startPprof() {
+	if rm.Config.PprofAddr == "" {
+		return
+	}
+
+	listener, err := net.Listen("tcp", rm.Config.PprofAddr)
+	if err != nil {
+		logErrorf("pprof服务监听失败: %v", err)
+		return
+	}
+	server := &http.Server{Handler: pprofHandler()}
+
+	rm.pprofMutex.Lock()
+	rm.pprofServer = server
+	rm.pprofListenAddr = listener.Addr().String()
+	rm.pprofMutex.Unlock()
+
+	logInfof("pprof服务已启动: http://%s/debug/pprof/", listener.Addr())
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			logErrorf("pprof服务退出: %v", err)
+		}
+	}()
+}
+
+// stopPprof 关闭 pprof 服务，等待进行中的请求最多5秒
+func (rm *ResourceMonitor) stopPprof() {
+	rm.pprofMutex.Lock()
+	server := rm.pprofServer
+	rm.pprofServer = nil
+	rm.pprofListenAddr = ""
+	rm.pprofMutex.Unlock()
+
+	if server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		server.Close()
+	}
+}
+
+// PprofAddr 获取 pprof 服务实际监听的地址（PprofAddr 端口为 0 时可由此获取分配的端口），未运行时返回空字符串
+func (rm *ResourceMonitor) PprofAddr() string {
+	rm.pprofMutex.Lock()
+	defer rm.pprofMutex.Unlock()
+
+	return rm.pprofListenAddr
+}
@@ -0,0 +1,71 @@
+package occupy
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPprofEnabledServesUntilStop(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		PprofAddr: "127.0.0.1:0",
+		Interval:  MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+	done := rm.Done()
+	go rm.Start()
+	waitFor(t, 5*time.Second, "pprof服务启动", func() bool { return rm.PprofAddr() != "" })
+	addr := rm.PprofAddr()
+
+	resp, err := http.Get("http://" + addr + "/debug/pprof/")
+	if err != nil {
+		t.Fatalf("请求 pprof 失败: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "goroutine") {
+		t.Fatalf("/debug/pprof/ 状态码 = %d, 内容:\n%s", resp.StatusCode, body)
+	}
+
+	// 命令行参数可能包含 --api-token，不能通过未认证的 pprof 服务读取
+	resp, err = http.Get("http://" + addr + "/debug/pprof/cmdline")
+	if err != nil {
+		t.Fatalf("请求 pprof 失败: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || strings.Contains(string(body), os.Args[0]) {
+		t.Errorf("/debug/pprof/cmdline 状态码 = %d, want %d, 内容:\n%s", resp.StatusCode, http.StatusNotFound, body)
+	}
+
+	rm.Stop()
+	waitDone(t, done, 10*time.Second)
+	if got := rm.PprofAddr(); got != "" {
+		t.Errorf("停止后 PprofAddr = %q, want 空", got)
+	}
+	if conn, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+		conn.Close()
+		t.Errorf("停止后 %s 仍在监听", addr)
+	}
+}
+
+func TestPprofDisabledOpensNoListener(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+	done := rm.Done()
+	go rm.Start()
+	defer func() {
+		rm.Stop()
+		waitDone(t, done, 10*time.Second)
+	}()
+	waitFor(t, 5*time.Second, "监控开始运行", rm.Running)
+
+	rm.pprofMutex.Lock()
+	server := rm.pprofServer
+	rm.pprofMutex.Unlock()
+	if server != nil || rm.PprofAddr() != "" {
+		t.Errorf("未设置 PprofAddr 时启动了 pprof 服务 (%q)", rm.PprofAddr())
+	}
+}
@@ -0,0 +1,237 @@
+package occupy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// DefaultProbeMemoryMargin 探测内存上限时默认保留的可用内存（占总内存的百分比）
+const DefaultProbeMemoryMargin = 10.0
+
+// DefaultProbeDiskMargin 探测磁盘上限时默认保留的剩余空间（占总容量的百分比）
+const DefaultProbeDiskMargin = 5.0
+
+// ProbeStats 探测所需的系统状态和限制
+type ProbeStats struct {
+	Memory MemoryUsage
+	CPU    CPUUsage
+	Disks  []DiskUsage
+	// CgroupCPUs cgroup CPU 配额（核心数），0 表示未限制
+	CgroupCPUs float64
+	// CgroupMemoryLimit/CgroupMemoryUsage cgroup 内存限制和当前用量，限制为 0 表示未限制
+	CgroupMemoryLimit uint64
+	CgroupMemoryUsage uint64
+	// RlimitMemory 进程内存资源限制，0 表示未限制
+	RlimitMemory uint64
+}
+
+// ProbeMargins 探测时为每种资源保留的余量（百分比）
+type ProbeMargins struct {
+	Memory float64
+	Disk   float64
+}
+
+// ProbeLimit 单个资源可以安全达到的最高使用率
+type ProbeLimit struct {
+	// Current 当前使用率
+	Current float64 `json:"current_percent"`
+	// Max 可以安全达到的最高使用率，不低于当前使用率
+	Max float64 `json:"max_percent"`
+	// Available 本工具最多还能占用的量：内存和磁盘为字节数，CPU为核心数
+	Available float64 `json:"available"`
+	// LimitedBy 决定上限的因素
+	LimitedBy string `json:"limited_by"`
+}
+
+// ProbeDiskLimit 单个磁盘路径可以安全达到的最高使用率
+type ProbeDiskLimit struct {
+	Path string `json:"path"`
+	ProbeLimit
+}
+
+// ProbeResult 各资源可以安全达到的最高目标
+type ProbeResult struct {
+	Memory ProbeLimit       `json:"memory"`
+	CPU    ProbeLimit       `json:"cpu"`
+	Disks  []ProbeDiskLimit `json:"disks"`
+}
+
+// CollectProbeStats 采集探测所需的系统状态：内存、CPU（在 cpuInterval 时间内采样）、
+// 各磁盘路径的使用情况，以及 cgroup 和进程资源限制
+func CollectProbeStats(diskPaths []string, cpuInterval time.Duration) (*ProbeStats, error) {
+	if len(diskPaths) == 0 {
+		diskPaths = []string{DefaultDiskPath}
+	}
+
+	memInfo, err := mem.VirtualMemory()
+	if err != nil {
+		return nil, fmt.Errorf("获取内存信息失败: %v", err)
+	}
+	cpuPercent, err := cpu.Percent(cpuInterval, false)
+	if err != nil {
+		return nil, fmt.Errorf("获取CPU信息失败: %v", err)
+	}
+	if len(cpuPercent) == 0 {
+		return nil, fmt.Errorf("获取CPU信息失败: 无数据")
+	}
+
+	stats := &ProbeStats{
+		Memory: MemoryUsage{
+			Total:       memInfo.Total,
+			Used:        memInfo.Used,
+			Available:   memInfo.Available,
+			UsedPercent: memInfo.UsedPercent,
+		},
+		CPU: CPUUsage{
+			Cores:       runtime.NumCPU(),
+			UsedPercent: cpuPercent[0],
+		},
+	}
+	for _, path := range diskPaths {
+		diskInfo, err := disk.Usage(path)
+		if err != nil {
+			return nil, fmt.Errorf("获取磁盘信息失败: %s, %v", path, err)
+		}
+		stats.Disks = append(stats.Disks, DiskUsage{
+			Path:        path,
+			Total:       diskInfo.Total,
+			Used:        diskInfo.Used,
+			Free:        diskInfo.Free,
+			UsedPercent: diskInfo.UsedPercent,
+		})
+	}
+
+	if stats.CgroupCPUs, err = cgroupCPUQuota(cgroupRoot); err != nil {
+		logWarnf("读取 cgroup CPU 配额失败: %v", err)
+	}
+	if stats.CgroupMemoryLimit, stats.CgroupMemoryUsage, err = cgroupMemoryLimit(cgroupRoot); err != nil {
+		logWarnf("读取 cgroup 内存限制失败: %v", err)
+	}
+	if stats.RlimitMemory, err = currentMemoryRlimit(); err != nil {
+		logWarnf("读取进程内存资源限制失败: %v", err)
+	}
+	return stats, nil
+}
+
+// ComputeProbe 根据系统状态计算各资源可以安全达到的最高目标，不占用任何资源
+func ComputeProbe(stats ProbeStats, margins ProbeMargins) ProbeResult {
+	return ProbeResult{
+		Memory: probeMemory(stats, margins.Memory),
+		CPU:    probeCPU(stats),
+		Disks:  probeDisks(stats.Disks, margins.Disk),
+	}
+}
+
+// probeMemory 内存上限：当前使用率加上可分配的量，可分配的量为可用内存减去保留量，
+// 并受 cgroup 内存限制和进程资源限制约束
+func probeMemory(stats ProbeStats, margin float64) ProbeLimit {
+	m := stats.Memory
+	limit := ProbeLimit{Current: m.UsedPercent, Max: m.UsedPercent, LimitedBy: "可用内存"}
+	if m.Total == 0 {
+		return limit
+	}
+
+	marginBytes := uint64(margin / 100.0 * float64(m.Total))
+	var available uint64
+	if m.Available > marginBytes {
+		available = m.Available - marginBytes
+	}
+	if stats.CgroupMemoryLimit > 0 {
+		var remaining uint64
+		if stats.CgroupMemoryLimit > stats.CgroupMemoryUsage {
+			remaining = stats.CgroupMemoryLimit - stats.CgroupMemoryUsage
+		}
+		if remaining < available {
+			available = remaining
+			limit.LimitedBy = "cgroup 内存限制"
+		}
+	}
+	if stats.RlimitMemory > 0 {
+		var remaining uint64
+		if stats.RlimitMemory > DefaultRlimitHeadroom {
+			remaining = stats.RlimitMemory - DefaultRlimitHeadroom
+		}
+		if remaining < available {
+			available = remaining
+			limit.LimitedBy = "进程内存资源限制"
+		}
+	}
+
+	limit.Available = float64(available)
+	limit.Max = math.Min(100, m.UsedPercent+float64(available)/float64(m.Total)*100)
+	return limit
+}
+
+// probeCores 本工具可以使用的核心数：cgroup 配额低于核心数时按配额计算
+func probeCores(stats ProbeStats) float64 {
+	cores := float64(stats.CPU.Cores)
+	if stats.CgroupCPUs > 0 && stats.CgroupCPUs < cores {
+		return stats.CgroupCPUs
+	}
+	return cores
+}
+
+// probeCPU CPU上限：当前使用率加上可用核心数对应的使用率，不超过100%
+func probeCPU(stats ProbeStats) ProbeLimit {
+	c := stats.CPU
+	limit := ProbeLimit{Current: c.UsedPercent, Max: c.UsedPercent, LimitedBy: "核心数"}
+	if c.Cores == 0 {
+		return limit
+	}
+
+	cores := probeCores(stats)
+	if cores < float64(c.Cores) {
+		limit.LimitedBy = "cgroup CPU 配额"
+	}
+	limit.Available = cores
+	limit.Max = math.Min(100, c.UsedPercent+cores/float64(c.Cores)*100)
+	return limit
+}
+
+// probeDisks 磁盘上限：保留 margin 百分比的剩余空间，使用率与 disk.Usage 的计算方式一致（已用 / (已用 + 剩余)）
+func probeDisks(disks []DiskUsage, margin float64) []ProbeDiskLimit {
+	limits := make([]ProbeDiskLimit, 0, len(disks))
+	for _, d := range disks {
+		limit := ProbeDiskLimit{
+			Path:       d.Path,
+			ProbeLimit: ProbeLimit{Current: d.UsedPercent, Max: d.UsedPercent, LimitedBy: "剩余空间"},
+		}
+		capacity := d.Used + d.Free
+		if capacity > 0 {
+			marginBytes := uint64(margin / 100.0 * float64(capacity))
+			if d.Free > marginBytes {
+				limit.Available = float64(d.Free - marginBytes)
+				limit.Max = math.Max(d.UsedPercent, 100-margin)
+			}
+		}
+		limits = append(limits, limit)
+	}
+	return limits
+}
+
+// WriteText 以文本格式输出探测结果
+func (p *ProbeResult) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "内存: 当前 %.1f%%，最高 %.1f%% (还可占用 %s，取决于%s)\n",
+		p.Memory.Current, p.Memory.Max, FormatBytes(uint64(p.Memory.Available)), p.Memory.LimitedBy)
+	fmt.Fprintf(w, "CPU:  当前 %.1f%%，最高 %.1f%% (可用 %.2f 核，取决于%s)\n",
+		p.CPU.Current, p.CPU.Max, p.CPU.Available, p.CPU.LimitedBy)
+	for _, d := range p.Disks {
+		fmt.Fprintf(w, "磁盘 %s: 当前 %.1f%%，最高 %.1f%% (还可写入 %s，取决于%s)\n",
+			d.Path, d.Current, d.Max, FormatBytes(uint64(d.Available)), d.LimitedBy)
+	}
+}
+
+// WriteJSON 以JSON格式输出探测结果
+func (p *ProbeResult) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
@@ -0,0 +1,77 @@
+package occupy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestProbeReportsPerResourceMaximums(t *testing.T) {
+	const mb = 1024 * 1024
+	stats := ProbeStats{
+		Memory: MemoryUsage{Total: 1000 * mb, Used: 200 * mb, Available: 700 * mb, UsedPercent: 20},
+		CPU:    CPUUsage{Cores: 8, UsedPercent: 25},
+		Disks: []DiskUsage{
+			{Path: "/data", Total: 100 * mb, Used: 40 * mb, Free: 60 * mb, UsedPercent: 40},
+			{Path: "/full", Total: 100 * mb, Used: 98 * mb, Free: 2 * mb, UsedPercent: 98},
+		},
+	}
+	margins := ProbeMargins{Memory: DefaultProbeMemoryMargin, Disk: DefaultProbeDiskMargin}
+
+	result := ComputeProbe(stats, margins)
+	// 可用 700MB 减去保留的 100MB
+	if result.Memory.Max != 80 || result.Memory.Available != 600*mb || result.Memory.LimitedBy != "可用内存" {
+		t.Errorf("内存上限 = %+v, want 最高 80%%、还可占用 600MB、取决于可用内存", result.Memory)
+	}
+	if result.CPU.Max != 100 || result.CPU.Available != 8 || result.CPU.LimitedBy != "核心数" {
+		t.Errorf("CPU上限 = %+v, want 最高 100%%、8 核、取决于核心数", result.CPU)
+	}
+	if len(result.Disks) != 2 {
+		t.Fatalf("磁盘上限数量 = %d, want 2", len(result.Disks))
+	}
+	if d := result.Disks[0]; d.Path != "/data" || d.Max != 95 || d.Available != 55*mb {
+		t.Errorf("磁盘 /data 上限 = %+v, want 最高 95%%、还可写入 55MB", d)
+	}
+	// 剩余空间已少于保留量时上限等于当前使用率
+	if d := result.Disks[1]; d.Max != 98 || d.Available != 0 {
+		t.Errorf("磁盘 /full 上限 = %+v, want 最高 98%%、还可写入 0", d)
+	}
+
+	var out bytes.Buffer
+	result.WriteText(&out)
+	for _, want := range []string{
+		"内存: 当前 20.0%，最高 80.0% (还可占用 600.0 MiB，取决于可用内存)",
+		"CPU:  当前 25.0%，最高 100.0% (可用 8.00 核，取决于核心数)",
+		"磁盘 /data: 当前 40.0%，最高 95.0% (还可写入 55.0 MiB，取决于剩余空间)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("探测输出缺少 %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestProbeLimitedByCgroupAndRlimit(t *testing.T) {
+	const mb = 1024 * 1024
+	stats := ProbeStats{
+		Memory:            MemoryUsage{Total: 1000 * mb, Used: 200 * mb, Available: 700 * mb, UsedPercent: 20},
+		CPU:               CPUUsage{Cores: 8, UsedPercent: 25},
+		CgroupCPUs:        2,
+		CgroupMemoryLimit: 500 * mb,
+		CgroupMemoryUsage: 300 * mb,
+	}
+	margins := ProbeMargins{Memory: DefaultProbeMemoryMargin, Disk: DefaultProbeDiskMargin}
+
+	result := ComputeProbe(stats, margins)
+	if result.Memory.Max != 40 || result.Memory.LimitedBy != "cgroup 内存限制" {
+		t.Errorf("内存上限 = %+v, want 最高 40%%、取决于 cgroup 内存限制", result.Memory)
+	}
+	if result.CPU.Max != 50 || result.CPU.Available != 2 || result.CPU.LimitedBy != "cgroup CPU 配额" {
+		t.Errorf("CPU上限 = %+v, want 最高 50%%、2 核、取决于 cgroup CPU 配额", result.CPU)
+	}
+
+	stats.RlimitMemory = DefaultRlimitHeadroom + 100*mb
+	result = ComputeProbe(stats, margins)
+	if result.Memory.Max != 30 || result.Memory.LimitedBy != "进程内存资源限制" {
+		t.Errorf("内存上限 = %+v, want 最高 30%%、取决于进程内存资源限制", result.Memory)
+	}
+}
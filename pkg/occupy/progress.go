@@ -0,0 +1,65 @@
+package occupy
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// isTerminal 判断输出是否为终端
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// formatProgress 格式化当前使用率与目标的对比
+func (rm *ResourceMonitor) formatProgress(m Measurement, t Targets) string {
+	rm.cpuLoadMutex.Lock()
+	cpuWorkers := rm.currentCPUWorkers
+	rm.cpuLoadMutex.Unlock()
+
+	disks := make([]string, len(m.DiskPercents))
+	for i, percent := range m.DiskPercents {
+		disks[i] = fmt.Sprintf("%.1f%%→%.1f%%", percent, t.DiskPercents[i])
+	}
+	return fmt.Sprintf("内存 %.1f%%→%.1f%% | CPU %.1f%%→%.1f%% (%d 线程) | 磁盘 %s",
+		m.MemoryPercent, t.MemoryPercent, m.CPUPercent, t.CPUPercent, cpuWorkers, strings.Join(disks, ", "))
+}
+
+// reportProgress 向 ProgressOutput 输出进度：终端上使用回车在同一行刷新，
+// 否则每次调整输出一行日志（仅由监控协程调用）
+func (rm *ResourceMonitor) reportProgress(m Measurement, t Targets) {
+	w := rm.Config.ProgressOutput
+	if w == nil || len(m.DiskPercents) != len(t.DiskPercents) {
+		return
+	}
+
+	line := rm.formatProgress(m, t)
+	if isTerminal(w) {
+		fmt.Fprintf(w, "\r\033[K%s", line)
+		rm.progressShown = true
+		return
+	}
+
+	if rm.progressLogger == nil {
+		rm.progressLogger = log.New(w, "", log.LstdFlags)
+	}
+	rm.progressLogger.Println(line)
+}
+
+// endProgress 结束终端上的进度行，使后续日志从新行开始
+func (rm *ResourceMonitor) endProgress() {
+	if rm.progressShown {
+		fmt.Fprintln(rm.Config.ProgressOutput)
+		rm.progressShown = false
+	}
+}
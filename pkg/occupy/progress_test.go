@@ -0,0 +1,39 @@
+package occupy
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressFallsBackToLinesWhenNotTerminal(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	var out bytes.Buffer
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:  10,
+		Interval:       100 * time.Millisecond,
+		ProgressOutput: &out,
+	}, newFakeMetrics(100*mb, 100*mb))
+	defer rm.CleanupAllResources()
+
+	for i := 0; i < 2; i++ {
+		rm.MonitorAndAdjust()
+	}
+	rm.endProgress()
+
+	got := out.String()
+	if strings.Contains(got, "\r") || strings.Contains(got, "\033[K") {
+		t.Fatalf("非终端输出包含回车或控制序列: %q", got)
+	}
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("进度行数 = %d, want 2:\n%s", len(lines), got)
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "内存 ") || !strings.Contains(line, "→10.0%") {
+			t.Errorf("进度行 = %q, want 包含内存使用率与目标", line)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package occupy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// metricSample 一个指标样本，labels 为样本自身的标签（如磁盘序号）
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricFamily 同名的一组 gauge 样本
+type metricFamily struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+// metricsRegistry 收集一次抓取的所有指标，输出时为每个样本附加 constLabels（即 ResourceConfig.Labels）
+type metricsRegistry struct {
+	constLabels map[string]string
+	families    []metricFamily
+}
+
+// gauge 添加一个不带样本标签的 gauge
+func (r *metricsRegistry) gauge(name, help string, value float64) {
+	r.families = append(r.families, metricFamily{
+		name:    name,
+		help:    help,
+		samples: []metricSample{{value: value}},
+	})
+}
+
+// gauges 添加一组样本
+func (r *metricsRegistry) gauges(name, help string, samples []metricSample) {
+	r.families = append(r.families, metricFamily{name: name, help: help, samples: samples})
+}
+
+// WriteTo 以 Prometheus 文本格式输出所有指标
+func (r *metricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	for _, family := range r.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.name, family.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", family.name)
+		for _, sample := range family.samples {
+			b.WriteString(family.name)
+			b.WriteString(formatMetricLabels(r.constLabels, sample.labels))
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(sample.value, 'g', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// formatMetricLabels 合并标签并按名称排序格式化为 {k1="v1",k2="v2"}，无标签时返回空字符串；
+// 样本标签与常量标签同名时以样本标签为准
+func formatMetricLabels(constLabels, labels map[string]string) string {
+	merged := make(map[string]string, len(constLabels)+len(labels))
+	for key, value := range constLabels {
+		merged[key] = value
+	}
+	for key, value := range labels {
+		merged[key] = value
+	}
+	if len(merged) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+`="`+escapeLabelValue(merged[key])+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelValueEscaper 转义 Prometheus 标签值中的反斜杠、双引号和换行
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue 转义标签值
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+// collectMetrics 收集当前的运行状态、最近一次测量的使用率、目标和已占用的字节数
+func (rm *ResourceMonitor) collectMetrics() *metricsRegistry {
+	r := &metricsRegistry{constLabels: rm.Config.Labels}
+
+	up := 0.0
+	if rm.Running() {
+		up = 1
+	}
+	r.gauge("go_occupy_up", "监控循环是否正在运行", up)
+
+	rm.measurementMutex.Lock()
+	m := rm.lastMeasurement
+	t := rm.lastTargets
+	rm.measurementMutex.Unlock()
+
+	if !m.Time.IsZero() {
+		r.gauge("go_occupy_memory_used_percent", "最近一次测量的内存使用率", m.MemoryPercent)
+		r.gauge("go_occupy_cpu_used_percent", "最近一次测量的CPU使用率", m.CPUPercent)
+		r.gauges("go_occupy_disk_used_percent", "最近一次测量的各磁盘使用率，disk 为磁盘目标的序号", diskSamples(m.DiskPercents))
+	}
+	if t != nil {
+		r.gauge("go_occupy_memory_target_percent", "当前的内存目标", t.MemoryPercent)
+		r.gauge("go_occupy_cpu_target_percent", "当前的CPU目标", t.CPUPercent)
+		r.gauges("go_occupy_disk_target_percent", "当前的各磁盘目标，disk 为磁盘目标的序号", diskSamples(t.DiskPercents))
+	}
+	r.gauge("go_occupy_allocated_bytes", "已分配的内存字节数", float64(rm.AllocatedBytes()))
+	r.gauge("go_occupy_temp_file_bytes", "已写入的临时文件字节数", float64(rm.TempFileBytes()))
+	return r
+}
+
+// diskSamples 按磁盘目标的序号生成样本
+func diskSamples(percents []float64) []metricSample {
+	samples := make([]metricSample, 0, len(percents))
+	for i, percent := range percents {
+		samples = append(samples, metricSample{
+			labels: map[string]string{"disk": strconv.Itoa(i)},
+			value:  percent,
+		})
+	}
+	return samples
+}
+
+// handleMetrics 以 Prometheus 文本格式输出指标，配置的标签作为每个样本的标签维度
+func (rm *ResourceMonitor) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if _, err := rm.collectMetrics().WriteTo(w); err != nil {
+		logDebugf("输出指标失败: %v", err)
+	}
+}
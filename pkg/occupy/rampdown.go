@@ -0,0 +1,48 @@
+package occupy
+
+import "time"
+
+// cleanupTimeout Stop 等待清理完成的最长时间
+const cleanupTimeout = 60 * time.Second
+
+// rampDownSteps 逐步释放资源的步数
+const rampDownSteps = 10
+
+// rampDownReserve 为最终清理预留的时间，逐步释放的时长不超过 cleanupTimeout 减去该值
+const rampDownReserve = 10 * time.Second
+
+// rampDown 停止时在 RampDown 时长内分步释放内存并减少CPU负载，避免资源骤降；
+// 临时文件仍在最终清理时一次性删除
+func (rm *ResourceMonitor) rampDown() {
+	duration := rm.Config.RampDown
+	if duration <= 0 {
+		return
+	}
+	if limit := cleanupTimeout - rampDownReserve; duration > limit {
+		logWarnf("逐步释放时长 %v 超过上限，使用 %v", duration, limit)
+		duration = limit
+	}
+
+	logInfof("逐步释放资源，持续 %v...", duration)
+	rm.cpuLoadMutex.Lock()
+	initialLoad := rm.targetCPULoad
+	rm.cpuLoadMutex.Unlock()
+
+	stepInterval := duration / rampDownSteps
+	for step := 1; step <= rampDownSteps; step++ {
+		remaining := rampDownSteps - step
+
+		// 每步释放剩余内存的 1/(剩余步数+1)，使内存线性下降
+		rm.memoryMutex.Lock()
+		if allocated := rm.getTotalAllocatedMemory(); allocated > 0 {
+			rm.releaseBytes(allocated / uint64(remaining+1))
+		}
+		rm.memoryMutex.Unlock()
+
+		rm.adjustCPULoad(initialLoad * float64(remaining) / rampDownSteps)
+
+		if remaining > 0 {
+			time.Sleep(stepInterval)
+		}
+	}
+}
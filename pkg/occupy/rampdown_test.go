@@ -0,0 +1,47 @@
+package occupy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRampDownReleasesMemoryInSteps(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		RampDown: 200 * time.Millisecond,
+		Interval: 100 * time.Millisecond,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+	defer rm.CleanupAllResources()
+	rm.AllocateMemory(20 * mb)
+
+	done := make(chan struct{})
+	go func() {
+		rm.rampDown()
+		close(done)
+	}()
+
+	seen := []uint64{20 * mb}
+	for {
+		select {
+		case <-done:
+		case <-time.After(2 * time.Millisecond):
+			if got := rm.AllocatedBytes(); got != seen[len(seen)-1] {
+				if got > seen[len(seen)-1] {
+					t.Fatalf("逐步释放期间内存增加: %d -> %d", seen[len(seen)-1], got)
+				}
+				seen = append(seen, got)
+			}
+			continue
+		}
+		break
+	}
+
+	if got := rm.AllocatedBytes(); got != 0 {
+		t.Fatalf("逐步释放后 AllocatedBytes = %d, want 0", got)
+	}
+	// 起始值之外至少还应观察到两个中间值，而不是一次释放完毕
+	if len(seen) < 4 {
+		t.Fatalf("观察到的内存变化 = %v, want 分多步释放", seen)
+	}
+}
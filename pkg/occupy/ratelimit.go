@@ -0,0 +1,62 @@
+package occupy
+
+import (
+	"fmt"
+	"time"
+)
+
+// MinDiskWriteMBps 写入速率上限的最小值：每写入一块（writeChunkSize）后最长休眠约4秒，
+// 过低的速率会使单块写入后的等待超过停止时的清理超时
+const MinDiskWriteMBps = 1.0
+
+// ValidateDiskWriteRate 验证临时文件写入速率上限，0 表示不限速
+func ValidateDiskWriteRate(mbps float64) error {
+	if mbps < 0 {
+		return fmt.Errorf("磁盘写入速率不能为负数 (当前: %g)", mbps)
+	}
+	if mbps > 0 && mbps < MinDiskWriteMBps {
+		return fmt.Errorf("磁盘写入速率 %g MB/s 过低，最小为 %g MB/s", mbps, MinDiskWriteMBps)
+	}
+	return nil
+}
+
+// rateLimiter 基于休眠的速率限制器，使累计速率不超过 bytesPerSecond
+type rateLimiter struct {
+	bytesPerSecond float64
+	start          time.Time
+	consumed       uint64
+}
+
+// newRateLimiter 创建速率限制器，mbps 不大于0时返回 nil 表示不限速
+func newRateLimiter(mbps float64) *rateLimiter {
+	if mbps <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		bytesPerSecond: mbps * 1024 * 1024,
+		start:          time.Now(),
+	}
+}
+
+// wait 记录已消耗的字节数，必要时休眠以保持速率不超过上限。
+// 休眠期间 stop 关闭时立即返回 false
+func (l *rateLimiter) wait(n uint64, stop <-chan bool) bool {
+	if l == nil {
+		return true
+	}
+
+	l.consumed += n
+	expected := time.Duration(float64(l.consumed) / l.bytesPerSecond * float64(time.Second))
+	elapsed := time.Since(l.start)
+	if elapsed >= expected {
+		return true
+	}
+	timer := time.NewTimer(expected - elapsed)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
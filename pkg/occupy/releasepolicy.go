@@ -0,0 +1,48 @@
+package occupy
+
+// 释放内存时选择内存块的顺序
+const (
+	// MemoryReleaseTail 从最后分配的块开始释放（默认）
+	MemoryReleaseTail = "tail"
+	// MemoryReleaseHead 从最早分配的块开始释放
+	MemoryReleaseHead = "head"
+	// MemoryReleaseRandom 按随机顺序选择释放的块
+	MemoryReleaseRandom = "random"
+)
+
+// releaseOrder 按 MemoryReleasePolicy 返回释放时依次考虑的内存块下标（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) releaseOrder() []int {
+	n := len(rm.AllocatedMemory)
+	order := make([]int, n)
+	switch rm.Config.MemoryReleasePolicy {
+	case MemoryReleaseHead:
+		for i := range order {
+			order[i] = i
+		}
+	case MemoryReleaseRandom:
+		order = rm.memoryRandom().Perm(n)
+	default:
+		for i := range order {
+			order[i] = n - 1 - i
+		}
+	}
+	return order
+}
+
+// removeChunks 从 AllocatedMemory 中移除已释放的块，其余块保持原有顺序（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) removeChunks(freed map[int]bool) {
+	if len(freed) == 0 {
+		return
+	}
+	kept := rm.AllocatedMemory[:0]
+	for i, chunk := range rm.AllocatedMemory {
+		if !freed[i] {
+			kept = append(kept, chunk)
+		}
+	}
+	// 清空尾部的引用，使已释放的堆内存可以被GC回收
+	for i := len(kept); i < len(rm.AllocatedMemory); i++ {
+		rm.AllocatedMemory[i] = nil
+	}
+	rm.AllocatedMemory = kept
+}
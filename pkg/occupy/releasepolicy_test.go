@@ -0,0 +1,68 @@
+package occupy
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+)
+
+// releaseWithPolicy 按释放策略分配 10 个 1MB 的带编号内存块后释放 3MB，返回剩余块的编号
+func releaseWithPolicy(t *testing.T, policy string) []uint64 {
+	t.Helper()
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryReleasePolicy: policy,
+		MemoryFragment:      true,
+		MemoryFragmentMin:   mb,
+		MemoryFragmentMax:   mb,
+		TagAllocations:      true,
+		Seed:                1,
+		Interval:            MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+	defer rm.cleanupMemory()
+
+	rm.AllocateMemory(10 * mb)
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+	rm.releaseBytes(3 * mb)
+
+	ids := make([]uint64, 0, len(rm.AllocatedMemory))
+	for _, chunk := range rm.AllocatedMemory {
+		id, ok := ChunkID(chunk)
+		if !ok {
+			t.Fatal("内存块没有块头")
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestReleasePolicyChoosesChunks(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{MemoryReleaseTail, "[1 2 3 4 5 6 7]"},
+		{"", "[1 2 3 4 5 6 7]"},
+		{MemoryReleaseHead, "[4 5 6 7 8 9 10]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(releaseWithPolicy(t, tt.policy)); got != tt.want {
+			t.Errorf("策略 %q 释放后剩余 %s, want %s", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestReleasePolicyRandomIsNotTail(t *testing.T) {
+	ids := releaseWithPolicy(t, MemoryReleaseRandom)
+	if len(ids) != 7 {
+		t.Fatalf("释放后剩余 %d 块, want 7", len(ids))
+	}
+	if fmt.Sprint(ids) == "[1 2 3 4 5 6 7]" {
+		t.Errorf("随机策略释放的恰好是最后分配的块: 剩余 %v", ids)
+	}
+	// 剩余的块保持分配顺序
+	if !sort.SliceIsSorted(ids, func(i, j int) bool { return ids[i] < ids[j] }) {
+		t.Errorf("剩余块的顺序被打乱: %v", ids)
+	}
+}
@@ -0,0 +1,128 @@
+package occupy
+
+import "fmt"
+
+// Retarget 在运行中修改内存、CPU和磁盘目标，下一次调整时生效。
+// CPU目标按百分比设置，会覆盖 CPUCoreLoad；DiskPercents 为空时保持磁盘目标不变，
+// 否则数量需与磁盘目标一致
+func (rm *ResourceMonitor) Retarget(t Targets) error {
+	if err := validatePercent("内存", t.MemoryPercent); err != nil {
+		return err
+	}
+	if err := validatePercent("CPU", t.CPUPercent); err != nil {
+		return err
+	}
+	for _, percent := range t.DiskPercents {
+		if err := validatePercent("磁盘", percent); err != nil {
+			return err
+		}
+	}
+
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	if count := len(rm.diskTargets()); len(t.DiskPercents) > 0 && len(t.DiskPercents) != count {
+		return fmt.Errorf("磁盘目标数量不一致: 需要 %d 个，实际 %d 个", count, len(t.DiskPercents))
+	}
+	t.DiskPercents = append([]float64(nil), t.DiskPercents...)
+	if len(t.DiskPercents) == 0 && rm.pendingTargets != nil {
+		t.DiskPercents = rm.pendingTargets.DiskPercents
+	}
+	rm.pendingTargets = &t
+	return nil
+}
+
+// applyRetarget 将 Retarget 设置的目标写入配置（仅由监控协程调用）
+func (rm *ResourceMonitor) applyRetarget() {
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	t := rm.pendingTargets
+	if t == nil {
+		return
+	}
+	rm.pendingTargets = nil
+
+	rm.Config.MemoryPercent = t.MemoryPercent
+	rm.Config.CPUPercent = t.CPUPercent
+	rm.Config.CPUCoreLoad = 0
+	if len(t.DiskPercents) > 0 {
+		if len(rm.Config.DiskTargets) > 0 {
+			// 复制后再修改，避免改动调用方传入的切片
+			diskTargets := append([]DiskTarget(nil), rm.Config.DiskTargets...)
+			for i := range diskTargets {
+				diskTargets[i].Percent = t.DiskPercents[i]
+			}
+			rm.Config.DiskTargets = diskTargets
+		} else {
+			rm.Config.DiskPercent = t.DiskPercents[0]
+		}
+	}
+
+	logInfof("目标已修改: 内存 %.1f%%, CPU %.1f%%, 磁盘 %s",
+		rm.Config.MemoryPercent, rm.Config.CPUPercent, rm.formatDiskTargets())
+}
+
+// CurrentTargets 获取当前的资源目标：优先返回尚未生效的 Retarget 目标，
+// 其次为最近一次调整使用的目标，尚未调整时按配置计算
+func (rm *ResourceMonitor) CurrentTargets() Targets {
+	rm.retargetMutex.Lock()
+	pending := rm.pendingTargets != nil
+	rm.retargetMutex.Unlock()
+
+	rm.measurementMutex.Lock()
+	last := rm.lastTargets
+	rm.measurementMutex.Unlock()
+
+	if last != nil && !pending {
+		return *last
+	}
+	return rm.configuredTargets()
+}
+
+// configuredTargets 获取配置的目标（不含波形、突发、镜像等动态调整），
+// 优先返回尚未生效的 Retarget 目标，用于在此基础上修改部分目标
+func (rm *ResourceMonitor) configuredTargets() Targets {
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	base := rm.baseTargets()
+	if t := rm.pendingTargets; t != nil {
+		pending := *t
+		if len(pending.DiskPercents) == 0 {
+			pending.DiskPercents = base.DiskPercents
+		}
+		return pending
+	}
+	return base
+}
+
+// Pause 暂停调整，已占用的内存、CPU负载和临时文件保持不变；测量和安全看门狗照常运行
+func (rm *ResourceMonitor) Pause() {
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	if !rm.paused {
+		rm.paused = true
+		logInfof("已暂停调整")
+	}
+}
+
+// Resume 恢复调整
+func (rm *ResourceMonitor) Resume() {
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	if rm.paused {
+		rm.paused = false
+		logInfof("已恢复调整")
+	}
+}
+
+// Paused 是否已暂停调整
+func (rm *ResourceMonitor) Paused() bool {
+	rm.retargetMutex.Lock()
+	defer rm.retargetMutex.Unlock()
+
+	return rm.paused
+}
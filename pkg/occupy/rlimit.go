@@ -0,0 +1,73 @@
+package occupy
+
+// DefaultRlimitHeadroom 设置 RlimitMemoryBytes 时为运行时和其他分配预留的字节数，
+// 主动分配不会超过 限制 - 启动时用量 - 预留
+const DefaultRlimitHeadroom = 64 * 1024 * 1024
+
+// applyMemoryRlimit 设置了 RlimitMemoryBytes 时为本进程设置内存资源限制，
+// 并据此计算主动分配的上限，使正常调整不会触及硬限制；已设置过时不再重复设置
+func (rm *ResourceMonitor) applyMemoryRlimit() {
+	limit := rm.Config.RlimitMemoryBytes
+	if limit == 0 {
+		return
+	}
+	rm.memoryMutex.Lock()
+	set := rm.rlimitSet
+	rm.memoryMutex.Unlock()
+	if set {
+		return
+	}
+
+	usage, err := memoryRlimitUsage()
+	if err != nil {
+		logWarnf("获取进程内存用量失败，按0计算: %v", err)
+	}
+	name, err := setMemoryRlimit(limit)
+	if err != nil {
+		logErrorf("设置进程内存资源限制失败: %v", err)
+		return
+	}
+
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+	rm.rlimitBudget = 0
+	if usage+DefaultRlimitHeadroom < limit {
+		rm.rlimitBudget = limit - usage - DefaultRlimitHeadroom
+	}
+	rm.rlimitSet = true
+	logInfof("已设置 %s 为 %s (当前用量 %s)，最多分配 %s",
+		name, FormatBytes(limit), FormatBytes(usage), FormatBytes(rm.rlimitBudget))
+}
+
+// maxMemoryBytes 本进程最多分配的内存字节数：MaxMemoryBytes 与资源限制允许的分配量中较小者，
+// limited 为 false 表示不限制。内存基线同样受资源限制，从允许的分配量中扣除（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) maxMemoryBytes() (maxBytes uint64, limited bool) {
+	maxBytes = rm.Config.MaxMemoryBytes
+	if !rm.rlimitSet {
+		return maxBytes, maxBytes > 0
+	}
+	budget := rm.rlimitBudget
+	if baseline := rm.baselineBytes(); baseline < budget {
+		budget -= baseline
+	} else {
+		budget = 0
+	}
+	if maxBytes == 0 || budget < maxBytes {
+		return budget, true
+	}
+	return maxBytes, true
+}
+
+// rlimitAllocFailed 设置了资源限制时分配失败，将分配上限降到当前已分配的字节数，
+// 之后不再继续增长（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) rlimitAllocFailed() {
+	if !rm.rlimitSet {
+		return
+	}
+	allocated := rm.getTotalAllocatedMemory() + rm.baselineBytes()
+	if allocated >= rm.rlimitBudget {
+		return
+	}
+	rm.rlimitBudget = allocated
+	logWarnf("分配内存超出进程内存资源限制，停止继续分配 (已分配 %s)", FormatBytes(allocated))
+}
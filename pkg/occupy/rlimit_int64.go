@@ -0,0 +1,25 @@
+//go:build freebsd || dragonfly
+
+package occupy
+
+import (
+	"math"
+	"syscall"
+)
+
+// newRlimit 构造软、硬限制均为 limit 的 syscall.Rlimit；这些平台的字段为 int64，超出范围时按 RLIM_INFINITY 处理
+func newRlimit(limit uint64) syscall.Rlimit {
+	value := int64(math.MaxInt64)
+	if limit < math.MaxInt64 {
+		value = int64(limit)
+	}
+	return syscall.Rlimit{Cur: value, Max: value}
+}
+
+// rlimitCur syscall.Rlimit 的软限制，负值（RLIM_INFINITY 为 -1 的平台）视为无限制
+func rlimitCur(rlimit *syscall.Rlimit) uint64 {
+	if rlimit.Cur < 0 {
+		return math.MaxUint64
+	}
+	return uint64(rlimit.Cur)
+}
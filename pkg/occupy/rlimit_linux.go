@@ -0,0 +1,42 @@
+package occupy
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Linux 上 RLIMIT_DATA 覆盖堆和私有可写映射，且不计入Go运行时预留但未使用的地址空间
+const (
+	memoryRlimitResource = syscall.RLIMIT_DATA
+	memoryRlimitName     = "RLIMIT_DATA"
+)
+
+// memoryRlimitUsage 本进程当前计入 RLIMIT_DATA 的字节数（/proc/self/status 中的 VmData）
+func memoryRlimitUsage() (uint64, error) {
+	file, err := os.Open("/proc/self/status")
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmData:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("解析 VmData 失败: %v", err)
+		}
+		return kb * 1024, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("/proc/self/status 中没有 VmData")
+}
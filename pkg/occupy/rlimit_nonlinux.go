@@ -0,0 +1,29 @@
+//go:build unix && !linux && !openbsd
+
+package occupy
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// 其他Unix平台的 RLIMIT_DATA 不限制 mmap，使用 RLIMIT_AS 限制地址空间
+const (
+	memoryRlimitResource = syscall.RLIMIT_AS
+	memoryRlimitName     = "RLIMIT_AS"
+)
+
+// memoryRlimitUsage 本进程当前的虚拟内存大小
+func memoryRlimitUsage() (uint64, error) {
+	proc, err := process.NewProcess(int32(os.Getpid()))
+	if err != nil {
+		return 0, err
+	}
+	info, err := proc.MemoryInfo()
+	if err != nil {
+		return 0, err
+	}
+	return info.VMS, nil
+}
@@ -0,0 +1,24 @@
+//go:build !unix || openbsd
+
+package occupy
+
+import (
+	"errors"
+)
+
+// OpenBSD 的 syscall 包没有 RLIMIT_AS，与非Unix平台一样不支持
+
+// setMemoryRlimit 当前平台不支持 setrlimit
+func setMemoryRlimit(limit uint64) (string, error) {
+	return "", errors.New("当前平台不支持设置进程内存资源限制")
+}
+
+// memoryRlimitUsage 当前平台不支持 setrlimit
+func memoryRlimitUsage() (uint64, error) {
+	return 0, errors.New("当前平台不支持设置进程内存资源限制")
+}
+
+// currentMemoryRlimit 当前平台不支持 setrlimit，始终视为未限制
+func currentMemoryRlimit() (uint64, error) {
+	return 0, nil
+}
@@ -0,0 +1,17 @@
+//go:build unix && !openbsd && !freebsd && !dragonfly
+
+package occupy
+
+import (
+	"syscall"
+)
+
+// newRlimit 构造软、硬限制均为 limit 的 syscall.Rlimit
+func newRlimit(limit uint64) syscall.Rlimit {
+	return syscall.Rlimit{Cur: limit, Max: limit}
+}
+
+// rlimitCur syscall.Rlimit 的软限制
+func rlimitCur(rlimit *syscall.Rlimit) uint64 {
+	return rlimit.Cur
+}
@@ -0,0 +1,27 @@
+//go:build unix && !openbsd
+
+package occupy
+
+import (
+	"syscall"
+)
+
+// setMemoryRlimit 将本进程的内存资源限制（软、硬限制）设为 limit，返回所用资源类型的名称
+func setMemoryRlimit(limit uint64) (string, error) {
+	rlimit := newRlimit(limit)
+	return memoryRlimitName, syscall.Setrlimit(memoryRlimitResource, &rlimit)
+}
+
+// currentMemoryRlimit 本进程当前的内存资源软限制，未限制时返回 0
+func currentMemoryRlimit() (uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(memoryRlimitResource, &rlimit); err != nil {
+		return 0, err
+	}
+	// RLIM_INFINITY 在各平台的取值不同（Linux 为 ^uint64(0)，macOS 为 1<<63-1），超过 1<<62 均视为未限制
+	cur := rlimitCur(&rlimit)
+	if cur >= 1<<62 {
+		return 0, nil
+	}
+	return cur, nil
+}
@@ -0,0 +1,72 @@
+//go:build unix && !openbsd
+
+package occupy
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// rlimitChildEnv 设置时在子进程中执行资源限制测试，避免限制影响其他测试
+const rlimitChildEnv = "GO_OCCUPY_RLIMIT_CHILD"
+
+func TestMemoryRlimitStopsGrowthWithoutCrash(t *testing.T) {
+	if os.Getenv(rlimitChildEnv) == "1" {
+		runMemoryRlimitChild(t)
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestMemoryRlimitStopsGrowthWithoutCrash$", "-test.v")
+	cmd.Env = append(os.Environ(), rlimitChildEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("子进程失败: %v\n%s", err, out)
+	}
+	if strings.Contains(string(out), "--- SKIP") {
+		t.Skipf("子进程跳过:\n%s", out)
+	}
+	if !strings.Contains(string(out), "--- PASS") {
+		t.Fatalf("子进程未通过:\n%s", out)
+	}
+}
+
+// runMemoryRlimitChild 在子进程中设置较小的资源限制后尝试超出限制分配
+func runMemoryRlimitChild(t *testing.T) {
+	const mb = 1024 * 1024
+	usage, err := memoryRlimitUsage()
+	if err != nil {
+		t.Skipf("无法获取进程内存用量: %v", err)
+	}
+	buf := captureLog(t, LogInfo)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryAllocator:   MemoryAllocatorMmap,
+		RlimitMemoryBytes: usage + DefaultRlimitHeadroom + 32*mb,
+		Interval:          MinInterval,
+	}, newFakeMetrics(1<<40, 1<<40))
+	defer rm.CleanupAllResources()
+
+	rm.applyMemoryRlimit()
+	if !rm.rlimitSet {
+		t.Fatalf("未设置资源限制:\n%s", buf)
+	}
+	rm.AllocateMemory(256 * mb)
+	if got := rm.AllocatedBytes(); got == 0 || got > 32*mb {
+		t.Fatalf("资源限制下 AllocatedBytes = %d, want 大于0且不超过 %d", got, 32*mb)
+	}
+
+	// 绕过按限制计算的上限，直接触及硬限制：分配失败后停止增长而不是崩溃
+	rm.memoryMutex.Lock()
+	rm.rlimitBudget = 1 << 40
+	rm.memoryMutex.Unlock()
+	rm.AllocateMemory(256 * mb)
+	if !strings.Contains(buf.String(), "停止继续分配") {
+		t.Fatalf("超出资源限制时未停止分配:\n%s", buf)
+	}
+	allocated := rm.AllocatedBytes()
+	rm.AllocateMemory(256 * mb)
+	if got := rm.AllocatedBytes(); got != allocated {
+		t.Fatalf("分配失败后仍继续增长: %d -> %d", allocated, got)
+	}
+}
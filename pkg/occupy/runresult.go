@@ -0,0 +1,127 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAlreadyRunning 监控已在运行，不能再次 Run
+var ErrAlreadyRunning = errors.New("监控已在运行")
+
+// RunResult 一次阻塞运行的完整结果，便于自动化压测程序直接判断，而不必解析日志
+type RunResult struct {
+	// Labels 配置的标签，见 ResourceConfig.Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	// Start/End 运行开始和完成清理的时间，Duration 为两者之差
+	Start    time.Time     `json:"start"`
+	End      time.Time     `json:"end"`
+	Duration time.Duration `json:"duration"`
+	// Targets 运行结束前最后一次调整使用的目标，尚未调整时为 nil
+	Targets *Targets `json:"targets"`
+	// Memory/CPU/Disk 各资源实际使用率的分布，Max 为峰值，Mean 为平均值
+	Memory LevelStats   `json:"memory"`
+	CPU    LevelStats   `json:"cpu"`
+	Disk   []LevelStats `json:"disk"`
+	// Converged 运行期间是否曾经所有目标都在容忍范围内，ConvergeTime 为从开始到首次达到目标的时间
+	Converged    bool          `json:"converged"`
+	ConvergeTime time.Duration `json:"converge_time"`
+	// ConvergeMissed 设置 ConvergeDeadline 时是否因未能在截止时间内达到目标而退出
+	ConvergeMissed bool `json:"converge_missed"`
+	// WatchdogTrips 安全看门狗触发紧急释放的次数
+	WatchdogTrips int `json:"watchdog_trips"`
+	// CleanupErrors 清理临时文件时删除失败的次数
+	CleanupErrors int `json:"cleanup_errors"`
+	// Err 导致运行结束的错误，正常停止时为 nil；Error 为其文本，便于输出JSON
+	Err   error  `json:"-"`
+	Error string `json:"error,omitempty"`
+	// ExitCode 与命令行程序相同的退出码，见 Outcome.ExitCode
+	ExitCode int `json:"exit_code"`
+}
+
+// Run 按配置创建监控器并阻塞运行，直到 ctx 结束或因错误停止，完成清理后返回运行结果。
+// 配置无效时不启动并返回错误
+func Run(ctx context.Context, config ResourceConfig) (RunResult, error) {
+	if err := ValidateConfig(config); err != nil {
+		return RunResult{}, err
+	}
+	return NewResourceMonitor(config).Run(ctx), nil
+}
+
+// Run 阻塞运行监控，直到 ctx 结束、调用 Stop 或因错误停止，完成清理后返回运行结果；
+// 已在运行时立即返回 Err 为 ErrAlreadyRunning 的结果
+func (rm *ResourceMonitor) Run(ctx context.Context) RunResult {
+	if !rm.beginRun() {
+		return RunResult{Err: ErrAlreadyRunning, Error: ErrAlreadyRunning.Error(), ExitCode: ExitError}
+	}
+	rm.run(ctx)
+	return rm.Result()
+}
+
+// Result 获取最近一次运行的结果，应在 Done 关闭后调用
+func (rm *ResourceMonitor) Result() RunResult {
+	summary := rm.Summary()
+	outcome := rm.Outcome()
+
+	rm.measurementMutex.Lock()
+	result := RunResult{
+		Labels: summary.Labels,
+		Start:  rm.runStart,
+		End:    rm.runEnd,
+		Memory: summary.Memory,
+		CPU:    summary.CPU,
+		Disk:   summary.Disk,
+	}
+	if rm.lastTargets != nil {
+		targets := *rm.lastTargets
+		result.Targets = &targets
+	}
+	if !rm.convergedAt.IsZero() {
+		result.Converged = true
+		result.ConvergeTime = rm.convergedAt.Sub(rm.runStart)
+	}
+	rm.measurementMutex.Unlock()
+
+	if !result.Start.IsZero() && !result.End.IsZero() {
+		result.Duration = result.End.Sub(result.Start)
+	}
+	result.ConvergeMissed = outcome.ConvergeMissed()
+	result.WatchdogTrips = outcome.WatchdogTrips
+	result.CleanupErrors = outcome.CleanupErrors
+	result.Err = outcome.Err
+	if outcome.Err != nil {
+		result.Error = outcome.Err.Error()
+	}
+	result.ExitCode = outcome.ExitCode()
+	return result
+}
+
+// markRunStart 记录运行开始的时间
+func (rm *ResourceMonitor) markRunStart() {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	rm.runStart = time.Now()
+}
+
+// markRunEnd 记录运行完成清理的时间
+func (rm *ResourceMonitor) markRunEnd() {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	rm.runEnd = time.Now()
+}
+
+// recordConvergence 所有目标首次在容忍范围内时记录时间
+func (rm *ResourceMonitor) recordConvergence() {
+	if len(rm.unmetTargets()) > 0 {
+		return
+	}
+
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	if rm.convergedAt.IsZero() {
+		rm.convergedAt = time.Now()
+	}
+}
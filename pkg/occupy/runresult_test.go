@@ -0,0 +1,96 @@
+package occupy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunResultReflectsControlledRun(t *testing.T) {
+	const mb = 1024 * 1024
+	// 所有资源一开始就处于目标：内存 40%、CPU 20%、磁盘 10%
+	metrics := newFakeMetrics(100*mb, 100*mb)
+	metrics.setMemoryUsed(40 * mb)
+	metrics.setCPU(20)
+	metrics.setDiskUsed(10 * mb)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 40,
+		CPUPercent:    20,
+		CPUCount:      1,
+		DiskTargets:   []DiskTarget{{Path: t.TempDir(), Percent: 10}},
+		Interval:      MinInterval,
+		Labels:        map[string]string{"run": "result"},
+	}, metrics)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	result := rm.Run(ctx)
+
+	if result.Err != nil || result.Error != "" || result.ExitCode != ExitOK {
+		t.Fatalf("Err = %v, Error = %q, ExitCode = %d, want nil, \"\", %d", result.Err, result.Error, result.ExitCode, ExitOK)
+	}
+	if result.Start.Before(start) || result.End.Before(result.Start) || result.Duration != result.End.Sub(result.Start) {
+		t.Errorf("Start = %v, End = %v, Duration = %v 不一致", result.Start, result.End, result.Duration)
+	}
+	if result.Duration < time.Second || result.Duration > 5*time.Second {
+		t.Errorf("Duration = %v, want 约 1.5s", result.Duration)
+	}
+	if result.Labels["run"] != "result" {
+		t.Errorf("Labels = %v, want run=result", result.Labels)
+	}
+	if result.Targets == nil || result.Targets.MemoryPercent != 40 || result.Targets.CPUPercent != 20 {
+		t.Errorf("Targets = %+v, want 内存 40%%, CPU 20%%", result.Targets)
+	}
+	if result.Memory.Samples == 0 || result.Memory.Max != 40 || result.Memory.Mean != 40 {
+		t.Errorf("Memory = %+v, want 峰值和均值 40", result.Memory)
+	}
+	if result.CPU.Max != 20 || result.CPU.Mean != 20 {
+		t.Errorf("CPU = %+v, want 峰值和均值 20", result.CPU)
+	}
+	if len(result.Disk) != 1 || result.Disk[0].Max != 10 {
+		t.Errorf("Disk = %+v, want 1 个磁盘峰值 10", result.Disk)
+	}
+	if !result.Converged || result.ConvergeTime <= 0 || result.ConvergeTime > result.Duration {
+		t.Errorf("Converged = %v, ConvergeTime = %v, want 运行期间达到目标", result.Converged, result.ConvergeTime)
+	}
+	if result.ConvergeMissed || result.WatchdogTrips != 0 || result.CleanupErrors != 0 {
+		t.Errorf("ConvergeMissed = %v, WatchdogTrips = %d, CleanupErrors = %d, want false, 0, 0",
+			result.ConvergeMissed, result.WatchdogTrips, result.CleanupErrors)
+	}
+}
+
+func TestRunResultReportsMissedDeadline(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent:    99,
+		MaxMemoryBytes:   4 * mb,
+		Interval:         MinInterval,
+		ConvergeDeadline: time.Second,
+	}, newFakeMetrics(100*mb, 100*mb))
+
+	result := rm.Run(context.Background())
+	if !errors.Is(result.Err, ErrConvergeDeadline) || result.Error == "" {
+		t.Fatalf("Err = %v, Error = %q, want ErrConvergeDeadline", result.Err, result.Error)
+	}
+	if !result.ConvergeMissed || result.Converged || result.ExitCode != ExitConvergeMissed {
+		t.Errorf("ConvergeMissed = %v, Converged = %v, ExitCode = %d, want true, false, %d",
+			result.ConvergeMissed, result.Converged, result.ExitCode, ExitConvergeMissed)
+	}
+}
+
+func TestRunWhileRunningReturnsErrAlreadyRunning(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+	ctx, cancel := context.WithCancel(context.Background())
+	results := make(chan RunResult, 1)
+	go func() { results <- rm.Run(ctx) }()
+	waitFor(t, 5*time.Second, "监控开始运行", rm.Running)
+
+	if result := rm.Run(context.Background()); !errors.Is(result.Err, ErrAlreadyRunning) || result.ExitCode != ExitError {
+		t.Errorf("第二次 Run: Err = %v, ExitCode = %d, want ErrAlreadyRunning, %d", result.Err, result.ExitCode, ExitError)
+	}
+	cancel()
+	<-results
+}
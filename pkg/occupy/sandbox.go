@@ -0,0 +1,151 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+// SandboxChildCommand 沙箱模式下父进程以该隐藏子命令启动自身作为子进程
+const SandboxChildCommand = "__sandbox-child"
+
+// DefaultSandboxGracePeriod 停止时等待子进程完成清理的默认时间（可覆盖最长50s的逐步释放），超时后强制结束子进程
+const DefaultSandboxGracePeriod = 60 * time.Second
+
+// Sandbox 在子进程中执行实际的资源占用，父进程只负责监督：
+// 子进程崩溃或被 OOM killer 杀死不会影响父进程，父进程随后清理子进程残留的临时文件
+type Sandbox struct {
+	// Config 子进程使用的配置，用于确定需要清理的临时文件目录和前缀
+	Config ResourceConfig
+	// Path 和 Args 启动子进程的可执行文件和参数
+	Path string
+	Args []string
+	// GracePeriod 停止时等待子进程退出的时间，为0时使用 DefaultSandboxGracePeriod
+	GracePeriod time.Duration
+}
+
+// Run 启动子进程并等待其退出，返回子进程的退出码。stop 关闭时向子进程发送 SIGTERM，
+// 超过 GracePeriod 仍未退出时强制结束。子进程未正常退出时清理其残留的临时文件
+// （设置了 NoCleanupOnError 且子进程以错误码退出时保留，以便排查）
+func (s *Sandbox) Run(stop <-chan struct{}) (int, error) {
+	cmd := exec.Command(s.Path, s.Args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = sandboxProcAttr()
+
+	// Linux 的 Pdeathsig 在创建子进程的线程退出时触发，因此启动和等待子进程都在锁定的线程上进行
+	started := make(chan error, 1)
+	done := make(chan error, 1)
+	startTime := time.Now()
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+
+		if err := cmd.Start(); err != nil {
+			started <- err
+			return
+		}
+		started <- nil
+		done <- cmd.Wait()
+	}()
+	if err := <-started; err != nil {
+		return 1, fmt.Errorf("启动子进程失败: %v", err)
+	}
+	logInfof("沙箱模式: 子进程已启动 (PID %d)", cmd.Process.Pid)
+
+	var err error
+	select {
+	case err = <-done:
+	case <-stop:
+		err = s.stopChild(cmd.Process, done)
+	}
+
+	code := 0
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		return 1, fmt.Errorf("等待子进程失败: %v", err)
+	}
+
+	if code == 0 {
+		logInfof("沙箱模式: 子进程已正常退出")
+		return 0, nil
+	}
+	// 被信号终止时 ExitCode 为 -1
+	if code < 0 {
+		logErrorf("沙箱模式: 子进程异常终止: %v", err)
+		code = 1
+	} else {
+		logErrorf("沙箱模式: 子进程退出码 %d", code)
+		if s.Config.NoCleanupOnError {
+			logWarnf("沙箱模式: 已设置保留资源，不清理子进程的临时文件")
+			return code, nil
+		}
+	}
+	s.cleanupResidue(cmd.Process.Pid, startTime)
+	return code, nil
+}
+
+// stopChild 请求子进程停止，超过 GracePeriod 仍未退出时强制结束，返回子进程的等待结果
+func (s *Sandbox) stopChild(process *os.Process, done <-chan error) error {
+	grace := s.GracePeriod
+	if grace <= 0 {
+		grace = DefaultSandboxGracePeriod
+	}
+
+	logInfof("沙箱模式: 正在停止子进程 (PID %d)...", process.Pid)
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		process.Kill()
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(grace):
+	}
+
+	logWarnf("沙箱模式: 子进程在 %v 内未退出，强制结束", grace)
+	process.Kill()
+	return <-done
+}
+
+// cleanupResidue 删除子进程可能残留的临时文件：各磁盘目标的写入目录，以及 shm/file 分配方式的内存文件。
+// 只删除子进程 pid 创建的文件（见 createdByProcess），不影响同一目录中其他实例的文件
+func (s *Sandbox) cleanupResidue(pid int, started time.Time) {
+	rm := &ResourceMonitor{Config: s.Config}
+	dirs := make([]string, 0)
+	for _, target := range rm.diskTargets() {
+		dirs = append(dirs, rm.writeDir(target))
+	}
+	switch s.Config.MemoryAllocator {
+	case MemoryAllocatorFile:
+		dirs = append(dirs, memoryFileDir(s.Config))
+	case MemoryAllocatorShm:
+		if dir := shmSegmentDir(); dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	prefix := rm.filePrefix()
+	owned := createdByProcess(prefix, pid, started)
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		removed, _, reclaimed, err := removeTempFiles(dir, prefix, owned)
+		if err != nil {
+			logErrorf("沙箱模式: 清理 %s 中的临时文件失败: %v", dir, err)
+			continue
+		}
+		if removed > 0 {
+			logInfof("沙箱模式: 已清理子进程残留的临时文件 %s %d 个，回收 %s", dir, removed, FormatBytes(reclaimed))
+		}
+	}
+}
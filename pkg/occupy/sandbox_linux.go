@@ -0,0 +1,10 @@
+package occupy
+
+import (
+	"syscall"
+)
+
+// sandboxProcAttr 父进程意外退出时内核向子进程发送 SIGKILL，避免子进程脱离监督继续占用资源
+func sandboxProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Pdeathsig: syscall.SIGKILL}
+}
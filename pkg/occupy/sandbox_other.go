@@ -0,0 +1,12 @@
+//go:build !linux
+
+package occupy
+
+import (
+	"syscall"
+)
+
+// sandboxProcAttr 当前平台不支持父进程退出时通知子进程
+func sandboxProcAttr() *syscall.SysProcAttr {
+	return nil
+}
@@ -0,0 +1,106 @@
+//go:build unix
+
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sandboxChildEnv 设置时测试二进制作为沙箱子进程运行：为 crash 时写入临时文件后被强制结束，为 wait 时等待父进程停止
+const sandboxChildEnv = "GO_OCCUPY_SANDBOX_CHILD"
+
+func TestSandboxChild(t *testing.T) {
+	switch os.Getenv(sandboxChildEnv) {
+	case "crash":
+		dir := os.Getenv("GO_OCCUPY_TEMP_DIR")
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			DiskTargets: []DiskTarget{{Path: dir, Percent: 50}},
+			Interval:    MinInterval,
+		}, newFakeMetrics(1<<30, 100*1024*1024))
+		if err := rm.createTempFiles(dir, 1024*1024); err != nil {
+			t.Fatalf("createTempFiles: %v", err)
+		}
+		// 模拟崩溃或被 OOM killer 杀死，来不及清理临时文件
+		p, _ := os.FindProcess(os.Getpid())
+		p.Kill()
+		time.Sleep(time.Minute)
+	case "wait":
+		time.Sleep(time.Minute)
+	default:
+		t.Skip("仅在沙箱测试的子进程中运行")
+	}
+}
+
+// newTestSandbox 创建以测试二进制作为子进程的沙箱，子进程和父进程使用同一个临时目录
+func newTestSandbox(t *testing.T, mode string) (*Sandbox, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("GO_OCCUPY_TEMP_DIR", dir)
+	t.Setenv(sandboxChildEnv, mode)
+	return &Sandbox{
+		Config:      ResourceConfig{DiskTargets: []DiskTarget{{Path: dir, Percent: 50}}},
+		Path:        os.Args[0],
+		Args:        []string{"-test.run=^TestSandboxChild$"},
+		GracePeriod: 5 * time.Second,
+	}, dir
+}
+
+func TestSandboxCleansUpAfterAbruptChildExit(t *testing.T) {
+	buf := captureLog(t, LogInfo)
+	sandbox, dir := newTestSandbox(t, "crash")
+	// 同一目录中使用相同前缀的其他实例：子进程启动前创建的磁盘文件和其他进程的内存文件
+	others := []string{
+		filepath.Join(dir, fmt.Sprintf("%s%d_0.dat", DefaultFilePrefix, time.Now().UnixNano())),
+		filepath.Join(dir, fmt.Sprintf("%s%d_mem_0.dat", DefaultFilePrefix, os.Getpid())),
+	}
+	for _, file := range others {
+		if err := os.WriteFile(file, []byte("other"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	code, err := sandbox.Run(make(chan struct{}))
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if code != 1 {
+		t.Errorf("子进程被强制结束时退出码 = %d, want 1", code)
+	}
+	if !strings.Contains(buf.String(), "已清理子进程残留的临时文件") {
+		t.Errorf("未清理子进程写入的临时文件:\n%s", buf)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(others)
+	if !reflect.DeepEqual(matches, others) {
+		t.Errorf("清理后剩余文件 = %v, want 只保留其他实例的文件 %v", matches, others)
+	}
+}
+
+func TestSandboxStopReapsChild(t *testing.T) {
+	sandbox, _ := newTestSandbox(t, "wait")
+
+	stop := make(chan struct{})
+	time.AfterFunc(500*time.Millisecond, func() { close(stop) })
+	start := time.Now()
+	code, err := sandbox.Run(stop)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	// 测试二进制收到 SIGTERM 后直接退出
+	if code == 0 {
+		t.Errorf("停止后子进程退出码 = 0, want 非0")
+	}
+	if elapsed := time.Since(start); elapsed > sandbox.GracePeriod {
+		t.Errorf("停止子进程耗时 %v，超过 %v", elapsed, sandbox.GracePeriod)
+	}
+}
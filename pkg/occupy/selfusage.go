@@ -0,0 +1,63 @@
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// SelfUsage 本进程自身的资源占用，包括为达到目标而分配的内存和CPU负载
+type SelfUsage struct {
+	// RSS 常驻内存字节数
+	RSS uint64
+	// CPUPercent 自上次采样以来的CPU使用率，以单核为100%
+	CPUPercent float64
+	// SystemCPUPercent CPUPercent 折算为占全部核心的百分比，可与系统CPU使用率直接比较
+	SystemCPUPercent float64
+}
+
+// SelfUsage 采集本进程自身的资源占用，CPU使用率为自上次调用以来的平均值（首次调用为启动以来）
+func (rm *ResourceMonitor) SelfUsage() (SelfUsage, error) {
+	rm.selfMutex.Lock()
+	defer rm.selfMutex.Unlock()
+
+	if rm.selfProcess == nil {
+		proc, err := process.NewProcess(int32(os.Getpid()))
+		if err != nil {
+			return SelfUsage{}, fmt.Errorf("获取本进程信息失败: %v", err)
+		}
+		rm.selfProcess = proc
+	}
+
+	memInfo, err := rm.selfProcess.MemoryInfo()
+	if err != nil {
+		return SelfUsage{}, fmt.Errorf("获取本进程内存信息失败: %v", err)
+	}
+	cpuPercent, err := rm.selfProcess.Percent(0)
+	if err != nil {
+		return SelfUsage{}, fmt.Errorf("获取本进程CPU信息失败: %v", err)
+	}
+
+	return SelfUsage{
+		RSS:              memInfo.RSS,
+		CPUPercent:       cpuPercent,
+		SystemCPUPercent: cpuPercent / float64(runtime.NumCPU()),
+	}, nil
+}
+
+// logSelfUsage 设置 ReportSelf 时输出本进程自身的资源占用
+func (rm *ResourceMonitor) logSelfUsage(logf func(format string, args ...interface{})) {
+	if !rm.Config.ReportSelf {
+		return
+	}
+
+	usage, err := rm.SelfUsage()
+	if err != nil {
+		logWarnf("%v", err)
+		return
+	}
+	logf("本进程占用: RSS %s, CPU %.1f%% (占系统 %.1f%%)",
+		FormatBytes(usage.RSS), usage.CPUPercent, usage.SystemCPUPercent)
+}
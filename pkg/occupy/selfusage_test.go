@@ -0,0 +1,33 @@
+package occupy
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelfUsageReportsRSS(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{ReportSelf: true, Interval: MinInterval})
+	usage, err := rm.SelfUsage()
+	if err != nil {
+		t.Fatalf("SelfUsage: %v", err)
+	}
+	if usage.RSS == 0 {
+		t.Fatal("本进程 RSS = 0, want 大于0")
+	}
+
+	buf := captureLog(t, LogInfo)
+	rm.logSelfUsage(logInfof)
+	if !strings.Contains(buf.String(), "本进程占用: RSS ") {
+		t.Fatalf("未输出本进程占用:\n%s", buf)
+	}
+	if strings.Contains(buf.String(), "RSS 0 B") {
+		t.Fatalf("本进程占用的 RSS 为0:\n%s", buf)
+	}
+
+	rm.Config.ReportSelf = false
+	buf = captureLog(t, LogInfo)
+	rm.logSelfUsage(logInfof)
+	if buf.String() != "" {
+		t.Fatalf("未设置 ReportSelf 时输出了:\n%s", buf)
+	}
+}
@@ -0,0 +1,339 @@
+package occupy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobRequest 创建任务的请求参数
+type JobRequest struct {
+	Name          string  `json:"name"`
+	MemoryPercent float64 `json:"memory_percent"`
+	CPUPercent    float64 `json:"cpu_percent"`
+	DiskPercent   float64 `json:"disk_percent"`
+	Interval      string  `json:"interval"`
+}
+
+// JobInfo 任务信息
+type JobInfo struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	MemoryPercent  float64   `json:"memory_percent"`
+	CPUPercent     float64   `json:"cpu_percent"`
+	DiskPercent    float64   `json:"disk_percent"`
+	Interval       string    `json:"interval"`
+	FilePrefix     string    `json:"file_prefix"`
+	AllocatedBytes uint64    `json:"allocated_bytes"`
+	TempFileBytes  uint64    `json:"temp_file_bytes"`
+	Status         string    `json:"status"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Job 由服务管理的单个占用任务
+type Job struct {
+	ID        string
+	Name      string
+	Monitor   *ResourceMonitor
+	CreatedAt time.Time
+	// config 创建任务时的配置副本，info 读取它而不是监控协程可能修改的 Monitor.Config
+	config ResourceConfig
+	// stopped 由 StopJob 设置，可能与其他请求中的 info 并发访问
+	stopped atomic.Bool
+}
+
+// info 生成任务信息：监控已退出时按退出原因报告 stopped 或 failed，
+// 否则报告 running（StopJob 已请求停止时为 stopped）
+func (j *Job) info() JobInfo {
+	status := "running"
+	var errMsg string
+	select {
+	case <-j.Monitor.Done():
+		status = "stopped"
+		if err := j.Monitor.Err(); err != nil {
+			status = "failed"
+			errMsg = err.Error()
+		}
+	default:
+		if j.stopped.Load() {
+			status = "stopped"
+		}
+	}
+	config := j.config
+	return JobInfo{
+		ID:             j.ID,
+		Name:           j.Name,
+		MemoryPercent:  config.MemoryPercent,
+		CPUPercent:     config.CPUPercent,
+		DiskPercent:    config.DiskPercent,
+		Interval:       config.Interval.String(),
+		FilePrefix:     config.FilePrefix,
+		AllocatedBytes: j.Monitor.AllocatedBytes(),
+		TempFileBytes:  j.Monitor.TempFileBytes(),
+		Status:         status,
+		Error:          errMsg,
+		CreatedAt:      j.CreatedAt,
+	}
+}
+
+// Server 通过HTTP管理多个独立的占用任务
+type Server struct {
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	nextID int
+}
+
+// NewServer 创建新的任务管理服务
+func NewServer() *Server {
+	return &Server{
+		jobs: make(map[string]*Job),
+	}
+}
+
+// Handler 返回任务管理的HTTP处理器
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", s.handleJobs)
+	mux.HandleFunc("/jobs/", s.handleJob)
+	return mux
+}
+
+// CreateJob 根据请求创建并启动任务
+func (s *Server) CreateJob(req JobRequest) (*Job, error) {
+	if err := validatePercent("内存", req.MemoryPercent); err != nil {
+		return nil, err
+	}
+	if err := validatePercent("CPU", req.CPUPercent); err != nil {
+		return nil, err
+	}
+	if err := validatePercent("磁盘", req.DiskPercent); err != nil {
+		return nil, err
+	}
+
+	interval := 5 * time.Second
+	if req.Interval != "" {
+		d, err := time.ParseDuration(req.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("监控间隔格式错误: %v", err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("监控间隔必须大于0")
+		}
+		interval = d
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("%d", s.nextID)
+	s.mu.Unlock()
+
+	config := ResourceConfig{
+		MemoryPercent:  req.MemoryPercent,
+		CPUPercent:     req.CPUPercent,
+		DiskPercent:    req.DiskPercent,
+		Interval:       interval,
+		WarmupDuration: DefaultWarmupDuration,
+		// 每个任务使用独立的文件前缀，避免互相清理对方的临时文件
+		FilePrefix: fmt.Sprintf("go_occupy_job%s_", id),
+	}
+	if err := ValidateConfig(config); err != nil {
+		return nil, err
+	}
+
+	job := &Job{
+		ID:        id,
+		Name:      req.Name,
+		Monitor:   NewResourceMonitor(config),
+		CreatedAt: time.Now(),
+		config:    config,
+	}
+
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.mu.Unlock()
+
+	logInfof("创建任务 %s (%s)", id, req.Name)
+	go job.Monitor.Start()
+	return job, nil
+}
+
+// GetJob 获取任务
+func (s *Server) GetJob(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	return job, ok
+}
+
+// ListJobs 列出所有任务，按创建时间排序
+func (s *Server) ListJobs() []JobInfo {
+	s.mu.Lock()
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	s.mu.Unlock()
+
+	// 在锁外生成任务信息，避免读取某个任务的状态时阻塞其他请求
+	infos := make([]JobInfo, 0, len(jobs))
+	for _, job := range jobs {
+		infos = append(infos, job.info())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].CreatedAt.Before(infos[j].CreatedAt)
+	})
+	return infos
+}
+
+// StopJob 优雅停止并移除任务
+func (s *Server) StopJob(id string) (JobInfo, bool) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if ok {
+		delete(s.jobs, id)
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		return JobInfo{}, false
+	}
+
+	logInfof("停止任务 %s", id)
+	job.Monitor.Stop()
+	job.stopped.Store(true)
+	return job.info(), true
+}
+
+// StopAll 停止所有任务
+func (s *Server) StopAll() {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			s.StopJob(id)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// handleJobs 处理 /jobs
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.ListJobs())
+	case http.MethodPost:
+		var req JobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("请求格式错误: %v", err))
+			return
+		}
+		job, err := s.CreateJob(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusCreated, job.info())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	}
+}
+
+// handleJob 处理 /jobs/{id} 和 /jobs/{id}/files
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if jobID, ok := strings.CutSuffix(id, "/files"); ok {
+		s.handleJobFiles(w, r, jobID)
+		return
+	}
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, "任务不存在")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		job, ok := s.GetJob(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "任务不存在")
+			return
+		}
+		writeJSON(w, http.StatusOK, job.info())
+	case http.MethodDelete:
+		info, ok := s.StopJob(id)
+		if !ok {
+			writeError(w, http.StatusNotFound, "任务不存在")
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	}
+}
+
+// handleJobFiles 处理 /jobs/{id}/files：GET 列出任务持有的临时文件，
+// DELETE 删除查询参数 path 指定的临时文件
+func (s *Server) handleJobFiles(w http.ResponseWriter, r *http.Request, id string) {
+	job, ok := s.GetJob(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "任务不存在")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, job.Monitor.ListTempFiles())
+	case http.MethodDelete:
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			writeError(w, http.StatusBadRequest, "缺少参数 path")
+			return
+		}
+		if err := job.Monitor.DropTempFile(path); err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrTempFileNotTracked) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, job.Monitor.ListTempFiles())
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "不支持的请求方法")
+	}
+}
+
+// validatePercent 验证百分比范围
+func validatePercent(name string, value float64) error {
+	if value < 0 || value > 100 {
+		return fmt.Errorf("%s百分比必须在 0-100 之间", name)
+	}
+	return nil
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError 输出错误响应
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
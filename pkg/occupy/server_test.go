@@ -0,0 +1,166 @@
+package occupy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestServer 创建使用临时目录存放临时文件的任务服务
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	s := NewServer()
+	t.Cleanup(s.StopAll)
+	return s
+}
+
+// doJSON 发送请求并检查状态码，响应体解码到 out（为 nil 时忽略）
+func doJSON(t *testing.T, method, url, body string, wantStatus int, out interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s 状态码 = %d, want %d", method, url, resp.StatusCode, wantStatus)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+	}
+}
+
+func TestServerCreateAndDeleteJob(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	var created JobInfo
+	doJSON(t, http.MethodPost, ts.URL+"/jobs", `{"name":"web","interval":"2s"}`, http.StatusCreated, &created)
+	if created.ID == "" || created.Name != "web" || created.Interval != "2s" || created.Status != "running" {
+		t.Fatalf("创建的任务 = %+v", created)
+	}
+
+	var jobs []JobInfo
+	doJSON(t, http.MethodGet, ts.URL+"/jobs", "", http.StatusOK, &jobs)
+	if len(jobs) != 1 || jobs[0].ID != created.ID {
+		t.Fatalf("任务列表 = %+v, want 仅 %s", jobs, created.ID)
+	}
+	doJSON(t, http.MethodGet, ts.URL+"/jobs/"+created.ID, "", http.StatusOK, nil)
+
+	var deleted JobInfo
+	doJSON(t, http.MethodDelete, ts.URL+"/jobs/"+created.ID, "", http.StatusOK, &deleted)
+	if deleted.ID != created.ID || deleted.Status != "stopped" {
+		t.Fatalf("删除的任务 = %+v", deleted)
+	}
+	doJSON(t, http.MethodGet, ts.URL+"/jobs/"+created.ID, "", http.StatusNotFound, nil)
+	doJSON(t, http.MethodDelete, ts.URL+"/jobs/"+created.ID, "", http.StatusNotFound, nil)
+	doJSON(t, http.MethodGet, ts.URL+"/jobs", "", http.StatusOK, &jobs)
+	if len(jobs) != 0 {
+		t.Fatalf("删除后任务列表 = %+v, want 空", jobs)
+	}
+}
+
+func TestServerRejectsInvalidJob(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	for _, body := range []string{`{"memory_percent":120}`, `{"interval":"-1s"}`, `{`} {
+		doJSON(t, http.MethodPost, ts.URL+"/jobs", body, http.StatusBadRequest, nil)
+	}
+	if n := len(s.ListJobs()); n != 0 {
+		t.Fatalf("无效请求创建了 %d 个任务", n)
+	}
+}
+
+func TestStopAllConcurrentWithInfo(t *testing.T) {
+	s := newTestServer(t)
+	for i := 0; i < 3; i++ {
+		if _, err := s.CreateJob(JobRequest{Name: "race", Interval: "1s"}); err != nil {
+			t.Fatalf("CreateJob: %v", err)
+		}
+	}
+	jobs := make([]*Job, 0, 3)
+	for _, info := range s.ListJobs() {
+		job, ok := s.GetJob(info.ID)
+		if !ok {
+			t.Fatalf("任务 %s 不存在", info.ID)
+		}
+		jobs = append(jobs, job)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					job.info()
+				}
+			}
+		}(job)
+	}
+	s.StopAll()
+	close(done)
+	wg.Wait()
+
+	for _, job := range jobs {
+		if status := job.info().Status; status != "stopped" {
+			t.Errorf("任务 %s 状态 = %q, want stopped", job.ID, status)
+		}
+	}
+	if n := len(s.ListJobs()); n != 0 {
+		t.Errorf("StopAll 后仍有 %d 个任务", n)
+	}
+}
+
+func TestJobInfoReflectsMonitorExit(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		stop    func(rm *ResourceMonitor)
+		status  string
+		wantErr string
+	}{
+		{"stopped", func(rm *ResourceMonitor) { rm.Stop() }, "stopped", ""},
+		{"failed", func(rm *ResourceMonitor) { rm.Abort(errors.New("磁盘写入失败")) }, "failed", "磁盘写入失败"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := ResourceConfig{MemoryPercent: 10, Interval: 100 * time.Millisecond}
+			job := &Job{
+				ID:      "1",
+				Monitor: NewResourceMonitorWithMetrics(config, newFakeMetrics(1<<30, 1<<40)),
+				config:  config,
+			}
+			done := job.Monitor.Done()
+			go job.Monitor.Start()
+			if info := job.info(); info.Status != "running" || info.Error != "" {
+				t.Fatalf("运行中的任务 = %q (%q), want running", info.Status, info.Error)
+			}
+
+			// 监控自行退出（未经 StopJob）后应反映实际状态
+			tc.stop(job.Monitor)
+			waitDone(t, done, 10*time.Second)
+			if info := job.info(); info.Status != tc.status || info.Error != tc.wantErr {
+				t.Errorf("退出后任务 = %q (%q), want %q (%q)", info.Status, info.Error, tc.status, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !unix
+
+package occupy
+
+import (
+	"os"
+)
+
+// StatusSignals 当前平台不支持状态输出信号
+func StatusSignals() []os.Signal {
+	return nil
+}
+
+// ReloadSignals 当前平台不支持重新加载配置的信号
+func ReloadSignals() []os.Signal {
+	return nil
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package occupy
+
+import (
+	"os"
+	"syscall"
+)
+
+// StatusSignals 返回触发状态输出的信号（SIGUSR1）
+func StatusSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
+
+// ReloadSignals 返回触发重新加载配置文件的信号（SIGHUP）
+func ReloadSignals() []os.Signal {
+	return []os.Signal{syscall.SIGHUP}
+}
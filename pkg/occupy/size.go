@@ -0,0 +1,48 @@
+package occupy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits 字节大小单位（按1024进制）
+var sizeUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"K":   1 << 10,
+	"KB":  1 << 10,
+	"KIB": 1 << 10,
+	"M":   1 << 20,
+	"MB":  1 << 20,
+	"MIB": 1 << 20,
+	"G":   1 << 30,
+	"GB":  1 << 30,
+	"GIB": 1 << 30,
+	"T":   1 << 40,
+	"TB":  1 << 40,
+	"TIB": 1 << 40,
+}
+
+// ParseSize 解析字节大小，支持 100MB、1.5G、4096 等格式（按1024进制）
+func ParseSize(value string) (uint64, error) {
+	trimmed := strings.TrimSpace(value)
+	index := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	number, unit := trimmed, ""
+	if index >= 0 {
+		number, unit = trimmed[:index], strings.TrimSpace(trimmed[index:])
+	}
+
+	multiplier, ok := sizeUnits[strings.ToUpper(unit)]
+	if !ok || number == "" {
+		return 0, fmt.Errorf("大小格式错误: %q", value)
+	}
+
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("大小格式错误: %q", value)
+	}
+	return uint64(n * float64(multiplier)), nil
+}
@@ -0,0 +1,90 @@
+package occupy
+
+import (
+	"fmt"
+	"time"
+)
+
+// Snapshot 某一时刻监控器占用的资源和最近一次测量结果，用于在测试中比较两个时刻之间的变化
+type Snapshot struct {
+	Time            time.Time
+	AllocatedBytes  uint64
+	AllocatedChunks int
+	CPUWorkers      int
+	// CPULoad CPU负载（核心数），小数部分由占空比工作线程承担
+	CPULoad       float64
+	TempFileBytes uint64
+	TempFiles     int
+	Measurement   Measurement
+}
+
+// Snapshot 获取当前占用的资源。各项分别在对应的锁内读取，
+// 同一类资源的数值相互一致，不同类资源之间不保证是同一瞬间的值
+func (rm *ResourceMonitor) Snapshot() Snapshot {
+	s := Snapshot{Time: time.Now()}
+
+	rm.memoryMutex.Lock()
+	s.AllocatedBytes = rm.getTotalAllocatedMemory()
+	s.AllocatedChunks = len(rm.AllocatedMemory)
+	rm.memoryMutex.Unlock()
+
+	rm.cpuLoadMutex.Lock()
+	s.CPUWorkers = rm.currentCPUWorkers
+	s.CPULoad = rm.currentCPULoad
+	rm.cpuLoadMutex.Unlock()
+
+	rm.diskMutex.Lock()
+	s.TempFileBytes = rm.tempFileBytes
+	s.TempFiles = len(rm.tempFileSizes)
+	rm.diskMutex.Unlock()
+
+	s.Measurement = rm.LastMeasurement()
+	return s
+}
+
+// SnapshotDiff 两个快照之间的变化，均为后者减去前者
+type SnapshotDiff struct {
+	Elapsed         time.Duration
+	AllocatedBytes  int64
+	AllocatedChunks int
+	CPUWorkers      int
+	CPULoad         float64
+	TempFileBytes   int64
+	TempFiles       int
+	MemoryPercent   float64
+	CPUPercent      float64
+	// DiskPercents 各磁盘目标使用率的变化，只包含两个快照中都有测量值的目标
+	DiskPercents []float64
+}
+
+// Diff 计算从 s 到 later 的变化
+func (s Snapshot) Diff(later Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		Elapsed:         later.Time.Sub(s.Time),
+		AllocatedBytes:  int64(later.AllocatedBytes) - int64(s.AllocatedBytes),
+		AllocatedChunks: later.AllocatedChunks - s.AllocatedChunks,
+		CPUWorkers:      later.CPUWorkers - s.CPUWorkers,
+		CPULoad:         later.CPULoad - s.CPULoad,
+		TempFileBytes:   int64(later.TempFileBytes) - int64(s.TempFileBytes),
+		TempFiles:       later.TempFiles - s.TempFiles,
+		MemoryPercent:   later.Measurement.MemoryPercent - s.Measurement.MemoryPercent,
+		CPUPercent:      later.Measurement.CPUPercent - s.Measurement.CPUPercent,
+	}
+
+	disks := len(s.Measurement.DiskPercents)
+	if len(later.Measurement.DiskPercents) < disks {
+		disks = len(later.Measurement.DiskPercents)
+	}
+	diff.DiskPercents = make([]float64, disks)
+	for i := range diff.DiskPercents {
+		diff.DiskPercents[i] = later.Measurement.DiskPercents[i] - s.Measurement.DiskPercents[i]
+	}
+	return diff
+}
+
+// String 格式化快照之间的变化
+func (d SnapshotDiff) String() string {
+	return fmt.Sprintf("%v 内: 内存 %+d bytes (%+d 块), CPU工作线程 %+d (负载 %+.2f 核), 临时文件 %+d bytes (%+d 个), 使用率 内存 %+.1f%% CPU %+.1f%%",
+		d.Elapsed.Round(time.Millisecond), d.AllocatedBytes, d.AllocatedChunks, d.CPUWorkers, d.CPULoad,
+		d.TempFileBytes, d.TempFiles, d.MemoryPercent, d.CPUPercent)
+}
@@ -0,0 +1,40 @@
+package occupy
+
+import "testing"
+
+func TestSnapshotDiffAroundAllocation(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{{Path: dir}},
+		Interval:    MinInterval,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+	defer rm.CleanupAllResources()
+	rm.AllocateMemory(4 * mb)
+
+	before := rm.Snapshot()
+	rm.AllocateMemory(12 * mb)
+	if err := rm.createTempFiles(dir, 3*mb); err != nil {
+		t.Fatalf("createTempFiles: %v", err)
+	}
+	after := rm.Snapshot()
+
+	diff := before.Diff(after)
+	if diff.AllocatedBytes != 12*mb {
+		t.Errorf("内存变化 = %d, want %d", diff.AllocatedBytes, 12*mb)
+	}
+	if diff.AllocatedChunks <= 0 {
+		t.Errorf("内存块变化 = %d, want 大于0", diff.AllocatedChunks)
+	}
+	if diff.TempFileBytes != 3*mb || diff.TempFiles != 1 {
+		t.Errorf("临时文件变化 = %d 字节 %d 个, want %d 字节 1 个", diff.TempFileBytes, diff.TempFiles, 3*mb)
+	}
+	if diff.CPUWorkers != 0 || diff.Elapsed <= 0 {
+		t.Errorf("CPU工作线程变化 = %d, 时间 = %v", diff.CPUWorkers, diff.Elapsed)
+	}
+
+	// 反向比较得到相反的变化
+	if back := after.Diff(before); back.AllocatedBytes != -12*mb || back.TempFileBytes != -3*mb {
+		t.Errorf("反向变化 = %+v", back)
+	}
+}
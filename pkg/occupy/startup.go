@@ -0,0 +1,99 @@
+package occupy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 分阶段启动的资源名称
+const (
+	StartupMemory = "mem"
+	StartupCPU    = "cpu"
+	StartupDisk   = "disk"
+)
+
+// StartupStage 分阶段启动中的一个阶段：上一阶段启用 Delay 之后开始调整 Resource
+type StartupStage struct {
+	Resource string
+	Delay    time.Duration
+}
+
+// ParseStartupOrder 解析启动顺序，如 "mem,cpu:30s,disk"；未单独指定延迟的阶段
+// 在上一阶段之后 delay 启用，第一个阶段默认立即启用
+func ParseStartupOrder(order string, delay time.Duration) ([]StartupStage, error) {
+	var stages []StartupStage
+	for i, item := range strings.Split(order, ",") {
+		name, delayText, hasDelay := strings.Cut(strings.TrimSpace(item), ":")
+		stage := StartupStage{Resource: name, Delay: delay}
+		if i == 0 {
+			stage.Delay = 0
+		}
+		if hasDelay {
+			d, err := time.ParseDuration(delayText)
+			if err != nil {
+				return nil, fmt.Errorf("无效的启动阶段延迟 %q: %v", item, err)
+			}
+			stage.Delay = d
+		}
+		stages = append(stages, stage)
+	}
+	if err := ValidateStartupOrder(stages); err != nil {
+		return nil, err
+	}
+	return stages, nil
+}
+
+// ValidateStartupOrder 验证启动阶段：资源只能是 mem、cpu、disk 且不能重复，延迟不能为负
+func ValidateStartupOrder(stages []StartupStage) error {
+	seen := make(map[string]bool)
+	for _, stage := range stages {
+		switch stage.Resource {
+		case StartupMemory, StartupCPU, StartupDisk:
+		default:
+			return fmt.Errorf("未知的启动阶段资源: %q (可选: mem, cpu, disk)", stage.Resource)
+		}
+		if seen[stage.Resource] {
+			return fmt.Errorf("启动阶段资源重复: %s", stage.Resource)
+		}
+		seen[stage.Resource] = true
+		if stage.Delay < 0 {
+			return fmt.Errorf("启动阶段 %s 的延迟不能为负数", stage.Resource)
+		}
+	}
+	return nil
+}
+
+// advanceStartup 按启动开始后经过的时间启用新的阶段
+func (rm *ResourceMonitor) advanceStartup() {
+	stages := rm.Config.StartupOrder
+	if rm.startupStages >= len(stages) {
+		return
+	}
+	if rm.startupBegan.IsZero() {
+		rm.startupBegan = time.Now()
+	}
+
+	var offset time.Duration
+	for i, stage := range stages {
+		offset += stage.Delay
+		if i < rm.startupStages {
+			continue
+		}
+		if time.Since(rm.startupBegan) < offset {
+			return
+		}
+		rm.startupStages = i + 1
+		logInfof("启动阶段 %d/%d: 开始调整 %s", i+1, len(stages), stage.Resource)
+	}
+}
+
+// stageEnabled 资源是否已到达其启动阶段；未出现在启动顺序中的资源始终启用
+func (rm *ResourceMonitor) stageEnabled(resource string) bool {
+	for i, stage := range rm.Config.StartupOrder {
+		if stage.Resource == resource {
+			return i < rm.startupStages
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package occupy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartupOrderDiskLast(t *testing.T) {
+	const mb = 1024 * 1024
+	const diskDelay = 1500 * time.Millisecond
+	stages, err := ParseStartupOrder("mem,cpu,disk:1500ms", 0)
+	if err != nil {
+		t.Fatalf("ParseStartupOrder: %v", err)
+	}
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 20,
+		DiskTargets:   []DiskTarget{{Path: t.TempDir(), Percent: 5}},
+		StartupOrder:  stages,
+		Interval:      MinInterval,
+	}, newFakeMetrics(100*mb, 100*mb))
+	defer rm.CleanupAllResources()
+
+	start := time.Now()
+	rm.MonitorAndAdjust()
+	if rm.AllocatedBytes() == 0 {
+		t.Fatal("第一阶段的内存未开始占用")
+	}
+	// 每次调整约 500ms，在延迟到达前至少再调整一次
+	for time.Since(start) < diskDelay-600*time.Millisecond {
+		rm.MonitorAndAdjust()
+	}
+	if got := rm.TempFileBytes(); got != 0 {
+		t.Fatalf("延迟 %v 前已写入 %d 字节临时文件", diskDelay, got)
+	}
+
+	time.Sleep(time.Until(start.Add(diskDelay)))
+	rm.MonitorAndAdjust()
+	if rm.TempFileBytes() == 0 {
+		t.Fatalf("经过 %v 后磁盘仍未开始占用", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+func TestParseStartupOrder(t *testing.T) {
+	stages, err := ParseStartupOrder("mem, cpu:30s ,disk", 10*time.Second)
+	if err != nil {
+		t.Fatalf("ParseStartupOrder: %v", err)
+	}
+	want := []StartupStage{{StartupMemory, 0}, {StartupCPU, 30 * time.Second}, {StartupDisk, 10 * time.Second}}
+	if len(stages) != len(want) {
+		t.Fatalf("stages = %v, want %v", stages, want)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Fatalf("stages = %v, want %v", stages, want)
+		}
+	}
+
+	for _, order := range []string{"mem,gpu", "mem,mem", "mem,disk:-1s", "cpu:abc"} {
+		if _, err := ParseStartupOrder(order, 0); err == nil {
+			t.Errorf("ParseStartupOrder(%q) = nil, want 错误", order)
+		}
+	}
+}
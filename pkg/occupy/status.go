@@ -0,0 +1,257 @@
+package occupy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// MemoryUsage 内存使用情况
+type MemoryUsage struct {
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Available   uint64  `json:"available"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// CPUUsage CPU使用情况
+type CPUUsage struct {
+	Cores       int     `json:"cores"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// DiskUsage 磁盘使用情况
+type DiskUsage struct {
+	Path        string  `json:"path"`
+	Total       uint64  `json:"total"`
+	Used        uint64  `json:"used"`
+	Free        uint64  `json:"free"`
+	UsedPercent float64 `json:"used_percent"`
+}
+
+// SystemUsage 系统资源使用情况
+type SystemUsage struct {
+	Memory MemoryUsage `json:"memory"`
+	CPU    CPUUsage    `json:"cpu"`
+	Disk   DiskUsage   `json:"disk"`
+}
+
+// CollectUsage 采集当前系统资源使用情况，CPU在 cpuInterval 时间内采样
+func CollectUsage(diskPath string, cpuInterval time.Duration) (*SystemUsage, error) {
+	return NewResourceMonitor(ResourceConfig{DiskPath: diskPath}).CollectUsage(cpuInterval)
+}
+
+// CollectUsage 通过与监控相同的指标来源采集当前资源使用情况：CPU为 cpuInterval 内的使用率，
+// 磁盘为 DiskPath（为空时为 DefaultDiskPath）的使用情况
+func (rm *ResourceMonitor) CollectUsage(cpuInterval time.Duration) (*SystemUsage, error) {
+	diskPath := rm.Config.DiskPath
+	if diskPath == "" {
+		diskPath = DefaultDiskPath
+	}
+	metrics := rm.metricsProvider()
+
+	memInfo, err := metrics.Memory()
+	if err != nil {
+		return nil, fmt.Errorf("获取内存信息失败: %v", err)
+	}
+
+	// CPU 返回自上次调用以来的使用率，先读取一次作为采样起点
+	if _, err := metrics.CPU(); err != nil {
+		return nil, fmt.Errorf("获取CPU信息失败: %v", err)
+	}
+	time.Sleep(cpuInterval)
+	cpuPercent, err := metrics.CPU()
+	if err != nil {
+		return nil, fmt.Errorf("获取CPU信息失败: %v", err)
+	}
+
+	cores, err := cpu.Counts(true)
+	if err != nil {
+		return nil, fmt.Errorf("获取CPU核心数失败: %v", err)
+	}
+
+	diskInfo, err := metrics.Disk(diskPath)
+	if err != nil {
+		return nil, fmt.Errorf("获取磁盘信息失败: %v", err)
+	}
+
+	return &SystemUsage{
+		Memory: MemoryUsage{
+			Total:       memInfo.Total,
+			Used:        memInfo.Used,
+			Available:   memInfo.Available,
+			UsedPercent: memInfo.UsedPercent,
+		},
+		CPU: CPUUsage{
+			Cores:       cores,
+			UsedPercent: cpuPercent,
+		},
+		Disk: DiskUsage{
+			Path:        diskInfo.Path,
+			Total:       diskInfo.Total,
+			Used:        diskInfo.Used,
+			Free:        diskInfo.Free,
+			UsedPercent: diskInfo.UsedPercent,
+		},
+	}, nil
+}
+
+// WriteText 以文本格式输出资源使用情况
+func (u *SystemUsage) WriteText(w io.Writer) {
+	fmt.Fprintln(w, "内存:")
+	fmt.Fprintf(w, "  使用率: %.1f%%\n", u.Memory.UsedPercent)
+	fmt.Fprintf(w, "  总量:   %s\n", FormatBytes(u.Memory.Total))
+	fmt.Fprintf(w, "  已用:   %s\n", FormatBytes(u.Memory.Used))
+	fmt.Fprintf(w, "  可用:   %s\n", FormatBytes(u.Memory.Available))
+	fmt.Fprintln(w, "CPU:")
+	fmt.Fprintf(w, "  使用率: %.1f%%\n", u.CPU.UsedPercent)
+	fmt.Fprintf(w, "  核心数: %d\n", u.CPU.Cores)
+	fmt.Fprintln(w, "磁盘:")
+	fmt.Fprintf(w, "  路径:   %s\n", u.Disk.Path)
+	fmt.Fprintf(w, "  使用率: %.1f%%\n", u.Disk.UsedPercent)
+	fmt.Fprintf(w, "  总量:   %s\n", FormatBytes(u.Disk.Total))
+	fmt.Fprintf(w, "  已用:   %s\n", FormatBytes(u.Disk.Used))
+	fmt.Fprintf(w, "  可用:   %s\n", FormatBytes(u.Disk.Free))
+}
+
+// WriteJSON 以JSON格式输出资源使用情况
+func (u *SystemUsage) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(u)
+}
+
+// FormatBytes 将字节数格式化为易读的字符串
+func FormatBytes(bytes uint64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := uint64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// Measurement 监控器最近一次测量的资源使用率
+type Measurement struct {
+	Time          time.Time
+	MemoryPercent float64
+	CPUPercent    float64
+	// DiskPercents 各磁盘目标的使用率，顺序与磁盘目标一致
+	DiskPercents []float64
+}
+
+// recordMeasurement 记录最近一次测量结果
+func (rm *ResourceMonitor) recordMeasurement(m Measurement) {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	rm.lastMeasurement = m
+	rm.recordLevels(m)
+}
+
+// LastMeasurement 获取最近一次测量结果，尚未测量时 Time 为零值
+func (rm *ResourceMonitor) LastMeasurement() Measurement {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	return rm.lastMeasurement
+}
+
+// recordTargets 记录最近一次调整使用的目标
+func (rm *ResourceMonitor) recordTargets(t Targets) {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	rm.lastTargets = &t
+}
+
+// TargetsMet 根据最近一次测量判断内存、CPU、磁盘是否都在目标的容忍范围内，
+// 有多个磁盘目标时需全部满足；尚未完成测量和调整时均返回 false
+func (rm *ResourceMonitor) TargetsMet() (memory, cpu, disk bool) {
+	rm.measurementMutex.Lock()
+	m := rm.lastMeasurement
+	t := rm.lastTargets
+	rm.measurementMutex.Unlock()
+
+	if t == nil || m.Time.IsZero() || len(m.DiskPercents) != len(t.DiskPercents) {
+		return false, false, false
+	}
+
+	memory = math.Abs(m.MemoryPercent-t.MemoryPercent) <= rm.memoryTolerance()
+	cpu = math.Abs(m.CPUPercent-t.CPUPercent) <= rm.cpuTolerance()
+	// 只测量磁盘时不判断磁盘目标
+	disk = true
+	if rm.Config.DiskMeasureOnly {
+		return memory, cpu, disk
+	}
+	for i, percent := range m.DiskPercents {
+		if math.Abs(percent-t.DiskPercents[i]) > rm.diskTolerance() {
+			disk = false
+		}
+	}
+	return memory, cpu, disk
+}
+
+// logUsage 输出最近一次测量的使用情况
+func (rm *ResourceMonitor) logUsage() {
+	m := rm.LastMeasurement()
+	if m.Time.IsZero() {
+		return
+	}
+
+	diskPercents := make([]string, len(m.DiskPercents))
+	targets := rm.diskTargets()
+	for i, percent := range m.DiskPercents {
+		diskPercents[i] = fmt.Sprintf("%.1f%%", percent)
+		if len(targets) > 1 && i < len(targets) {
+			diskPercents[i] = targets[i].Path + " " + diskPercents[i]
+		}
+	}
+	logInfof("当前使用情况: 内存 %.1f%%, CPU %.1f%%, 磁盘 %s",
+		m.MemoryPercent, m.CPUPercent, strings.Join(diskPercents, ", "))
+	rm.logSelfUsage(logInfof)
+}
+
+// LogStatus 输出当前占用状态快照
+func (rm *ResourceMonitor) LogStatus() {
+	allocatedBytes := rm.AllocatedBytes()
+	allocatedChunks := rm.AllocatedChunks()
+
+	rm.cpuLoadMutex.Lock()
+	cpuWorkers := rm.currentCPUWorkers
+	rm.cpuLoadMutex.Unlock()
+
+	rm.diskMutex.Lock()
+	tempFileCount := 0
+	for _, files := range rm.tempFiles {
+		tempFileCount += len(files)
+	}
+	tempFileBytes := rm.tempFileBytes
+	rm.diskMutex.Unlock()
+
+	logInfof("状态快照: 已分配内存 %s (%d 块), CPU工作线程 %d, 临时文件 %d 个 (%s)",
+		FormatBytes(allocatedBytes), allocatedChunks, cpuWorkers, tempFileCount, FormatBytes(tempFileBytes))
+	rm.logAllocationManifest()
+
+	m := rm.LastMeasurement()
+	if m.Time.IsZero() {
+		logInfof("状态快照: 尚未完成测量")
+		return
+	}
+
+	diskPercents := make([]string, len(m.DiskPercents))
+	for i, percent := range m.DiskPercents {
+		diskPercents[i] = fmt.Sprintf("%.1f%%", percent)
+	}
+	logInfof("状态快照: 最近测量 (%s) 内存 %.1f%%, CPU %.1f%%, 磁盘 %s",
+		m.Time.Format("15:04:05"), m.MemoryPercent, m.CPUPercent, strings.Join(diskPercents, ", "))
+}
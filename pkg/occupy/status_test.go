@@ -0,0 +1,183 @@
+package occupy
+
+import (
+	"bytes"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// captureLog 将日志输出重定向到返回的缓冲区并设置日志级别，测试结束时恢复
+func captureLog(t *testing.T, level LogLevel) *bytes.Buffer {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	origOutput, origFlags, origLevel := log.Writer(), log.Flags(), GetLogLevel()
+	log.SetOutput(buf)
+	log.SetFlags(0)
+	SetLogLevel(level)
+	t.Cleanup(func() {
+		log.SetOutput(origOutput)
+		log.SetFlags(origFlags)
+		SetLogLevel(origLevel)
+	})
+	return buf
+}
+
+func TestLogStatusFields(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitor(ResourceConfig{DiskTargets: []DiskTarget{{Path: dir}}})
+	t.Cleanup(rm.CleanupAllResources)
+
+	buf := captureLog(t, LogInfo)
+	rm.LogStatus()
+	if !strings.Contains(buf.String(), "尚未完成测量") {
+		t.Errorf("测量前的状态快照缺少提示:\n%s", buf)
+	}
+
+	rm.recordMeasurement(Measurement{
+		Time:          time.Now(),
+		MemoryPercent: 30,
+		CPUPercent:    12.5,
+		DiskPercents:  []float64{0},
+	})
+	rm.AllocateMemory(8 * mb)
+	rm.AllocateMemory(8 * mb)
+	rm.createTempFiles(dir, 3*mb)
+
+	buf.Reset()
+	rm.LogStatus()
+	out := buf.String()
+	for _, want := range []string{
+		"已分配内存 " + FormatBytes(16*mb) + " (2 块)",
+		"CPU工作线程 0",
+		"临时文件 1 个",
+		"内存 30.0%",
+		"CPU 12.5%",
+		"磁盘 0.0%",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("状态快照缺少 %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestCollectUsageReadsMetricsProvider(t *testing.T) {
+	const gb = 1024 * 1024 * 1024
+	metrics := newFakeMetrics(8*gb, 100*gb)
+	metrics.setMemoryUsed(2 * gb)
+	metrics.setCPU(37.5)
+	metrics.setDiskUsed(40 * gb)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{DiskPath: "/data"}, metrics)
+
+	usage, err := rm.CollectUsage(0)
+	if err != nil {
+		t.Fatalf("CollectUsage: %v", err)
+	}
+	if usage.Memory.Total != 8*gb || usage.Memory.UsedPercent != 25 {
+		t.Errorf("内存 = %+v, want 总量 8GB 使用率 25%%", usage.Memory)
+	}
+	if usage.CPU.UsedPercent != 37.5 {
+		t.Errorf("CPU使用率 = %.1f, want 37.5", usage.CPU.UsedPercent)
+	}
+	if usage.Disk.Path != "/data" || usage.Disk.UsedPercent != 40 {
+		t.Errorf("磁盘 = %+v, want /data 使用率 40%%", usage.Disk)
+	}
+}
+
+// feedbackMetrics 在基础用量上叠加监控器自身的占用，模拟真实系统对调整的响应
+type feedbackMetrics struct {
+	*fakeMetrics
+	rm    *ResourceMonitor
+	cores float64
+}
+
+func (m *feedbackMetrics) Memory() (*mem.VirtualMemoryStat, error) {
+	stat, _ := m.fakeMetrics.Memory()
+	stat.Used += m.rm.AllocatedBytes()
+	stat.Available = stat.Total - stat.Used
+	stat.UsedPercent = float64(stat.Used) / float64(stat.Total) * 100
+	return stat, nil
+}
+
+func (m *feedbackMetrics) CPU() (float64, error) {
+	base, _ := m.fakeMetrics.CPU()
+	m.rm.cpuLoadMutex.Lock()
+	load := m.rm.currentCPULoad
+	m.rm.cpuLoadMutex.Unlock()
+	return base + load/m.cores*100, nil
+}
+
+func (m *feedbackMetrics) Disk(path string) (*disk.UsageStat, error) {
+	stat, _ := m.fakeMetrics.Disk(path)
+	stat.Used += m.rm.TempFileBytes()
+	stat.Free = stat.Total - stat.Used
+	stat.UsedPercent = float64(stat.Used) / float64(stat.Total) * 100
+	return stat, nil
+}
+
+func TestTargetsMetAfterConverging(t *testing.T) {
+	const mb = 1024 * 1024
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	// CPU目标折算为半个核心，由一个占空比工作线程承担
+	cores := float64(runtime.NumCPU())
+	metrics := &feedbackMetrics{fakeMetrics: newFakeMetrics(100*mb, 100*mb), cores: cores}
+	metrics.setMemoryUsed(10 * mb)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		MemoryPercent: 30,
+		CPUPercent:    50 / cores,
+		DiskPercent:   5,
+		Interval:      100 * time.Millisecond,
+	}, metrics)
+	metrics.rm = rm
+
+	if memory, cpu, disk := rm.TargetsMet(); memory || cpu || disk {
+		t.Fatalf("测量前 TargetsMet = %v, %v, %v, want 全部 false", memory, cpu, disk)
+	}
+
+	done := rm.Done()
+	go rm.Start()
+	defer func() {
+		rm.Stop()
+		<-done
+	}()
+	waitFor(t, 10*time.Second, "TargetsMet 全部满足", func() bool {
+		memory, cpu, disk := rm.TargetsMet()
+		return memory && cpu && disk
+	})
+	if got := rm.AllocatedBytes(); got != 20*mb {
+		t.Errorf("收敛后 AllocatedBytes = %d, want %d", got, 20*mb)
+	}
+}
+
+func TestReportIntervalLogsLessOftenThanAdjusting(t *testing.T) {
+	t.Setenv("GO_OCCUPY_TEMP_DIR", t.TempDir())
+	buf := captureLog(t, LogDebug)
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		Interval:       100 * time.Millisecond,
+		ReportInterval: time.Second,
+	}, newFakeMetrics(1<<30, 1<<30))
+
+	// 设置 ReportInterval 时每次调整不输出使用情况，只在输出间隔到达时输出
+	rm.MonitorAndAdjust()
+	rm.MonitorAndAdjust()
+	if reports := strings.Count(buf.String(), "当前使用情况"); reports != 0 {
+		t.Fatalf("调整 2 次输出使用情况 %d 次, want 0", reports)
+	}
+	rm.logUsage()
+	if reports := strings.Count(buf.String(), "当前使用情况"); reports != 1 {
+		t.Fatalf("到达输出间隔后输出使用情况 %d 次, want 1", reports)
+	}
+
+	// 未设置 ReportInterval 时每次调整都以 debug 级别输出
+	rm.Config.ReportInterval = 0
+	rm.MonitorAndAdjust()
+	if reports := strings.Count(buf.String(), "当前使用情况"); reports != 2 {
+		t.Fatalf("未设置输出间隔时调整后输出使用情况 %d 次, want 2", reports)
+	}
+}
@@ -0,0 +1,63 @@
+package occupy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// tempSubdirPattern 获取目录中指定前缀临时子目录的匹配模式
+func tempSubdirPattern(dir, prefix string) string {
+	return filepath.Join(dir, prefix+"dir_[0-9]*")
+}
+
+// fileDir 获取下一个临时文件的写入目录：设置 DiskFilesPerDir 时在 tempDir 下
+// 创建子目录，每个子目录最多写入 DiskFilesPerDir 个文件（调用方需持有 diskMutex）
+func (rm *ResourceMonitor) fileDir(tempDir string) (string, error) {
+	perDir := rm.Config.DiskFilesPerDir
+	if perDir <= 0 {
+		return tempDir, nil
+	}
+
+	subdirs := rm.tempSubdirs[tempDir]
+	if n := len(subdirs); n > 0 && rm.dirFileCounts[subdirs[n-1]] < perDir {
+		return subdirs[n-1], nil
+	}
+
+	subdir := filepath.Join(tempDir, fmt.Sprintf("%sdir_%d", rm.filePrefix(), time.Now().UnixNano()))
+	if err := os.Mkdir(subdir, 0755); err != nil {
+		return "", fmt.Errorf("创建临时子目录失败: %v", err)
+	}
+	rm.tempSubdirs[tempDir] = append(subdirs, subdir)
+	logDebugf("创建临时子目录: %s", subdir)
+	return subdir, nil
+}
+
+// removeEmptySubdirs 删除 tempDir 下已没有临时文件的子目录（调用方需持有 diskMutex）
+func (rm *ResourceMonitor) removeEmptySubdirs(tempDir string) {
+	subdirs := rm.tempSubdirs[tempDir]
+	if len(subdirs) == 0 {
+		return
+	}
+
+	remaining := make([]string, 0)
+	for _, subdir := range subdirs {
+		if rm.dirFileCounts[subdir] > 0 {
+			remaining = append(remaining, subdir)
+			continue
+		}
+		if err := os.Remove(subdir); err != nil && !os.IsNotExist(err) {
+			logErrorf("删除临时子目录失败: %s, %v", subdir, err)
+			remaining = append(remaining, subdir)
+			continue
+		}
+		delete(rm.dirFileCounts, subdir)
+	}
+
+	if len(remaining) > 0 {
+		rm.tempSubdirs[tempDir] = remaining
+	} else {
+		delete(rm.tempSubdirs, tempDir)
+	}
+}
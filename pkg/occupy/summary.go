@@ -0,0 +1,173 @@
+package occupy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxLevelSamples 每项资源最多保留的测量样本数，超过后按蓄水池抽样保留
+const maxLevelSamples = 10000
+
+// levelReservoir 一项资源使用率的测量样本，最小值和最大值按全部测量统计
+type levelReservoir struct {
+	// seed 抽样使用的随机数种子，rng 在样本数超过 maxLevelSamples 时按其创建
+	seed    int64
+	rng     *rand.Rand
+	samples []float64
+	count   int
+	sum     float64
+	min     float64
+	max     float64
+}
+
+// add 记录一个测量值
+func (r *levelReservoir) add(value float64) {
+	if r.count == 0 || value < r.min {
+		r.min = value
+	}
+	if r.count == 0 || value > r.max {
+		r.max = value
+	}
+	r.count++
+	r.sum += value
+
+	if len(r.samples) < maxLevelSamples {
+		r.samples = append(r.samples, value)
+		return
+	}
+	if r.rng == nil {
+		r.rng = rand.New(rand.NewSource(r.seed))
+	}
+	if i := r.rng.Intn(r.count); i < maxLevelSamples {
+		r.samples[i] = value
+	}
+}
+
+// stats 计算分布统计
+func (r *levelReservoir) stats() LevelStats {
+	if r.count == 0 {
+		return LevelStats{}
+	}
+
+	sorted := append([]float64(nil), r.samples...)
+	sort.Float64s(sorted)
+	return LevelStats{
+		Samples: r.count,
+		Min:     r.min,
+		Mean:    r.sum / float64(r.count),
+		P50:     percentile(sorted, 50),
+		P90:     percentile(sorted, 90),
+		P99:     percentile(sorted, 99),
+		Max:     r.max,
+	}
+}
+
+// percentile 按最近秩法计算已排序样本的百分位数
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// LevelStats 一项资源使用率（百分比）的分布
+type LevelStats struct {
+	Samples int     `json:"samples"`
+	Min     float64 `json:"min"`
+	Mean    float64 `json:"mean"`
+	P50     float64 `json:"p50"`
+	P90     float64 `json:"p90"`
+	P99     float64 `json:"p99"`
+	Max     float64 `json:"max"`
+}
+
+// String 格式化分布统计
+func (s LevelStats) String() string {
+	if s.Samples == 0 {
+		return "无数据"
+	}
+	return fmt.Sprintf("min %.1f%% p50 %.1f%% p90 %.1f%% p99 %.1f%% max %.1f%% (%d 次)",
+		s.Min, s.P50, s.P90, s.P99, s.Max, s.Samples)
+}
+
+// Summary 运行期间各资源实际使用率的分布
+type Summary struct {
+	// Labels 配置的标签，见 ResourceConfig.Labels
+	Labels map[string]string `json:"labels,omitempty"`
+	Start  time.Time         `json:"start"`
+	End    time.Time         `json:"end"`
+	Memory LevelStats        `json:"memory"`
+	CPU    LevelStats        `json:"cpu"`
+	// Disk 各磁盘目标的使用率分布，顺序与磁盘目标一致
+	Disk []LevelStats `json:"disk"`
+}
+
+// recordLevels 将一次测量计入分布统计（调用方需持有 measurementMutex）
+func (rm *ResourceMonitor) recordLevels(m Measurement) {
+	if rm.levelsStart.IsZero() {
+		rm.levelsStart = m.Time
+		rm.memoryLevels.seed = rm.randomSeed()
+		rm.cpuLevels.seed = rm.randomSeed()
+	}
+	rm.levelsEnd = m.Time
+	rm.memoryLevels.add(m.MemoryPercent)
+	rm.cpuLevels.add(m.CPUPercent)
+	for len(rm.diskLevels) < len(m.DiskPercents) {
+		rm.diskLevels = append(rm.diskLevels, &levelReservoir{seed: rm.randomSeed()})
+	}
+	for i, percent := range m.DiskPercents {
+		rm.diskLevels[i].add(percent)
+	}
+}
+
+// Summary 获取运行期间每次测量的内存、CPU、磁盘使用率分布
+func (rm *ResourceMonitor) Summary() Summary {
+	rm.measurementMutex.Lock()
+	defer rm.measurementMutex.Unlock()
+
+	summary := Summary{
+		Labels: rm.Config.Labels,
+		Start:  rm.levelsStart,
+		End:    rm.levelsEnd,
+		Memory: rm.memoryLevels.stats(),
+		CPU:    rm.cpuLevels.stats(),
+		Disk:   make([]LevelStats, len(rm.diskLevels)),
+	}
+	for i, levels := range rm.diskLevels {
+		summary.Disk[i] = levels.stats()
+	}
+	return summary
+}
+
+// WriteJSON 以JSON格式输出运行汇总
+func (s Summary) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Log 输出运行汇总
+func (s Summary) Log() {
+	if s.Memory.Samples == 0 {
+		return
+	}
+
+	disk := make([]string, len(s.Disk))
+	for i, stats := range s.Disk {
+		disk[i] = stats.String()
+	}
+	logInfof("运行汇总 (%v):", s.End.Sub(s.Start).Round(time.Second))
+	logInfof("  内存: %s", s.Memory)
+	logInfof("  CPU:  %s", s.CPU)
+	logInfof("  磁盘: %s", strings.Join(disk, "; "))
+}
@@ -0,0 +1,72 @@
+package occupy
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSummaryPercentiles(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{Interval: MinInterval})
+	start := time.Unix(1700000000, 0)
+
+	// 内存读数为 1..100 的一个乱序排列，CPU 固定为 40，磁盘为 100 减去内存读数
+	rm.measurementMutex.Lock()
+	for i := 0; i < 100; i++ {
+		memory := float64((i*37)%100 + 1)
+		rm.recordLevels(Measurement{
+			Time:          start.Add(time.Duration(i) * time.Second),
+			MemoryPercent: memory,
+			CPUPercent:    40,
+			DiskPercents:  []float64{100 - memory},
+		})
+	}
+	rm.measurementMutex.Unlock()
+
+	summary := rm.Summary()
+	want := LevelStats{Samples: 100, Min: 1, Mean: 50.5, P50: 50, P90: 90, P99: 99, Max: 100}
+	if summary.Memory != want {
+		t.Errorf("内存分布 = %+v, want %+v", summary.Memory, want)
+	}
+	if cpu := summary.CPU; cpu.Min != 40 || cpu.P50 != 40 || cpu.P99 != 40 || cpu.Max != 40 {
+		t.Errorf("CPU分布 = %+v, want 全部为 40", cpu)
+	}
+	wantDisk := LevelStats{Samples: 100, Min: 0, Mean: 49.5, P50: 49, P90: 89, P99: 98, Max: 99}
+	if len(summary.Disk) != 1 || summary.Disk[0] != wantDisk {
+		t.Errorf("磁盘分布 = %+v, want [%+v]", summary.Disk, wantDisk)
+	}
+	if got := summary.End.Sub(summary.Start); got != 99*time.Second {
+		t.Errorf("统计时长 = %v, want 99s", got)
+	}
+
+	var buf bytes.Buffer
+	if err := summary.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var decoded Summary
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("解析汇总JSON失败: %v", err)
+	}
+	if decoded.Memory != want {
+		t.Errorf("JSON 中的内存分布 = %+v, want %+v", decoded.Memory, want)
+	}
+}
+
+func TestPercentileNearestRank(t *testing.T) {
+	sorted := []float64{10, 20, 30, 40}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10}, {25, 10}, {26, 20}, {50, 20}, {75, 30}, {99, 40}, {100, 40},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(%v, %v) = %v, want %v", sorted, tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("空样本 percentile = %v, want 0", got)
+	}
+}
@@ -0,0 +1,42 @@
+package occupy
+
+// SuspendCPU 停止所有CPU工作线程并暂停CPU调整，内存和临时文件保持不变，直到 ResumeCPU
+func (rm *ResourceMonitor) SuspendCPU() {
+	rm.cpuLoadMutex.Lock()
+	defer rm.cpuLoadMutex.Unlock()
+
+	if rm.cpuSuspended {
+		return
+	}
+	rm.cpuSuspended = true
+	rm.suspendedCPULoad = rm.targetCPULoad
+	if rm.ActiveCPULoad {
+		logInfof("暂停CPU负载 (当前工作线程: %d)", rm.currentCPUWorkers)
+		rm.stopCPULoadInternal()
+	}
+	rm.targetCPULoad = 0
+	rm.targetCPUWorkers = 0
+}
+
+// ResumeCPU 恢复CPU调整，并立即按暂停前的负载重新启动CPU工作线程
+func (rm *ResourceMonitor) ResumeCPU() {
+	rm.cpuLoadMutex.Lock()
+	if !rm.cpuSuspended {
+		rm.cpuLoadMutex.Unlock()
+		return
+	}
+	rm.cpuSuspended = false
+	load := rm.suspendedCPULoad
+	rm.cpuLoadMutex.Unlock()
+
+	logInfof("恢复CPU负载 (%.2f 核)", load)
+	rm.adjustCPULoad(load)
+}
+
+// CPUSuspended CPU负载是否已通过 SuspendCPU 暂停
+func (rm *ResourceMonitor) CPUSuspended() bool {
+	rm.cpuLoadMutex.Lock()
+	defer rm.cpuLoadMutex.Unlock()
+
+	return rm.cpuSuspended
+}
@@ -0,0 +1,6 @@
+//go:build !unix
+
+package occupy
+
+// WatchSuspendSignals 当前平台没有作业控制信号
+func (rm *ResourceMonitor) WatchSuspendSignals() {}
@@ -0,0 +1,51 @@
+package occupy
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSuspendStopsCPUWorkersAndResumeRestarts(t *testing.T) {
+	const mb = 1024 * 1024
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		CPUPercent: 100,
+		CPUCount:   2,
+		Interval:   MinInterval,
+	}, newFakeMetrics(1024*mb, 1024*mb))
+	defer rm.CleanupAllResources()
+	goroutines := runtime.NumGoroutine()
+
+	rm.AllocateMemory(4 * mb)
+	rm.AdjustCPUUsage(0)
+	if workers := rm.Snapshot().CPUWorkers; workers != 2 {
+		t.Fatalf("暂停前工作线程 = %d, want 2", workers)
+	}
+
+	rm.SuspendCPU()
+	if !rm.CPUSuspended() {
+		t.Fatal("SuspendCPU 后 CPUSuspended = false")
+	}
+	if workers := rm.Snapshot().CPUWorkers; workers != 0 {
+		t.Fatalf("暂停后工作线程 = %d, want 0", workers)
+	}
+	waitFor(t, time.Second, "CPU工作协程退出", func() bool {
+		return runtime.NumGoroutine() <= goroutines
+	})
+	// 暂停期间的调整不应重新启动工作线程，内存保持不变
+	rm.AdjustCPUUsage(0)
+	if workers := rm.Snapshot().CPUWorkers; workers != 0 {
+		t.Fatalf("暂停期间调整后工作线程 = %d, want 0", workers)
+	}
+	if got := rm.AllocatedBytes(); got != 4*mb {
+		t.Fatalf("暂停后 AllocatedBytes = %d, want %d", got, 4*mb)
+	}
+
+	rm.ResumeCPU()
+	if rm.CPUSuspended() {
+		t.Fatal("ResumeCPU 后 CPUSuspended = true")
+	}
+	if snapshot := rm.Snapshot(); snapshot.CPUWorkers != 2 || snapshot.CPULoad != 2 {
+		t.Fatalf("恢复后工作线程 %d, 负载 %.2f 核, want 2, 2", snapshot.CPUWorkers, snapshot.CPULoad)
+	}
+}
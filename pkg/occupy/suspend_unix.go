@@ -0,0 +1,32 @@
+//go:build unix
+
+package occupy
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchSuspendSignals 处理作业控制信号：收到 SIGTSTP（如 Ctrl+Z）时暂停CPU负载后
+// 以 SIGSTOP 停止本进程，保持默认的挂起行为；收到 SIGCONT 时恢复CPU负载
+func (rm *ResourceMonitor) WatchSuspendSignals() {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTSTP, syscall.SIGCONT)
+
+	go func() {
+		for sig := range signals {
+			switch sig {
+			case syscall.SIGTSTP:
+				logInfof("收到 SIGTSTP，暂停CPU负载后挂起进程")
+				rm.SuspendCPU()
+				syscall.Kill(os.Getpid(), syscall.SIGSTOP)
+			case syscall.SIGCONT:
+				if rm.CPUSuspended() {
+					logInfof("收到 SIGCONT，恢复CPU负载")
+				}
+				rm.ResumeCPU()
+			}
+		}
+	}()
+}
@@ -0,0 +1,83 @@
+package occupy
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unsafe"
+)
+
+// AllocationMagic 启用 TagAllocations 时写在每个内存块开头的标记，便于堆分析和内存泄漏工具识别本工具的分配
+const AllocationMagic = "GOOCCUPY"
+
+// AllocationHeaderSize 内存块头的字节数：8 字节 AllocationMagic + 8 字节小端序块编号
+const AllocationHeaderSize = len(AllocationMagic) + 8
+
+// AllocationInfo 单个已分配内存块的信息
+type AllocationInfo struct {
+	// ID 块编号，启用 TagAllocations 时与块头中的编号一致，否则为块在列表中的位置
+	ID      uint64  `json:"id"`
+	Address uintptr `json:"address"`
+	Size    int     `json:"size"`
+}
+
+// tagChunk 在内存块开头写入块头并分配新的块编号（调用方需持有 memoryMutex）
+func (rm *ResourceMonitor) tagChunk(chunk []byte) {
+	if !rm.Config.TagAllocations || len(chunk) < AllocationHeaderSize {
+		return
+	}
+	rm.nextChunkID++
+	copy(chunk, AllocationMagic)
+	binary.LittleEndian.PutUint64(chunk[len(AllocationMagic):AllocationHeaderSize], rm.nextChunkID)
+}
+
+// ChunkID 读取内存块头中的块编号，块开头不是 AllocationMagic 时返回 false
+func ChunkID(chunk []byte) (uint64, bool) {
+	if len(chunk) < AllocationHeaderSize || !bytes.Equal(chunk[:len(AllocationMagic)], []byte(AllocationMagic)) {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint64(chunk[len(AllocationMagic):AllocationHeaderSize]), true
+}
+
+// chunkDataOffset 内存块中写入校验模式的起始偏移，启用 TagAllocations 时跳过块头
+func (rm *ResourceMonitor) chunkDataOffset(chunk []byte) int {
+	if !rm.Config.TagAllocations || len(chunk) < AllocationHeaderSize {
+		return 0
+	}
+	return AllocationHeaderSize
+}
+
+// AllocationManifest 获取当前已分配内存块的编号、地址和大小
+func (rm *ResourceMonitor) AllocationManifest() []AllocationInfo {
+	rm.memoryMutex.Lock()
+	defer rm.memoryMutex.Unlock()
+
+	manifest := make([]AllocationInfo, 0, len(rm.AllocatedMemory))
+	for i, chunk := range rm.AllocatedMemory {
+		if len(chunk) == 0 {
+			continue
+		}
+		id, ok := ChunkID(chunk)
+		if !ok {
+			id = uint64(i)
+		}
+		manifest = append(manifest, AllocationInfo{
+			ID:      id,
+			Address: uintptr(unsafe.Pointer(&chunk[0])),
+			Size:    len(chunk),
+		})
+	}
+	return manifest
+}
+
+// logAllocationManifest 启用 TagAllocations 时输出已分配内存块清单
+func (rm *ResourceMonitor) logAllocationManifest() {
+	if !rm.Config.TagAllocations {
+		return
+	}
+
+	manifest := rm.AllocationManifest()
+	logInfof("内存块清单: %d 块 (块头 %q)", len(manifest), AllocationMagic)
+	for _, info := range manifest {
+		logInfof("  #%d 0x%x-0x%x %s", info.ID, info.Address, info.Address+uintptr(info.Size), FormatBytes(uint64(info.Size)))
+	}
+}
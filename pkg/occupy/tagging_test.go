@@ -0,0 +1,58 @@
+package occupy
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestTaggedChunksBeginWithMagicHeader(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		TagAllocations: true,
+		MemoryVerify:   true,
+		Interval:       MinInterval,
+	}, newFakeMetrics(1<<30, 1<<30))
+	defer rm.cleanupMemory()
+
+	rm.AllocateMemory(2*100*1024*1024 + 4096)
+	if len(rm.AllocatedMemory) != 3 {
+		t.Fatalf("内存块数量 = %d, want 3", len(rm.AllocatedMemory))
+	}
+	for i, chunk := range rm.AllocatedMemory {
+		if got := string(chunk[:len(AllocationMagic)]); got != AllocationMagic {
+			t.Errorf("第 %d 块开头 = %q, want %q", i, got, AllocationMagic)
+		}
+		if id, ok := ChunkID(chunk); !ok || id != uint64(i+1) {
+			t.Errorf("第 %d 块编号 = %d, %v, want %d, true", i, id, ok, i+1)
+		}
+	}
+	// 块头不影响内容校验
+	rm.verifyMemoryPass(make(chan struct{}))
+	if got := rm.MemoryVerifyErrors(); got != 0 {
+		t.Errorf("MemoryVerifyErrors = %d, want 0", got)
+	}
+
+	manifest := rm.AllocationManifest()
+	if len(manifest) != len(rm.AllocatedMemory) {
+		t.Fatalf("清单数量 = %d, want %d", len(manifest), len(rm.AllocatedMemory))
+	}
+	for i, info := range manifest {
+		chunk := rm.AllocatedMemory[i]
+		if info.ID != uint64(i+1) || info.Address != uintptr(unsafe.Pointer(&chunk[0])) || info.Size != len(chunk) {
+			t.Errorf("清单第 %d 项 = %+v, want 编号 %d、地址 %#x、大小 %d",
+				i, info, i+1, uintptr(unsafe.Pointer(&chunk[0])), len(chunk))
+		}
+	}
+}
+
+func TestUntaggedChunksHaveNoHeader(t *testing.T) {
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{Interval: MinInterval}, newFakeMetrics(1<<30, 1<<30))
+	defer rm.cleanupMemory()
+
+	rm.AllocateMemory(4096)
+	if len(rm.AllocatedMemory) != 1 {
+		t.Fatalf("内存块数量 = %d, want 1", len(rm.AllocatedMemory))
+	}
+	if id, ok := ChunkID(rm.AllocatedMemory[0]); ok {
+		t.Errorf("未启用标记时读取到块编号 %d", id)
+	}
+}
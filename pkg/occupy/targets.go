@@ -0,0 +1,68 @@
+package occupy
+
+import (
+	"math"
+	"time"
+)
+
+// Targets 一次调整使用的资源目标百分比
+type Targets struct {
+	MemoryPercent float64
+	CPUPercent    float64
+	// DiskPercents 各磁盘目标的百分比，顺序与磁盘目标一致
+	DiskPercents []float64
+}
+
+// baseTargets 根据配置计算资源目标
+func (rm *ResourceMonitor) baseTargets() Targets {
+	cpuPercent := rm.Config.CPUPercent
+	if rm.Config.CPUCoreLoad > 0 {
+		cpuPercent = math.Min(rm.Config.CPUCoreLoad/rm.cpuCores()*100.0, 100.0)
+	}
+
+	diskTargets := rm.diskTargets()
+	diskPercents := make([]float64, len(diskTargets))
+	for i, target := range diskTargets {
+		diskPercents[i] = target.Percent
+	}
+
+	return Targets{
+		MemoryPercent: rm.Config.MemoryPercent,
+		CPUPercent:    cpuPercent,
+		DiskPercents:  diskPercents,
+	}
+}
+
+// computeTargets 计算本次调整的动态目标，返回 false 表示本次不应进行调整
+func (rm *ResourceMonitor) computeTargets() (Targets, bool) {
+	rm.applyRetarget()
+	targets := rm.baseTargets()
+	now := time.Now()
+	targets.MemoryPercent = rm.memoryWavePercent(now)
+	targets = rm.applyBurst(targets, now)
+
+	if rm.Config.MirrorPID > 0 {
+		var ok bool
+		if targets, ok = rm.mirrorTargets(targets); !ok {
+			return targets, false
+		}
+	}
+
+	return targets, true
+}
+
+// memoryTargetPercent 获取当前的内存目标百分比
+func (rm *ResourceMonitor) memoryTargetPercent() float64 {
+	if rm.activeTargets != nil {
+		return rm.activeTargets.MemoryPercent
+	}
+	return rm.Config.MemoryPercent
+}
+
+// cpuTargetPercent 获取当前的CPU目标使用率，设置 CPUCoreLoad 时按核心数换算
+func (rm *ResourceMonitor) cpuTargetPercent() float64 {
+	if rm.activeTargets != nil {
+		return rm.activeTargets.CPUPercent
+	}
+	return rm.baseTargets().CPUPercent
+}
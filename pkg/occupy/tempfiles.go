@@ -0,0 +1,72 @@
+package occupy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ErrTempFileNotTracked 要删除的文件不是本监控器当前持有的临时文件
+var ErrTempFileNotTracked = errors.New("不是当前持有的临时文件")
+
+// FileInfo 当前持有的临时文件
+type FileInfo struct {
+	// Dir 文件所属磁盘目标的写入目录
+	Dir  string `json:"dir"`
+	Path string `json:"path"`
+	Size uint64 `json:"size"`
+}
+
+// ListTempFiles 列出当前持有的临时文件，按写入目录排序，同一目录内按创建顺序排列
+func (rm *ResourceMonitor) ListTempFiles() []FileInfo {
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
+
+	dirs := make([]string, 0, len(rm.tempFiles))
+	for dir := range rm.tempFiles {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	files := make([]FileInfo, 0, len(rm.tempFileSizes))
+	for _, dir := range dirs {
+		for _, path := range rm.tempFiles[dir] {
+			files = append(files, FileInfo{Dir: dir, Path: path, Size: rm.tempFileSizes[path]})
+		}
+	}
+	return files
+}
+
+// DropTempFile 删除一个当前持有的临时文件，用于在不停止运行的情况下临时腾出少量空间；
+// path 不是当前持有的临时文件时返回 ErrTempFileNotTracked。
+// 磁盘使用率因此低于目标时，下一次调整会重新写入，需要保持空闲时应同时降低目标或暂停
+func (rm *ResourceMonitor) DropTempFile(path string) error {
+	path = filepath.Clean(path)
+
+	rm.diskMutex.Lock()
+	defer rm.diskMutex.Unlock()
+
+	for dir, files := range rm.tempFiles {
+		for i, file := range files {
+			if file != path {
+				continue
+			}
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("删除临时文件失败: %w", err)
+			}
+			size := rm.tempFileSizes[file]
+			rm.forgetTempFile(file)
+			if remaining := append(files[:i:i], files[i+1:]...); len(remaining) > 0 {
+				rm.tempFiles[dir] = remaining
+			} else {
+				delete(rm.tempFiles, dir)
+			}
+			rm.removeEmptySubdirs(dir)
+			logInfof("按请求删除临时文件: %s (%s)", file, FormatBytes(size))
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrTempFileNotTracked, path)
+}
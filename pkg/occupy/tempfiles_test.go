@@ -0,0 +1,93 @@
+package occupy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestListAndDropTempFiles(t *testing.T) {
+	const mb = 1024 * 1024
+	dir := t.TempDir()
+	rm := NewResourceMonitorWithMetrics(ResourceConfig{
+		DiskTargets: []DiskTarget{{Path: dir, Percent: 50}},
+		Interval:    MinInterval,
+	}, newFakeMetrics(1<<30, 100*mb))
+	defer rm.CleanupAllResources()
+	for i := 0; i < 3; i++ {
+		if err := rm.createTempFiles(dir, mb); err != nil {
+			t.Fatalf("createTempFiles: %v", err)
+		}
+	}
+
+	files := rm.ListTempFiles()
+	if len(files) != 3 {
+		t.Fatalf("临时文件数量 = %d, want 3", len(files))
+	}
+	for _, file := range files {
+		if file.Dir != dir || file.Size != mb {
+			t.Errorf("临时文件 = %+v, want 目录 %s、大小 %d", file, dir, mb)
+		}
+		if _, err := os.Stat(file.Path); err != nil {
+			t.Errorf("列出的文件不存在: %v", err)
+		}
+	}
+
+	dropped := files[1].Path
+	if err := rm.DropTempFile(dropped); err != nil {
+		t.Fatalf("DropTempFile: %v", err)
+	}
+	if _, err := os.Stat(dropped); !os.IsNotExist(err) {
+		t.Errorf("删除后文件仍然存在: %v", err)
+	}
+	remaining := rm.ListTempFiles()
+	if len(remaining) != 2 || remaining[0].Path != files[0].Path || remaining[1].Path != files[2].Path {
+		t.Fatalf("删除后剩余 %+v, want %s 和 %s", remaining, files[0].Path, files[2].Path)
+	}
+	for _, file := range remaining {
+		if _, err := os.Stat(file.Path); err != nil {
+			t.Errorf("其他文件被删除: %v", err)
+		}
+	}
+	if got := rm.TempFileBytes(); got != 2*mb {
+		t.Errorf("TempFileBytes = %d, want %d", got, 2*mb)
+	}
+
+	if err := rm.DropTempFile(dropped); !errors.Is(err, ErrTempFileNotTracked) {
+		t.Errorf("重复删除 = %v, want ErrTempFileNotTracked", err)
+	}
+	other := filepath.Join(dir, "other.dat")
+	if err := os.WriteFile(other, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.DropTempFile(other); !errors.Is(err, ErrTempFileNotTracked) {
+		t.Errorf("删除未持有的文件 = %v, want ErrTempFileNotTracked", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("未持有的文件被删除: %v", err)
+	}
+}
+
+func TestServerJobFilesEndpoint(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+	job, err := s.CreateJob(JobRequest{Name: "files", Interval: "1s"})
+	if err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	filesURL := ts.URL + "/jobs/" + job.ID + "/files"
+
+	var files []FileInfo
+	doJSON(t, http.MethodGet, filesURL, "", http.StatusOK, &files)
+	if len(files) != 0 {
+		t.Errorf("临时文件 = %+v, want 空", files)
+	}
+	doJSON(t, http.MethodDelete, filesURL, "", http.StatusBadRequest, nil)
+	doJSON(t, http.MethodDelete, filesURL+"?path="+url.QueryEscape("/tmp/not-held.dat"), "", http.StatusNotFound, nil)
+	doJSON(t, http.MethodGet, ts.URL+"/jobs/missing/files", "", http.StatusNotFound, nil)
+}
@@ -0,0 +1,155 @@
+package occupy
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// MinInterval 监控间隔的最小值，过短的间隔会使监控协程忙于采样和调整
+const MinInterval = 100 * time.Millisecond
+
+// ValidateInterval 验证监控间隔
+func ValidateInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("监控间隔必须大于0 (当前: %v)，建议不小于 %v", interval, MinInterval)
+	}
+	if interval < MinInterval {
+		return fmt.Errorf("监控间隔 %v 过短，最小为 %v", interval, MinInterval)
+	}
+	return nil
+}
+
+// ValidateFilePrefix 验证临时文件名前缀：不能包含路径分隔符或通配符
+func ValidateFilePrefix(prefix string) error {
+	if strings.ContainsAny(prefix, `/\*?[]`) {
+		return fmt.Errorf("临时文件名前缀 %q 不能包含路径分隔符或通配符", prefix)
+	}
+	// 以数字结尾的前缀之后紧接数字时间戳，无法与更短前缀的文件区分（如 a_1 的文件 a_1123_0.dat
+	// 看起来也是前缀 a_ 的文件）
+	if prefix != "" && isDigits(prefix[len(prefix)-1:]) {
+		return fmt.Errorf("临时文件名前缀 %q 不能以数字结尾，建议以 _ 结尾", prefix)
+	}
+	return nil
+}
+
+// memoryBackedFilesystems 数据存放在内存中的文件系统类型
+var memoryBackedFilesystems = map[string]bool{
+	"tmpfs": true,
+	"ramfs": true,
+}
+
+// filesystemType 获取路径所在文件系统的类型（可在测试中替换）
+var filesystemType = detectFilesystemType
+
+// detectFilesystemType 通过挂载点的最长前缀匹配获取路径所在文件系统的类型
+func detectFilesystemType(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		absPath = resolved
+	}
+
+	partitions, err := disk.Partitions(true)
+	if err != nil {
+		return "", err
+	}
+
+	fstype := ""
+	longest := -1
+	for _, partition := range partitions {
+		mountpoint := partition.Mountpoint
+		if !pathWithin(absPath, mountpoint) {
+			continue
+		}
+		if len(mountpoint) > longest {
+			longest = len(mountpoint)
+			fstype = partition.Fstype
+		}
+	}
+	if longest < 0 {
+		return "", fmt.Errorf("未找到 %s 所在的挂载点", absPath)
+	}
+	return fstype, nil
+}
+
+// pathWithin 判断 path 是否位于目录 dir 之下（含 dir 本身）
+func pathWithin(path, dir string) bool {
+	if path == dir || dir == "/" {
+		return true
+	}
+	return strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+// ValidateConfig 启动前验证配置。
+// 磁盘占用目标位于 tmpfs/ramfs 时，写入的临时文件会占用内存，与内存目标相互干扰，
+// 此时输出警告；未设置 AllowTmpfsDisk 时返回错误
+func ValidateConfig(config ResourceConfig) error {
+	if err := ValidateInterval(config.Interval); err != nil {
+		return err
+	}
+	if err := ValidateFilePrefix(config.FilePrefix); err != nil {
+		return err
+	}
+	if err := ValidateDiskWriteRate(config.DiskWriteMBps); err != nil {
+		return err
+	}
+	if err := ValidateMemoryFill(config); err != nil {
+		return err
+	}
+	if err := ValidateStartupOrder(config.StartupOrder); err != nil {
+		return err
+	}
+	if err := ValidateCPUMix(config.CPUMix); err != nil {
+		return err
+	}
+	if err := ValidateMemoryFragment(config); err != nil {
+		return err
+	}
+	if err := ValidateCPUExclude(config.CPUExclude); err != nil {
+		return err
+	}
+	if err := ValidateCgroupPath(config.CgroupPath); err != nil {
+		return err
+	}
+	if err := ValidateMemoryCommit(config); err != nil {
+		return err
+	}
+
+	rm := &ResourceMonitor{Config: config}
+	for _, target := range rm.diskTargets() {
+		if target.Percent <= 0 || config.DiskMeasureOnly {
+			continue
+		}
+
+		dir := rm.writeDir(target)
+		if measured := rm.measurePath(target); measured != dir {
+			same, err := sameFilesystem(measured, dir)
+			if err != nil {
+				logWarnf("无法比较 %s 与临时文件目录 %s 所在的文件系统: %v", measured, dir, err)
+			} else if !same {
+				logWarnf("警告: 统计使用率的路径 %s 与临时文件目录 %s 不在同一文件系统，磁盘占用无法改变测量值", measured, dir)
+			}
+		}
+
+		fstype, err := filesystemType(dir)
+		if err != nil {
+			logWarnf("无法获取 %s 的文件系统类型: %v", dir, err)
+			continue
+		}
+		if !memoryBackedFilesystems[fstype] {
+			continue
+		}
+
+		logWarnf("警告: 临时文件目录 %s 位于 %s，磁盘占用将消耗内存，可能与内存目标相互干扰", dir, fstype)
+		if !config.AllowTmpfsDisk {
+			return fmt.Errorf("临时文件目录 %s 位于 %s，如确认需要请设置 --allow-tmpfs-disk", dir, fstype)
+		}
+	}
+	return nil
+}
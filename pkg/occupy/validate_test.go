@@ -0,0 +1,96 @@
+package occupy
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubFilesystemType 将 filesystemType 替换为返回固定类型的函数，测试结束时恢复
+func stubFilesystemType(t *testing.T, fstype string) {
+	t.Helper()
+	orig := filesystemType
+	filesystemType = func(string) (string, error) { return fstype, nil }
+	t.Cleanup(func() { filesystemType = orig })
+}
+
+func TestValidateConfigTmpfsDisk(t *testing.T) {
+	dir := t.TempDir()
+	config := ResourceConfig{
+		Interval:    MinInterval,
+		DiskTargets: []DiskTarget{{Path: dir, Percent: 50}},
+	}
+
+	for _, fstype := range []string{"tmpfs", "ramfs"} {
+		stubFilesystemType(t, fstype)
+		buf := captureLog(t, LogInfo)
+		err := ValidateConfig(config)
+		if err == nil || !strings.Contains(err.Error(), "--allow-tmpfs-disk") {
+			t.Errorf("%s: ValidateConfig = %v, want 拒绝并提示 --allow-tmpfs-disk", fstype, err)
+		}
+		if !strings.Contains(buf.String(), "磁盘占用将消耗内存") {
+			t.Errorf("%s: 未输出警告:\n%s", fstype, buf)
+		}
+
+		allowed := config
+		allowed.AllowTmpfsDisk = true
+		buf = captureLog(t, LogInfo)
+		if err := ValidateConfig(allowed); err != nil {
+			t.Errorf("%s: 设置 AllowTmpfsDisk 后 ValidateConfig = %v, want nil", fstype, err)
+		}
+		if !strings.Contains(buf.String(), "磁盘占用将消耗内存") {
+			t.Errorf("%s: 设置 AllowTmpfsDisk 后仍应输出警告:\n%s", fstype, buf)
+		}
+	}
+
+	stubFilesystemType(t, "ext4")
+	buf := captureLog(t, LogInfo)
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("ext4: ValidateConfig = %v, want nil", err)
+	}
+	if strings.Contains(buf.String(), "磁盘占用将消耗内存") {
+		t.Errorf("ext4: 不应输出警告:\n%s", buf)
+	}
+
+	stubFilesystemType(t, "tmpfs")
+	config.DiskTargets[0].Percent = 0
+	if err := ValidateConfig(config); err != nil {
+		t.Errorf("未占用磁盘时 ValidateConfig = %v, want nil", err)
+	}
+}
+
+func TestValidateInterval(t *testing.T) {
+	tests := []struct {
+		interval time.Duration
+		wantErr  string
+	}{
+		{0, "必须大于0"},
+		{-time.Second, "必须大于0"},
+		{time.Millisecond, "过短"},
+		{MinInterval, ""},
+		{time.Second, ""},
+	}
+	for _, tt := range tests {
+		err := ValidateInterval(tt.interval)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("ValidateInterval(%v) = %v, want nil", tt.interval, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ValidateInterval(%v) = %v, want 包含 %q", tt.interval, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStartWithZeroIntervalReturnsError(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{MemoryPercent: 10})
+	done := rm.Done()
+	// 间隔无效时 Start 应记录错误并返回，而不是在 time.NewTicker 中 panic
+	rm.Start()
+	waitDone(t, done, time.Second)
+	if err := rm.Err(); err == nil || !strings.Contains(err.Error(), "监控间隔必须大于0") {
+		t.Fatalf("Err = %v, want 监控间隔无效的错误", err)
+	}
+}
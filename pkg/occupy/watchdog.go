@@ -0,0 +1,62 @@
+package occupy
+
+import (
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// watchdogRecoveryFactor 暂停后，可用资源需恢复到下限的该倍数才继续占用，避免在下限附近反复触发
+const watchdogRecoveryFactor = 1.5
+
+// watchdog 安全检查：可用内存或磁盘剩余空间低于下限时立即释放所有占用资源并暂停，
+// 直到资源恢复。读取超时的资源（为 nil）不参与检查。返回 true 表示本次不应继续调整
+func (rm *ResourceMonitor) watchdog(memInfo *mem.VirtualMemoryStat, diskInfos []*disk.UsageStat) bool {
+	factor := 1.0
+	if rm.watchdogPaused {
+		factor = watchdogRecoveryFactor
+	}
+
+	breached := false
+	if floor := rm.Config.MemoryFloorBytes; floor > 0 && memInfo != nil && float64(memInfo.Available) < float64(floor)*factor {
+		if !rm.watchdogPaused {
+			logWarnf("紧急: 可用内存 %s 低于下限 %s", FormatBytes(memInfo.Available), FormatBytes(floor))
+		}
+		breached = true
+	}
+	if floor := rm.Config.DiskFloorBytes; floor > 0 {
+		for _, diskInfo := range diskInfos {
+			if diskInfo != nil && float64(diskInfo.Free) < float64(floor)*factor {
+				if !rm.watchdogPaused {
+					logWarnf("紧急: 磁盘 %s 剩余空间 %s 低于下限 %s", diskInfo.Path, FormatBytes(diskInfo.Free), FormatBytes(floor))
+				}
+				breached = true
+			}
+		}
+	}
+
+	if !breached {
+		if rm.watchdogPaused {
+			logInfof("资源已恢复，继续占用")
+			rm.watchdogPaused = false
+			rm.allocateBaseline()
+		}
+		return false
+	}
+
+	if !rm.watchdogPaused {
+		rm.watchdogPaused = true
+		rm.watchdogTrips++
+		logWarnf("紧急释放所有占用资源并暂停...")
+		rm.emergencyRelease()
+	}
+	return true
+}
+
+// emergencyRelease 立即释放所有占用的资源
+func (rm *ResourceMonitor) emergencyRelease() {
+	rm.stopCPULoad()
+	rm.cleanupMemory()
+	for _, target := range rm.diskTargets() {
+		rm.cleanupTempFiles(rm.writeDir(target))
+	}
+}
@@ -0,0 +1,73 @@
+package occupy
+
+import (
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+const watchdogTestMB = 1024 * 1024
+
+// newWatchdogTestMonitor 创建已占用16MB内存和1MB临时文件的监控器
+func newWatchdogTestMonitor(t *testing.T, config ResourceConfig) (*ResourceMonitor, string) {
+	t.Helper()
+	dir := t.TempDir()
+	config.DiskTargets = []DiskTarget{{Path: dir, Percent: 1}}
+	rm := NewResourceMonitor(config)
+	t.Cleanup(rm.CleanupAllResources)
+
+	rm.AllocateMemory(16 * watchdogTestMB)
+	rm.createTempFiles(dir, watchdogTestMB)
+	if rm.getTotalAllocatedMemory() == 0 || dirBytes(t, dir) == 0 {
+		t.Fatal("触发下限前未占用内存和磁盘")
+	}
+	return rm, dir
+}
+
+// assertEmergencyReleased 检查所有占用的资源已释放且监控处于暂停状态
+func assertEmergencyReleased(t *testing.T, rm *ResourceMonitor, dir string) {
+	t.Helper()
+	if got := rm.getTotalAllocatedMemory(); got != 0 {
+		t.Errorf("紧急释放后仍保留 %d 字节内存", got)
+	}
+	if got := dirBytes(t, dir); got != 0 {
+		t.Errorf("紧急释放后仍保留 %d 字节临时文件", got)
+	}
+	if !rm.watchdogPaused || rm.watchdogTrips != 1 {
+		t.Errorf("watchdogPaused = %v, watchdogTrips = %d, want true, 1", rm.watchdogPaused, rm.watchdogTrips)
+	}
+}
+
+func TestWatchdogMemoryFloor(t *testing.T) {
+	rm, dir := newWatchdogTestMonitor(t, ResourceConfig{MemoryFloorBytes: 100 * watchdogTestMB})
+	diskInfos := []*disk.UsageStat{{Path: dir, Free: 1 << 40}}
+
+	if !rm.watchdog(&mem.VirtualMemoryStat{Available: 50 * watchdogTestMB}, diskInfos) {
+		t.Fatal("可用内存低于下限时 watchdog = false, want true")
+	}
+	assertEmergencyReleased(t, rm, dir)
+
+	// 恢复到下限以上但未达到恢复倍数时保持暂停
+	if !rm.watchdog(&mem.VirtualMemoryStat{Available: 120 * watchdogTestMB}, diskInfos) || !rm.watchdogPaused {
+		t.Fatal("可用内存未恢复到下限的 1.5 倍时不应继续占用")
+	}
+
+	if rm.watchdog(&mem.VirtualMemoryStat{Available: 1 << 30}, diskInfos) || rm.watchdogPaused {
+		t.Fatal("可用内存恢复后应继续占用")
+	}
+}
+
+func TestWatchdogDiskFloor(t *testing.T) {
+	rm, dir := newWatchdogTestMonitor(t, ResourceConfig{DiskFloorBytes: 10 * watchdogTestMB})
+	memInfo := &mem.VirtualMemoryStat{Available: 1 << 30}
+
+	if !rm.watchdog(memInfo, []*disk.UsageStat{{Path: dir, Free: 5 * watchdogTestMB}}) {
+		t.Fatal("磁盘剩余空间低于下限时 watchdog = false, want true")
+	}
+	assertEmergencyReleased(t, rm, dir)
+
+	if rm.watchdog(memInfo, []*disk.UsageStat{{Path: dir, Free: 100 * watchdogTestMB}}) || rm.watchdogPaused {
+		t.Fatal("磁盘剩余空间恢复后应继续占用")
+	}
+}
@@ -0,0 +1,50 @@
+package occupy
+
+import (
+	"math"
+	"time"
+)
+
+// 内存目标波形
+const (
+	MemoryWaveFlat     = "flat"
+	MemoryWaveSawtooth = "sawtooth"
+	MemoryWaveSine     = "sine"
+	MemoryWaveSquare   = "square"
+)
+
+// waveValue 计算波形在相位 phase（[0,1)）处的取值，范围 [-1,1]
+func waveValue(pattern string, phase float64) float64 {
+	switch pattern {
+	case MemoryWaveSawtooth:
+		return 2*phase - 1
+	case MemoryWaveSine:
+		return math.Sin(2 * math.Pi * phase)
+	case MemoryWaveSquare:
+		if phase < 0.5 {
+			return 1
+		}
+		return -1
+	default:
+		return 0
+	}
+}
+
+// memoryWavePercent 计算 now 时刻的内存目标：以 MemoryPercent 为中心、
+// MemoryWaveAmplitude 为振幅、MemoryWavePeriod 为周期按波形变化，结果限制在 0-100
+func (rm *ResourceMonitor) memoryWavePercent(now time.Time) float64 {
+	base := rm.Config.MemoryPercent
+	period := rm.Config.MemoryWavePeriod
+	if rm.Config.MemoryWave == "" || rm.Config.MemoryWave == MemoryWaveFlat || period <= 0 {
+		return base
+	}
+
+	if rm.waveStart.IsZero() {
+		rm.waveStart = now
+	}
+	elapsed := now.Sub(rm.waveStart) % period
+	phase := float64(elapsed) / float64(period)
+
+	percent := base + rm.Config.MemoryWaveAmplitude*waveValue(rm.Config.MemoryWave, phase)
+	return math.Max(0, math.Min(percent, 100))
+}
@@ -0,0 +1,43 @@
+package occupy
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSawtoothWaveRisesThenResets(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent:       50,
+		MemoryWave:          MemoryWaveSawtooth,
+		MemoryWaveAmplitude: 20,
+		MemoryWavePeriod:    10 * time.Second,
+		Interval:            2 * time.Second,
+	})
+
+	// 每次调整间隔 2s，周期 10s：30% 起逐步上升，一个周期后回到 30%
+	start := time.Unix(1700000000, 0)
+	want := []float64{30, 38, 46, 54, 62, 30, 38}
+	for tick, w := range want {
+		got := rm.memoryWavePercent(start.Add(time.Duration(tick) * 2 * time.Second))
+		if math.Abs(got-w) > 1e-9 {
+			t.Fatalf("第 %d 次调整的目标 = %.2f%%, want %.2f%%", tick+1, got, w)
+		}
+	}
+}
+
+func TestWaveTargetClamped(t *testing.T) {
+	rm := NewResourceMonitor(ResourceConfig{
+		MemoryPercent:       90,
+		MemoryWave:          MemoryWaveSquare,
+		MemoryWaveAmplitude: 30,
+		MemoryWavePeriod:    time.Second,
+	})
+	start := time.Unix(1700000000, 0)
+	if got := rm.memoryWavePercent(start); got != 100 {
+		t.Fatalf("方波高位目标 = %.1f%%, want 100%%", got)
+	}
+	if got := rm.memoryWavePercent(start.Add(600 * time.Millisecond)); got != 60 {
+		t.Fatalf("方波低位目标 = %.1f%%, want 60%%", got)
+	}
+}
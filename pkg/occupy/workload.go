@@ -0,0 +1,189 @@
+package occupy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CPU负载的计算类型
+const (
+	// CPUWorkloadFloat 浮点运算（默认）
+	CPUWorkloadFloat = "float"
+	// CPUWorkloadInt 整数运算
+	CPUWorkloadInt = "int"
+	// CPUWorkloadMemory 以缓存行步长遍历大数组，制造缓存未命中的访存密集型负载
+	CPUWorkloadMemory = "memory"
+)
+
+// workloadBatch 每批计算的迭代次数，每批之间检查一次停止信号
+const workloadBatch = 1000
+
+// memoryWorkloadSize 访存密集型负载每个工作线程遍历的数组大小，需远大于CPU缓存
+const memoryWorkloadSize = 32 * 1024 * 1024
+
+// memoryWorkloadStride 访存密集型负载的访问步长，略大于一页，使每次访问落在不同的缓存行和页
+const memoryWorkloadStride = 4096 + 64
+
+// newCPUWorkload 创建工作线程使用的计算函数，每次调用执行一批计算
+func newCPUWorkload(kind string) func() {
+	switch kind {
+	case CPUWorkloadInt:
+		x := uint64(88172645463325252)
+		return func() {
+			for i := 0; i < workloadBatch; i++ {
+				// xorshift 整数运算
+				x ^= x << 13
+				x ^= x >> 7
+				x ^= x << 17
+			}
+		}
+	case CPUWorkloadMemory:
+		data := make([]byte, memoryWorkloadSize)
+		pos := 0
+		return func() {
+			for i := 0; i < workloadBatch; i++ {
+				data[pos]++
+				pos += memoryWorkloadStride
+				if pos >= len(data) {
+					pos -= len(data)
+				}
+			}
+		}
+	default:
+		i := 0
+		sum := 0.0
+		return func() {
+			for n := 0; n < workloadBatch; n++ {
+				sum += float64(i) * 3.14159
+				sum = sum * 1.001
+				i++
+			}
+			// 与原实现一致，每一百万次迭代重新开始
+			if i >= 1000000 {
+				i = 0
+				sum = 0
+			}
+		}
+	}
+}
+
+// CPUWorkloadWeight CPU混合负载中的一种计算类型及其权重
+type CPUWorkloadWeight struct {
+	Type   string
+	Weight int
+}
+
+// ParseCPUMix 解析CPU混合负载，如 "float:2,int:1,memory:1"；省略权重时为 1
+func ParseCPUMix(value string) ([]CPUWorkloadWeight, error) {
+	var mix []CPUWorkloadWeight
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kind, weightText, hasWeight := strings.Cut(item, ":")
+		entry := CPUWorkloadWeight{Type: kind, Weight: 1}
+		if hasWeight {
+			weight, err := strconv.Atoi(weightText)
+			if err != nil {
+				return nil, fmt.Errorf("无效的CPU混合负载权重 %q", item)
+			}
+			entry.Weight = weight
+		}
+		mix = append(mix, entry)
+	}
+	if err := ValidateCPUMix(mix); err != nil {
+		return nil, err
+	}
+	return mix, nil
+}
+
+// ValidateCPUMix 验证CPU混合负载：类型必须是 float、int 或 memory 且不重复，权重必须大于0
+func ValidateCPUMix(mix []CPUWorkloadWeight) error {
+	seen := make(map[string]bool)
+	for _, entry := range mix {
+		switch entry.Type {
+		case CPUWorkloadFloat, CPUWorkloadInt, CPUWorkloadMemory:
+		default:
+			return fmt.Errorf("未知的CPU负载计算类型: %q (可选: float, int, memory)", entry.Type)
+		}
+		if seen[entry.Type] {
+			return fmt.Errorf("CPU负载计算类型重复: %s", entry.Type)
+		}
+		seen[entry.Type] = true
+		if entry.Weight <= 0 {
+			return fmt.Errorf("CPU负载计算类型 %s 的权重必须大于0", entry.Type)
+		}
+	}
+	return nil
+}
+
+// cpuWorkloadTypes 按 CPUMix 的权重为 workers 个工作线程分配计算类型（最大余数法），
+// 同一类型的工作线程相邻；未设置 CPUMix 时都使用 CPUWorkloadType
+func (rm *ResourceMonitor) cpuWorkloadTypes(workers int) []string {
+	types := make([]string, 0, workers)
+	mix := rm.Config.CPUMix
+	if len(mix) == 0 {
+		for i := 0; i < workers; i++ {
+			types = append(types, rm.Config.CPUWorkloadType)
+		}
+		return types
+	}
+
+	total := 0
+	for _, entry := range mix {
+		total += entry.Weight
+	}
+	counts := make([]int, len(mix))
+	remainders := make([]int, len(mix))
+	assigned := 0
+	for i, entry := range mix {
+		counts[i] = workers * entry.Weight / total
+		remainders[i] = workers * entry.Weight % total
+		assigned += counts[i]
+	}
+	// 剩余的工作线程依次分给余数最大的类型，余数相同时按配置顺序
+	for ; assigned < workers; assigned++ {
+		best := 0
+		for i := range remainders {
+			if remainders[i] > remainders[best] {
+				best = i
+			}
+		}
+		counts[best]++
+		remainders[best] = -1
+	}
+
+	for i, entry := range mix {
+		for n := 0; n < counts[i]; n++ {
+			types = append(types, entry.Type)
+		}
+	}
+	return types
+}
+
+// logWorkloadTypes 设置 CPUMix 时输出各计算类型的工作线程数
+func (rm *ResourceMonitor) logWorkloadTypes(types []string) {
+	if len(rm.Config.CPUMix) > 0 {
+		logInfof("CPU混合负载工作线程: %s", formatWorkloadTypes(types))
+	}
+}
+
+// formatWorkloadTypes 按类型统计工作线程数，用于日志输出
+func formatWorkloadTypes(types []string) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, kind := range types {
+		if counts[kind] == 0 {
+			order = append(order, kind)
+		}
+		counts[kind]++
+	}
+	parts := make([]string, len(order))
+	for i, kind := range order {
+		parts[i] = fmt.Sprintf("%s %d", kind, counts[kind])
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,101 @@
+package occupy
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCPUWorkloadTypesStopCleanly(t *testing.T) {
+	for _, kind := range []string{CPUWorkloadFloat, CPUWorkloadInt, CPUWorkloadMemory} {
+		t.Run(kind, func(t *testing.T) {
+			buf := captureLog(t, LogInfo)
+			rm := NewResourceMonitorWithMetrics(ResourceConfig{
+				CPUCount:        2,
+				CPUWorkloadType: kind,
+				Interval:        MinInterval,
+			}, newFakeMetrics(1<<30, 1<<40))
+			goroutines := runtime.NumGoroutine()
+
+			rm.adjustCPUWorkers(2)
+			if workers := cpuWorkers(rm); workers != 2 {
+				t.Fatalf("启动 %d 个工作线程, want 2", workers)
+			}
+			for _, got := range rm.cpuWorkloadTypes(2) {
+				if got != kind {
+					t.Fatalf("工作线程计算类型 = %s, want %s", got, kind)
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+
+			start := time.Now()
+			rm.stopCPULoad()
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("停止用时 %v", elapsed)
+			}
+			if strings.Contains(buf.String(), "停止超时") {
+				t.Fatalf("工作线程未及时退出:\n%s", buf)
+			}
+			if workers := cpuWorkers(rm); workers != 0 {
+				t.Fatalf("停止后仍有 %d 个工作线程", workers)
+			}
+			waitFor(t, time.Second, "工作协程退出", func() bool {
+				return runtime.NumGoroutine() <= goroutines
+			})
+		})
+	}
+}
+
+func TestCPUWorkloadBatchReturnsQuickly(t *testing.T) {
+	for _, kind := range []string{CPUWorkloadFloat, CPUWorkloadInt, CPUWorkloadMemory} {
+		work := newCPUWorkload(kind)
+		start := time.Now()
+		for i := 0; i < 100; i++ {
+			work()
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: 100 批计算用时 %v，每批应很快返回以便检查停止信号", kind, elapsed)
+		}
+	}
+}
+
+func TestCPUMixLaunchesWorkersInProportion(t *testing.T) {
+	mix, err := ParseCPUMix("float:2,int:1,memory:1")
+	if err != nil {
+		t.Fatalf("ParseCPUMix: %v", err)
+	}
+	tests := []struct {
+		workers int
+		want    string
+	}{
+		{8, "float 4, int 2, memory 2"},
+		{5, "float 3, int 1, memory 1"},
+		{2, "float 1, int 1"},
+	}
+	for _, tt := range tests {
+		buf := captureLog(t, LogInfo)
+		rm := NewResourceMonitorWithMetrics(ResourceConfig{
+			CPUCount: 8,
+			CPUMix:   mix,
+			Interval: MinInterval,
+		}, newFakeMetrics(1<<30, 1<<40))
+		goroutines := runtime.NumGoroutine()
+
+		rm.adjustCPUWorkers(tt.workers)
+		if workers := cpuWorkers(rm); workers != tt.workers {
+			t.Fatalf("启动 %d 个工作线程, want %d", workers, tt.workers)
+		}
+		if got := formatWorkloadTypes(rm.cpuWorkloadTypes(tt.workers)); got != tt.want {
+			t.Errorf("%d 个工作线程的计算类型 = %s, want %s", tt.workers, got, tt.want)
+		}
+		if !strings.Contains(buf.String(), "CPU混合负载工作线程: "+tt.want) {
+			t.Errorf("启动日志中没有 %q:\n%s", tt.want, buf)
+		}
+
+		rm.stopCPULoad()
+		waitFor(t, time.Second, "所有类型的工作协程退出", func() bool {
+			return runtime.NumGoroutine() <= goroutines
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package occupypb 资源占用控制 gRPC API 的生成代码，定义见 proto/occupy.proto
+package occupypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative -I ../../proto ../../proto/occupy.proto
@@ -0,0 +1,384 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: occupy.proto
+
+package occupypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Occupy_Start_FullMethodName         = "/occupy.v1.Occupy/Start"
+	Occupy_Stop_FullMethodName          = "/occupy.v1.Occupy/Stop"
+	Occupy_Retarget_FullMethodName      = "/occupy.v1.Occupy/Retarget"
+	Occupy_Status_FullMethodName        = "/occupy.v1.Occupy/Status"
+	Occupy_Pause_FullMethodName         = "/occupy.v1.Occupy/Pause"
+	Occupy_Resume_FullMethodName        = "/occupy.v1.Occupy/Resume"
+	Occupy_ListTempFiles_FullMethodName = "/occupy.v1.Occupy/ListTempFiles"
+	Occupy_DropTempFile_FullMethodName  = "/occupy.v1.Occupy/DropTempFile"
+)
+
+// OccupyClient is the client API for Occupy service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OccupyClient interface {
+	// Start 启动监控，已启动时直接返回当前状态
+	Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Stop 停止监控并释放所有资源
+	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Retarget 修改资源目标，下一次调整时生效
+	Retarget(ctx context.Context, in *RetargetRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Status 获取当前状态
+	Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Pause 暂停调整，保持已占用的资源不变
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// Resume 恢复调整
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+	// ListTempFiles 列出当前持有的临时文件
+	ListTempFiles(ctx context.Context, in *ListTempFilesRequest, opts ...grpc.CallOption) (*ListTempFilesResponse, error)
+	// DropTempFile 删除一个当前持有的临时文件，返回删除后持有的临时文件
+	DropTempFile(ctx context.Context, in *DropTempFileRequest, opts ...grpc.CallOption) (*ListTempFilesResponse, error)
+}
+
+type occupyClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOccupyClient(cc grpc.ClientConnInterface) OccupyClient {
+	return &occupyClient{cc}
+}
+
+func (c *occupyClient) Start(ctx context.Context, in *StartRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Start_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Stop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) Retarget(ctx context.Context, in *RetargetRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Retarget_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) Status(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Pause_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, Occupy_Resume_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) ListTempFiles(ctx context.Context, in *ListTempFilesRequest, opts ...grpc.CallOption) (*ListTempFilesResponse, error) {
+	out := new(ListTempFilesResponse)
+	err := c.cc.Invoke(ctx, Occupy_ListTempFiles_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *occupyClient) DropTempFile(ctx context.Context, in *DropTempFileRequest, opts ...grpc.CallOption) (*ListTempFilesResponse, error) {
+	out := new(ListTempFilesResponse)
+	err := c.cc.Invoke(ctx, Occupy_DropTempFile_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OccupyServer is the server API for Occupy service.
+// All implementations must embed UnimplementedOccupyServer
+// for forward compatibility
+type OccupyServer interface {
+	// Start 启动监控，已启动时直接返回当前状态
+	Start(context.Context, *StartRequest) (*StatusResponse, error)
+	// Stop 停止监控并释放所有资源
+	Stop(context.Context, *StopRequest) (*StatusResponse, error)
+	// Retarget 修改资源目标，下一次调整时生效
+	Retarget(context.Context, *RetargetRequest) (*StatusResponse, error)
+	// Status 获取当前状态
+	Status(context.Context, *StatusRequest) (*StatusResponse, error)
+	// Pause 暂停调整，保持已占用的资源不变
+	Pause(context.Context, *PauseRequest) (*StatusResponse, error)
+	// Resume 恢复调整
+	Resume(context.Context, *ResumeRequest) (*StatusResponse, error)
+	// ListTempFiles 列出当前持有的临时文件
+	ListTempFiles(context.Context, *ListTempFilesRequest) (*ListTempFilesResponse, error)
+	// DropTempFile 删除一个当前持有的临时文件，返回删除后持有的临时文件
+	DropTempFile(context.Context, *DropTempFileRequest) (*ListTempFilesResponse, error)
+	mustEmbedUnimplementedOccupyServer()
+}
+
+// UnimplementedOccupyServer must be embedded to have forward compatible implementations.
+type UnimplementedOccupyServer struct {
+}
+
+func (UnimplementedOccupyServer) Start(context.Context, *StartRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedOccupyServer) Stop(context.Context, *StopRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedOccupyServer) Retarget(context.Context, *RetargetRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Retarget not implemented")
+}
+func (UnimplementedOccupyServer) Status(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedOccupyServer) Pause(context.Context, *PauseRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedOccupyServer) Resume(context.Context, *ResumeRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedOccupyServer) ListTempFiles(context.Context, *ListTempFilesRequest) (*ListTempFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTempFiles not implemented")
+}
+func (UnimplementedOccupyServer) DropTempFile(context.Context, *DropTempFileRequest) (*ListTempFilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropTempFile not implemented")
+}
+func (UnimplementedOccupyServer) mustEmbedUnimplementedOccupyServer() {}
+
+// UnsafeOccupyServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OccupyServer will
+// result in compilation errors.
+type UnsafeOccupyServer interface {
+	mustEmbedUnimplementedOccupyServer()
+}
+
+func RegisterOccupyServer(s grpc.ServiceRegistrar, srv OccupyServer) {
+	s.RegisterService(&Occupy_ServiceDesc, srv)
+}
+
+func _Occupy_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Start(ctx, req.(*StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Stop(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_Retarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Retarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Retarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Retarget(ctx, req.(*RetargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Status(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Pause_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_Resume_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_ListTempFiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTempFilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).ListTempFiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_ListTempFiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).ListTempFiles(ctx, req.(*ListTempFilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Occupy_DropTempFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DropTempFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OccupyServer).DropTempFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Occupy_DropTempFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OccupyServer).DropTempFile(ctx, req.(*DropTempFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Occupy_ServiceDesc is the grpc.ServiceDesc for Occupy service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Occupy_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "occupy.v1.Occupy",
+	HandlerType: (*OccupyServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Start",
+			Handler:    _Occupy_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _Occupy_Stop_Handler,
+		},
+		{
+			MethodName: "Retarget",
+			Handler:    _Occupy_Retarget_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _Occupy_Status_Handler,
+		},
+		{
+			MethodName: "Pause",
+			Handler:    _Occupy_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _Occupy_Resume_Handler,
+		},
+		{
+			MethodName: "ListTempFiles",
+			Handler:    _Occupy_ListTempFiles_Handler,
+		},
+		{
+			MethodName: "DropTempFile",
+			Handler:    _Occupy_DropTempFile_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "occupy.proto",
+}